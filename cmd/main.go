@@ -3,9 +3,13 @@ package main
 import (
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/Brownie44l1/blog/config"
 	"github.com/Brownie44l1/blog/internal/api"
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+	"github.com/Brownie44l1/blog/internal/oidc"
 	"github.com/Brownie44l1/blog/internal/repo"
 	"github.com/Brownie44l1/blog/internal/service"
 )
@@ -17,18 +21,121 @@ func main() {
 	defer cfg.DB.Close()
 	log.Println("✅ Connected to database!")
 
+	// Password hashing cost for new and upgraded hashes
+	argon2Params := auth.DefaultArgon2Params
+	argon2Params.Memory = uint32(cfg.Argon2MemoryKiB)
+	argon2Params.Iterations = uint32(cfg.Argon2Iterations)
+	argon2Params.Parallelism = uint8(cfg.Argon2Parallelism)
+	auth.SetArgon2Params(argon2Params)
+
+	// Password policy for new passwords
+	passwordPolicy := auth.PasswordPolicy{
+		MinLength:      cfg.PasswordMinLength,
+		MinEntropyBits: float64(cfg.PasswordMinEntropy),
+	}
+	if cfg.BreachedPasswordsFile != "" {
+		filter, n, err := auth.LoadBreachedPasswords(cfg.BreachedPasswordsFile)
+		if err != nil {
+			log.Fatalln("❌ Failed to load breached password list:", err)
+		}
+		passwordPolicy.Breached = filter
+		log.Printf("✅ Loaded %d breached passwords (%d KiB)", n, filter.SizeBytes()/1024)
+	}
+
 	// Initialize repositories
 	userRepo := repo.NewUserRepo(cfg.DB)
 	blogRepo := repo.NewBlogRepo(cfg.DB)
+	tokenRepo := repo.NewAccessTokenRepo(cfg.DB)
+	userTokenRepo := repo.NewUserTokenRepo(cfg.DB)
+	identityRepo := repo.NewIdentityRepo(cfg.DB)
+	sessionRepo := repo.NewSessionRepo(cfg.DB)
+	exportRepo := repo.NewExportRepo(cfg.DB)
+	inviteRepo := repo.NewInviteRepo(cfg.DB)
+	reactionRepo := repo.NewReactionRepo(cfg.DB)
+	bookmarkRepo := repo.NewBookmarkRepo(cfg.DB)
+	readingListRepo := repo.NewReadingListRepo(cfg.DB)
+	seriesRepo := repo.NewSeriesRepo(cfg.DB)
+	coAuthorRepo := repo.NewCoAuthorRepo(cfg.DB)
+	publicationRepo := repo.NewPublicationRepo(cfg.DB)
+	reviewRepo := repo.NewReviewRepo(cfg.DB)
+	followRepo := repo.NewFollowRepo(cfg.DB)
+	previewRepo := repo.NewPreviewRepo(cfg.DB)
 	log.Println("✅ Repositories initialized!")
 
+	// Initialize mailer
+	var mailer mail.Mailer
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		mailer = mail.NewLogMailer()
+	}
+
 	// Initialize services
-	userService := service.NewUserService(userRepo)
-	blogService := service.NewBlogService(blogRepo)
+	userService := service.NewUserService(userRepo, userTokenRepo, sessionRepo, inviteRepo, mailer, service.UserOptions{
+		BaseURL:              cfg.BaseURL,
+		RequireEmail:         cfg.RequireEmail,
+		RequireVerifiedEmail: cfg.RequireVerifiedEmail,
+		RegistrationMode:     cfg.RegistrationMode,
+		PasswordPolicy:       passwordPolicy,
+	})
+	blogService := service.NewBlogService(blogRepo, reactionRepo, seriesRepo, coAuthorRepo, publicationRepo, reviewRepo, followRepo, userRepo)
+	reactionService := service.NewReactionService(reactionRepo, blogService)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, blogRepo, followRepo, reactionRepo)
+	readingListService := service.NewReadingListService(readingListRepo, blogRepo, followRepo, reactionRepo)
+	seriesService := service.NewSeriesService(seriesRepo, blogRepo)
+	coAuthorService := service.NewCoAuthorService(coAuthorRepo, blogRepo, userRepo)
+	publicationService := service.NewPublicationService(publicationRepo, blogRepo, userRepo, reactionRepo, cfg.BaseURL)
+	reviewService := service.NewReviewService(reviewRepo, blogRepo, userRepo, coAuthorRepo, publicationRepo)
+	followService := service.NewFollowService(followRepo, userRepo)
+	previewService := service.NewPreviewService(previewRepo, blogRepo, coAuthorRepo, reactionRepo, cfg.BaseURL)
+	tokenService := service.NewAccessTokenService(tokenRepo)
+	sessionService := service.NewSessionService(sessionRepo)
+	inviteService := service.NewInviteService(inviteRepo, userRepo)
+	exportService := service.NewExportService(exportRepo, userService, blogRepo, seriesRepo, reviewRepo, readingListRepo, mailer, service.ExportOptions{
+		Dir:     cfg.ExportDir,
+		BaseURL: cfg.BaseURL,
+	})
+
+	var oidcProviders []service.OIDCProvider
+	for _, p := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+		}))
+	}
+	oidcService := service.NewOIDCService(oidcProviders, identityRepo, userRepo, cfg.RegistrationMode)
 	log.Println("✅ Services initialized!")
 
+	// Background maintenance: expired export archives, accounts whose
+	// deletion grace period has ended and posts past the trash retention
+	go func() {
+		for {
+			if err := exportService.PurgeExpired(); err != nil {
+				log.Printf("⚠️  Failed to purge expired exports: %v", err)
+			}
+			if err := userService.PurgeDeletedAccounts(); err != nil {
+				log.Printf("⚠️  Failed to purge deleted accounts: %v", err)
+			}
+			if err := blogService.PurgeTrash(cfg.TrashRetention); err != nil {
+				log.Printf("⚠️  Failed to purge trashed blogs: %v", err)
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+
 	// Setup routes with all handlers
-	router := api.SetupRoutes(userService, blogService, cfg.JWTSecret)
+	router := api.SetupRoutes(
+		userService, blogService, tokenService, oidcService, sessionService, exportService, inviteService, reactionService,
+		bookmarkService, readingListService, seriesService, coAuthorService, publicationService, reviewService,
+		followService, previewService,
+		cfg.JWTSecret,
+		api.CookieOptions{Secure: cfg.CookieSecure, SameSite: cfg.CookieSameSite},
+		cfg.CORSAllowedOrigins,
+	)
 	log.Println("✅ Routes configured!")
 
 	// Start server
@@ -0,0 +1,35 @@
+// Command mockoidc is a minimal OpenID Connect provider for local development
+// and testing of the "Sign in with" flow. It supports discovery, the
+// authorization code flow with PKCE (S256) and RS256-signed ID tokens.
+//
+//	go run ./cmd/mockoidc -addr :9999
+//
+// Then start the API with OIDC_PROVIDERS=mock and the OIDC_MOCK_* variables
+// from .env.example, and open http://localhost:8080/auth/oidc/mock/login.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/Brownie44l1/blog/internal/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9999", "listen address")
+	issuer := flag.String("issuer", "http://localhost:9999", "issuer URL advertised to clients")
+	clientID := flag.String("client-id", "blog", "accepted client ID")
+	clientSecret := flag.String("client-secret", "secret", "accepted client secret")
+	autoUser := flag.String("auto", "", "sign in as this user without showing the login form")
+	flag.Parse()
+
+	p, err := oidctest.NewProvider(*issuer, *clientID, *clientSecret)
+	if err != nil {
+		log.Fatalln("❌ Failed to generate signing key:", err)
+	}
+	p.AutoUser = *autoUser
+
+	log.Printf("🚀 Mock OIDC provider running on %s (issuer %s)", *addr, *issuer)
+	log.Fatal(http.ListenAndServe(*addr, p))
+}
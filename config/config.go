@@ -2,8 +2,12 @@ package config
 
 import (
 	"log"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -14,6 +18,163 @@ import (
 type Config struct {
 	DB        *sqlx.DB
 	JWTSecret string
+	BaseURL   string
+
+	// Outgoing mail; SMTPHost may only be empty for a localhost BaseURL,
+	// and mail is then written to the log
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// RequireEmail makes an email address mandatory at registration
+	RequireEmail bool
+	// RequireVerifiedEmail blocks posting until the user's email is verified
+	RequireVerifiedEmail bool
+	// RegistrationMode is open, invite, approval or closed
+	RegistrationMode string
+
+	OIDCProviders []OIDCProviderConfig
+
+	// Browser session cookies
+	CookieSecure   bool
+	CookieSameSite http.SameSite
+	// CORSAllowedOrigins lists origins allowed to send credentialed requests;
+	// empty means any origin, without cookies
+	CORSAllowedOrigins []string
+
+	// Password policy for new passwords; BreachedPasswordsFile is optional
+	PasswordMinLength     int
+	PasswordMinEntropy    int
+	BreachedPasswordsFile string
+
+	// ExportDir is where account data export archives are written
+	ExportDir string
+
+	// TrashRetention is how long deleted posts stay in the trash
+	TrashRetention time.Duration
+
+	// argon2id password hashing cost
+	Argon2MemoryKiB   int
+	Argon2Iterations  int
+	Argon2Parallelism int
+}
+
+// getEnvInt reads a positive integer environment variable, falling back to
+// def when it is unset or invalid.
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		log.Printf("⚠️  Invalid value %q for %s, using %d", v, key, def)
+		return def
+	}
+	return n
+}
+
+// mustEnvIntInRange reads an integer environment variable, defaulting to def
+// when unset, and stops the server when it is not within [min, max].
+func mustEnvIntInRange(key string, def, min, max int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		log.Fatalf("❌ Invalid value %q for %s, expected a number from %d to %d", v, key, min, max)
+	}
+	return n
+}
+
+// parseSameSite maps COOKIE_SAMESITE to a cookie attribute, defaulting to Lax.
+func parseSameSite(v string) http.SameSite {
+	switch strings.ToLower(v) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	case "", "lax":
+		return http.SameSiteLaxMode
+	default:
+		log.Printf("⚠️  Invalid value %q for COOKIE_SAMESITE, using lax", v)
+		return http.SameSiteLaxMode
+	}
+}
+
+// OIDCProviderConfig configures one OpenID Connect identity provider.
+type OIDCProviderConfig struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// loadOIDCProviders reads providers listed in OIDC_PROVIDERS (comma
+// separated). Each provider NAME is configured through OIDC_<NAME>_ISSUER,
+// OIDC_<NAME>_CLIENT_ID, OIDC_<NAME>_CLIENT_SECRET and optionally
+// OIDC_<NAME>_REDIRECT_URL and OIDC_<NAME>_SCOPES.
+func loadOIDCProviders(baseURL string) []OIDCProviderConfig {
+	var providers []OIDCProviderConfig
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		p := OIDCProviderConfig{
+			Name:         name,
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(os.Getenv(prefix + "SCOPES")),
+		}
+		if p.Issuer == "" || p.ClientID == "" {
+			log.Fatalf("❌ OIDC provider %q needs %sISSUER and %sCLIENT_ID", name, prefix, prefix)
+		}
+		if p.RedirectURL == "" {
+			p.RedirectURL = baseURL + "/auth/oidc/" + name + "/callback"
+		}
+
+		providers = append(providers, p)
+		log.Printf("✅ OIDC provider %q configured (issuer %s)", name, p.Issuer)
+	}
+	return providers
+}
+
+// isLocalURL reports whether rawURL points at the local machine.
+func isLocalURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return true
+	}
+	return false
+}
+
+// getEnvBool reads a boolean environment variable, falling back to def when
+// it is unset or cannot be parsed.
+func getEnvBool(key string, def bool) bool {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Printf("⚠️  Invalid value %q for %s, using %t", v, key, def)
+		return def
+	}
+	return b
 }
 
 func Load() *Config {
@@ -39,6 +200,52 @@ func Load() *Config {
 		log.Println("⚠️  WARNING: JWT_SECRET should be at least 32 characters for security")
 	}
 
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	smtpHost := os.Getenv("SMTP_HOST")
+	if smtpHost == "" {
+		// Without SMTP nobody can verify an email or reset a password, so
+		// only allow it for local development
+		if !isLocalURL(baseURL) {
+			log.Fatalln("❌ SMTP_HOST is not set. It is required unless BASE_URL points at localhost")
+		}
+		log.Println("⚠️  SMTP_HOST is not set, outgoing mail will be written to the log")
+	}
+
+	smtpPort := os.Getenv("SMTP_PORT")
+	if smtpPort == "" {
+		smtpPort = "587"
+	}
+
+	mailFrom := os.Getenv("MAIL_FROM")
+	if mailFrom == "" {
+		mailFrom = "no-reply@localhost"
+	}
+
+	// A bad hashing cost would only show once someone registers or signs in
+	argon2Memory := mustEnvIntInRange("ARGON2_MEMORY_KIB", 64*1024, 8*1024, 4*1024*1024)
+	argon2Iterations := mustEnvIntInRange("ARGON2_ITERATIONS", 3, 1, 100)
+	argon2Parallelism := mustEnvIntInRange("ARGON2_PARALLELISM", 2, 1, 255)
+
+	registrationMode := strings.ToLower(os.Getenv("REGISTRATION_MODE"))
+	switch registrationMode {
+	case "open", "invite", "approval", "closed":
+	case "":
+		registrationMode = "open"
+	default:
+		log.Printf("⚠️  Unknown REGISTRATION_MODE %q, registration will be closed", registrationMode)
+		registrationMode = "closed"
+	}
+
+	exportDir := os.Getenv("EXPORT_DIR")
+	if exportDir == "" {
+		exportDir = "exports"
+	}
+
 	db, err := sqlx.Connect("postgres", dbURL)
 	if err != nil {
 		log.Fatalln("❌ Failed to connect to DB:", err)
@@ -57,5 +264,33 @@ func Load() *Config {
 	return &Config{
 		DB:           db,
 		JWTSecret:    jwtSecret,
+		BaseURL:      baseURL,
+		SMTPHost:     smtpHost,
+		SMTPPort:     smtpPort,
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		MailFrom:     mailFrom,
+
+		RequireEmail:         getEnvBool("REQUIRE_EMAIL", false),
+		RequireVerifiedEmail: getEnvBool("REQUIRE_VERIFIED_EMAIL", false),
+		RegistrationMode:     registrationMode,
+
+		OIDCProviders: loadOIDCProviders(baseURL),
+
+		CookieSecure:       getEnvBool("COOKIE_SECURE", true),
+		CookieSameSite:     parseSameSite(os.Getenv("COOKIE_SAMESITE")),
+		CORSAllowedOrigins: strings.FieldsFunc(os.Getenv("CORS_ALLOWED_ORIGINS"), func(r rune) bool { return r == ',' || r == ' ' }),
+
+		PasswordMinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordMinEntropy:    getEnvInt("PASSWORD_MIN_ENTROPY_BITS", 35),
+		BreachedPasswordsFile: os.Getenv("BREACHED_PASSWORDS_FILE"),
+
+		ExportDir: exportDir,
+
+		TrashRetention: time.Duration(getEnvInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+
+		Argon2MemoryKiB:   argon2Memory,
+		Argon2Iterations:  argon2Iterations,
+		Argon2Parallelism: argon2Parallelism,
 	}
 }
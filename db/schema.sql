@@ -1,32 +1,356 @@
 -- Users table
+DROP TABLE IF EXISTS blog_tags CASCADE;
+DROP TABLE IF EXISTS preview_links CASCADE;
+DROP TABLE IF EXISTS follows CASCADE;
+DROP TABLE IF EXISTS review_events CASCADE;
+DROP TABLE IF EXISTS review_comments CASCADE;
+DROP TABLE IF EXISTS blog_reviewers CASCADE;
+DROP TABLE IF EXISTS publication_members CASCADE;
+DROP TABLE IF EXISTS publications CASCADE;
+DROP TABLE IF EXISTS blog_authors CASCADE;
+DROP TABLE IF EXISTS series_posts CASCADE;
+DROP TABLE IF EXISTS series CASCADE;
+DROP TABLE IF EXISTS reading_list_items CASCADE;
+DROP TABLE IF EXISTS reading_lists CASCADE;
+DROP TABLE IF EXISTS bookmarks CASCADE;
+DROP TABLE IF EXISTS blog_reactions CASCADE;
+DROP TABLE IF EXISTS invites CASCADE;
+DROP TABLE IF EXISTS data_exports CASCADE;
+DROP TABLE IF EXISTS username_history CASCADE;
+DROP TABLE IF EXISTS sessions CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS user_tokens CASCADE;
+DROP TABLE IF EXISTS personal_access_tokens CASCADE;
 DROP TABLE IF EXISTS blogs CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
 
 CREATE TABLE users (
     id BIGSERIAL PRIMARY KEY,
-    username VARCHAR(20) UNIQUE NOT NULL,
-    password VARCHAR(60) NOT NULL
+    -- Unique regardless of case, see idx_users_username_lower
+    username VARCHAR(20) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    email VARCHAR(254) UNIQUE,
+    email_verified_at TIMESTAMP WITH TIME ZONE,
+    display_name VARCHAR(50) NOT NULL DEFAULT '',
+    bio VARCHAR(500) NOT NULL DEFAULT '',
+    avatar_url VARCHAR(500) NOT NULL DEFAULT '',
+    website VARCHAR(200) NOT NULL DEFAULT '',
+    location VARCHAR(100) NOT NULL DEFAULT '',
+    social_links JSONB NOT NULL DEFAULT '{}',
+    username_changed_at TIMESTAMP WITH TIME ZONE,
+    deletion_requested_at TIMESTAMP WITH TIME ZONE,
+    deletion_mode VARCHAR(16),
+    -- 'active', or 'pending' while awaiting admin approval
+    status VARCHAR(16) NOT NULL DEFAULT 'active',
+    -- Grant admin rights manually: UPDATE users SET is_admin = TRUE WHERE username = '...';
+    is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    invite_id BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Placeholder author for posts kept after their account was deleted. The
+-- empty password hash never verifies, so nobody can sign in as it.
+INSERT INTO users (username, password, display_name) VALUES ('deleted', '', 'Deleted user');
+
+-- Users following other users; followers can read 'followers' posts
+CREATE TABLE follows (
+    follower_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+-- Publications: team blogs with members
+CREATE TABLE publications (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    slug VARCHAR(50) UNIQUE NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE publication_members (
+    publication_id BIGINT NOT NULL REFERENCES publications(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- 'owner', 'editor' or 'writer'
+    role VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (publication_id, user_id)
 );
 
 -- Blogs table
 CREATE TABLE blogs (
     id BIGSERIAL PRIMARY KEY,
-    user_id BIGINT REFERENCES users(id),
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
     title VARCHAR(200) NOT NULL,
     content TEXT NOT NULL,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
     view_count INTEGER DEFAULT 0,
-    search_vector tsvector
+    search_vector tsvector,
+    -- 'draft', 'submitted' (awaiting a publication editor) or 'published'
+    status VARCHAR(16) NOT NULL DEFAULT 'published',
+    publication_id BIGINT REFERENCES publications(id) ON DELETE SET NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    -- Who may read the published post: 'public', 'unlisted' (by link only),
+    -- 'followers' (of the owner), 'private' (authors only) or 'password'
+    visibility VARCHAR(16) NOT NULL DEFAULT 'public',
+    -- Hash of the access password of a 'password' post
+    access_password_hash TEXT,
+    -- Author-provided summary, and the excerpt shown in lists (the summary,
+    -- or the start of the content), word count and reading time in minutes;
+    -- all computed on save
+    summary TEXT NOT NULL DEFAULT '',
+    excerpt TEXT NOT NULL DEFAULT '',
+    word_count INTEGER NOT NULL DEFAULT 0,
+    reading_time INTEGER NOT NULL DEFAULT 0,
+    -- Set when the post is moved to the trash; purged after the retention period
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Tags on a blog, lowercase
+CREATE TABLE blog_tags (
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    tag VARCHAR(32) NOT NULL,
+    PRIMARY KEY (blog_id, tag)
+);
+
+-- Co-authors of a blog besides its owner (blogs.user_id). Invites are
+-- pending until accepted_at is set.
+CREATE TABLE blog_authors (
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    -- 'editor' may edit the post, 'viewer' may only read it
+    role VARCHAR(16) NOT NULL,
+    invited_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    accepted_at TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (blog_id, user_id)
+);
+
+-- Editorial review: reviewers assigned to a submitted blog, their comments
+-- (optionally anchored to a rune range of the content) and the history of
+-- submissions and decisions
+CREATE TABLE blog_reviewers (
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    assigned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blog_id, user_id)
+);
+
+CREATE TABLE review_comments (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    anchor_start INTEGER,
+    anchor_end INTEGER,
+    quoted_text TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE review_events (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    -- submitted, reviewer_assigned, reviewer_removed, approved or changes_requested
+    action VARCHAR(32) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Secret preview links to a blog for readers without access; only the
+-- token hash is stored
+CREATE TABLE preview_links (
+    id BIGSERIAL PRIMARY KEY,
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    access_count INTEGER NOT NULL DEFAULT 0,
+    last_accessed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Reactions on blogs; one of each type per user
+CREATE TABLE blog_reactions (
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reaction VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blog_id, user_id, reaction)
+);
+
+-- Multi-part series; each blog belongs to at most one
+CREATE TABLE series (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL,
+    description VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE series_posts (
+    series_id BIGINT NOT NULL REFERENCES series(id) ON DELETE CASCADE,
+    blog_id BIGINT UNIQUE NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (series_id, blog_id)
+);
+
+-- Private "read later" bookmarks
+CREATE TABLE bookmarks (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, blog_id)
 );
 
+-- Named reading lists; private unless is_public is set
+CREATE TABLE reading_lists (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE reading_list_items (
+    list_id BIGINT NOT NULL REFERENCES reading_lists(id) ON DELETE CASCADE,
+    blog_id BIGINT NOT NULL REFERENCES blogs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (list_id, blog_id)
+);
+
+-- Personal access tokens table
+CREATE TABLE personal_access_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Single-use tokens mailed to users (password resets, email verification, ...)
+CREATE TABLE user_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    email VARCHAR(254),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- External OpenID Connect identities
+CREATE TABLE user_identities (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(254),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+
+-- Signed-in sessions; every issued JWT is bound to one
+CREATE TABLE sessions (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device VARCHAR(100) NOT NULL,
+    ip VARCHAR(45) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Previous usernames; they redirect to the new one and stay reserved until
+-- expires_at. Expired rows are replaced when the name is given up again.
+CREATE TABLE username_history (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    username VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Account data exports built in the background
+CREATE TABLE data_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    file_path VARCHAR(500) NOT NULL DEFAULT '',
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    token_hash CHAR(64) UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+-- Invite codes for invite-only registration
+CREATE TABLE invites (
+    id BIGSERIAL PRIMARY KEY,
+    created_by BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash CHAR(64) UNIQUE NOT NULL,
+    code_prefix VARCHAR(16) NOT NULL,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    use_count INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE users ADD CONSTRAINT users_invite_id_fkey
+    FOREIGN KEY (invite_id) REFERENCES invites(id) ON DELETE SET NULL;
+
 -- Indexes
-CREATE INDEX idx_users_username ON users(username);
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+CREATE INDEX idx_users_pending ON users(created_at) WHERE status = 'pending';
+CREATE INDEX idx_users_invite_id ON users(invite_id);
+CREATE INDEX idx_invites_created_by ON invites(created_by);
+CREATE INDEX idx_users_deletion_requested_at ON users(deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
 CREATE INDEX idx_blogs_user_id ON blogs(user_id);
+CREATE INDEX idx_blog_tags_tag ON blog_tags(tag);
+CREATE INDEX idx_blogs_deleted_at ON blogs(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_follows_followee_id ON follows(followee_id);
+CREATE INDEX idx_blogs_publication_id ON blogs(publication_id, status, published_at DESC);
+CREATE INDEX idx_publication_members_user_id ON publication_members(user_id);
 CREATE INDEX idx_blogs_title ON blogs(title);
 CREATE INDEX idx_blogs_created_at_id ON blogs(created_at DESC, id DESC);
 CREATE INDEX idx_blogs_view_count ON blogs(view_count DESC);
 CREATE INDEX idx_blogs_search ON blogs USING gin(search_vector);
+CREATE INDEX idx_blog_authors_user_id ON blog_authors(user_id);
+CREATE INDEX idx_preview_links_blog_id ON preview_links(blog_id);
+CREATE INDEX idx_blog_reviewers_user_id ON blog_reviewers(user_id);
+CREATE INDEX idx_review_comments_blog_id ON review_comments(blog_id, created_at);
+CREATE INDEX idx_review_events_blog_id ON review_events(blog_id, created_at);
+CREATE INDEX idx_blog_reactions_user_id ON blog_reactions(user_id, reaction, created_at DESC);
+CREATE INDEX idx_series_user_id ON series(user_id);
+CREATE INDEX idx_series_posts_series_id ON series_posts(series_id, position);
+CREATE INDEX idx_bookmarks_user_id ON bookmarks(user_id, created_at DESC);
+CREATE INDEX idx_reading_lists_user_id ON reading_lists(user_id);
+CREATE INDEX idx_reading_list_items_list_id ON reading_list_items(list_id, position);
+CREATE INDEX idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+CREATE INDEX idx_user_tokens_user_id_purpose ON user_tokens(user_id, purpose);
+CREATE INDEX idx_user_identities_user_id ON user_identities(user_id);
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX idx_data_exports_user_id ON data_exports(user_id, created_at DESC);
+CREATE UNIQUE INDEX idx_username_history_username ON username_history(LOWER(username));
 
 -- updated_at trigger
 CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -42,6 +366,21 @@ CREATE TRIGGER update_blogs_updated_at
     FOR EACH ROW
     EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_publications_updated_at
+    BEFORE UPDATE ON publications
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_series_updated_at
+    BEFORE UPDATE ON series
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_reading_lists_updated_at
+    BEFORE UPDATE ON reading_lists
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
 -- full-text search trigger
 CREATE OR REPLACE FUNCTION blogs_search_trigger()
 RETURNS TRIGGER AS $$
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type AdminHandler struct {
+	userService service.UserService
+}
+
+func NewAdminHandler(userService service.UserService) *AdminHandler {
+	return &AdminHandler{userService: userService}
+}
+
+// ListRegistrations handles GET /admin/registrations (the approval queue)
+func (h *AdminHandler) ListRegistrations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	pending, err := h.userService.ListPendingUsers()
+	if err != nil {
+		log.Printf("Error listing pending registrations: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve registrations")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pending)
+}
+
+// ReviewRegistration handles POST /admin/registrations/{id}/approve and
+// POST /admin/registrations/{id}/reject
+func (h *AdminHandler) ReviewRegistration(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/registrations/")
+	idStr, action, _ := strings.Cut(rest, "/")
+	userID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var message string
+	switch action {
+	case "approve":
+		err = h.userService.ApproveUser(userID)
+		message = "Registration approved"
+	case "reject":
+		err = h.userService.RejectUser(userID)
+		message = "Registration rejected"
+	default:
+		respondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "No pending registration for this user")
+			return
+		}
+		log.Printf("Error reviewing registration: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to review registration")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": message})
+}
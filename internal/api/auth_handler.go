@@ -3,36 +3,63 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"net/http"
 
 	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/middleware"
 	"github.com/Brownie44l1/blog/internal/service"
 )
 
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	Email      string `json:"email"`
+	InviteCode string `json:"invite_code"`
 }
 
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// UseCookie selects browser mode: the token is set as an HttpOnly
+	// cookie instead of being returned in the body
+	UseCookie bool `json:"use_cookie"`
+}
+
+type ChangePasswordRequest struct {
+	// CurrentPassword is not needed by accounts that don't have one yet
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+type ForgotPasswordRequest struct {
+	Login string `json:"login"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
 }
 
 type AuthResponse struct {
-	Token    string `json:"token"`
-	Username string `json:"username"`
+	Token     string `json:"token,omitempty"`
+	Username  string `json:"username"`
+	CSRFToken string `json:"csrf_token,omitempty"`
 }
 
 type AuthHandler struct {
-	userService service.UserService
-	jwtSecret   string
+	userService    service.UserService
+	sessionService service.SessionService
+	jwtSecret      string
+	cookies        CookieOptions
 }
 
-func NewAuthHandler(userService service.UserService, jwtSecret string) *AuthHandler {
+func NewAuthHandler(userService service.UserService, sessionService service.SessionService, jwtSecret string, cookies CookieOptions) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
-		jwtSecret:   jwtSecret,
+		userService:    userService,
+		sessionService: sessionService,
+		jwtSecret:      jwtSecret,
+		cookies:        cookies,
 	}
 }
 
@@ -50,18 +77,44 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.RegisterUser(req.Username, req.Password)
+	user, err := h.userService.RegisterUser(req.Username, req.Password, req.Email, req.InviteCode)
 	if err != nil {
+		if errors.Is(err, service.ErrRegistrationClosed) {
+			respondWithError(w, http.StatusForbidden, "Registration is closed")
+			return
+		}
+		if errors.Is(err, service.ErrInviteRequired) || errors.Is(err, service.ErrInvalidInvite) {
+			respondWithError(w, http.StatusForbidden, err.Error())
+			return
+		}
 		if errors.Is(err, service.ErrUsernameTaken) {
 			respondWithError(w, http.StatusConflict, "Username already taken")
 			return
 		}
+		if errors.Is(err, service.ErrEmailTaken) {
+			respondWithError(w, http.StatusConflict, "Email already in use")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithInvalidInput(w, err)
+			return
+		}
 		respondWithError(w, http.StatusInternalServerError, "An internal server error occurred")
 		return
 	}
 
-	tokenString, err := auth.GenerateToken(user.ID, h.jwtSecret)
+	// Accounts awaiting approval cannot sign in yet
+	if user.Status == service.UserStatusPending {
+		respondWithJSON(w, http.StatusAccepted, map[string]string{
+			"message":  "Registration received, your account is awaiting approval",
+			"username": user.Username,
+		})
+		return
+	}
+
+	tokenString, _, err := startSession(h.sessionService, h.jwtSecret, user.ID, r)
 	if err != nil {
+		log.Printf("Error starting session: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
 		return
 	}
@@ -89,20 +142,166 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.userService.Authenticate(req.Username, req.Password)
 	if err != nil {
+		if errors.Is(err, service.ErrAccountPending) {
+			respondWithError(w, http.StatusForbidden, "Your account is awaiting approval")
+			return
+		}
 		respondWithError(w, http.StatusUnauthorized, "Invalid username or password")
 		return
 	}
 
-	tokenString, err := auth.GenerateToken(user.ID, h.jwtSecret)
+	tokenString, sessionID, err := startSession(h.sessionService, h.jwtSecret, user.ID, r)
 	if err != nil {
+		log.Printf("Error starting session: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
 		return
 	}
 
+	if req.UseCookie {
+		csrfToken := auth.CSRFToken(sessionID, h.jwtSecret)
+		setSessionCookies(w, h.cookies, tokenString, csrfToken)
+		respondWithJSON(w, http.StatusOK, AuthResponse{
+			Username:  user.Username,
+			CSRFToken: csrfToken,
+		})
+		return
+	}
+
 	response := AuthResponse{
 		Token:    tokenString,
 		Username: user.Username,
 	}
 
 	respondWithJSON(w, http.StatusOK, response)
-}
\ No newline at end of file
+}
+
+// Logout handles POST /logout: it revokes the current session and clears
+// browser session cookies
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	if err := h.sessionService.Revoke(userID, sessionID); err != nil && !errors.Is(err, service.ErrSessionNotFound) {
+		log.Printf("Error revoking session on logout: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to log out")
+		return
+	}
+
+	clearSessionCookies(w, h.cookies)
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
+
+// ChangePassword handles PUT /users/me/password. Every other session is
+// signed out; the current token stays valid.
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.userService.ChangePassword(userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			respondWithError(w, http.StatusUnauthorized, "Current password is incorrect")
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithInvalidInput(w, err)
+		default:
+			log.Printf("Error changing password: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to change password")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Password changed, other sessions have been signed out"})
+}
+
+// ForgotPassword handles POST /password/forgot
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	if err := h.userService.RequestPasswordReset(req.Login); err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error requesting password reset: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	// Same response whether or not the account exists
+	respondWithJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If the account exists and has an email address, a reset link has been sent",
+	})
+}
+
+// ResetPassword handles POST /password/reset. The token may be given in the
+// body or as the ?token= query parameter. GET serves the page the emailed
+// link opens, which asks for the new password and POSTs it here.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		respondWithLinkPage(w, resetPasswordPage)
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+	if req.Token == "" {
+		req.Token = r.URL.Query().Get("token")
+	}
+
+	if err := h.userService.ResetPassword(req.Token, req.NewPassword); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			respondWithError(w, http.StatusBadRequest, "Invalid or expired reset token")
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithInvalidInput(w, err)
+		default:
+			log.Printf("Error resetting password: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to reset password")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Password has been reset, please log in again"})
+}
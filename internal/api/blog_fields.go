@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+// blogFields are the top-level JSON field names of a blog, which ?fields=
+// may select from.
+var blogFields = jsonFieldNames(reflect.TypeOf(models.Blog{}))
+
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// blogView is how a request wants blogs shaped: ?fields=title,excerpt
+// picks the fields to return (id is always kept) and
+// ?include=author,tags,stats embeds related resources.
+type blogView struct {
+	// fields is nil when the request doesn't select fields
+	fields  map[string]bool
+	include []string
+}
+
+func parseBlogView(r *http.Request) (blogView, error) {
+	view := blogView{include: splitList(r.URL.Query().Get("include"))}
+	if names := splitList(r.URL.Query().Get("fields")); len(names) > 0 {
+		view.fields = map[string]bool{"id": true}
+		for _, name := range names {
+			if !blogFields[name] {
+				return view, fmt.Errorf("%w: unknown field %q", service.ErrInvalidInput, name)
+			}
+			view.fields[name] = true
+		}
+		// Asking for a relation implies returning it
+		for _, name := range view.include {
+			view.fields[name] = true
+		}
+	}
+	return view, nil
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// shapeBlogs applies the request's blogView to blogs, embedding included
+// relations with one batched lookup each. Lists (summary true) come without
+// content, which is loaded only when ?fields= asks for it.
+func shapeBlogs(blogService service.BlogService, r *http.Request, blogs []models.Blog, summary bool) ([]any, error) {
+	view, err := parseBlogView(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(view.include) > 0 {
+		if err := blogService.Include(blogs, view.include); err != nil {
+			return nil, err
+		}
+	}
+	if summary && view.fields["content"] {
+		if err := blogService.LoadContent(blogs); err != nil {
+			return nil, err
+		}
+	}
+
+	shaped := make([]any, len(blogs))
+	for i := range blogs {
+		if view.fields == nil {
+			shaped[i] = blogs[i]
+			continue
+		}
+		b, err := json.Marshal(blogs[i])
+		if err != nil {
+			return nil, err
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return nil, err
+		}
+		for name := range fields {
+			if !view.fields[name] {
+				delete(fields, name)
+			}
+		}
+		shaped[i] = fields
+	}
+	return shaped, nil
+}
+
+// respondWithBlogs writes a list of blogs shaped by ?fields= and ?include=.
+func respondWithBlogs(w http.ResponseWriter, r *http.Request, blogService service.BlogService, blogs []models.Blog) {
+	body, err := shapeBlogs(blogService, r, blogs, true)
+	if err != nil {
+		respondWithShapeError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, body)
+}
+
+// respondWithBlog writes a single blog, content included, shaped by
+// ?fields= and ?include=.
+func respondWithBlog(w http.ResponseWriter, r *http.Request, blogService service.BlogService, blog *models.Blog) {
+	blogs := []models.Blog{*blog}
+	body, err := shapeBlogs(blogService, r, blogs, false)
+	if err != nil {
+		respondWithShapeError(w, err)
+		return
+	}
+	respondWithJSON(w, http.StatusOK, body[0])
+}
+
+func respondWithShapeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, service.ErrInvalidInput) {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("Error shaping blogs: %v", err)
+	respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blogs")
+}
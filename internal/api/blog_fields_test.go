@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+// stubBlogService records the batched lookups shapeBlogs makes. Methods it
+// doesn't override panic through the nil embedded interface.
+type stubBlogService struct {
+	service.BlogService
+	included      []string
+	loadedContent bool
+}
+
+func (s *stubBlogService) Include(blogs []models.Blog, include []string) error {
+	s.included = include
+	for i := range blogs {
+		for _, name := range include {
+			switch name {
+			case service.IncludeTags:
+				blogs[i].Tags = []string{"go"}
+			case service.IncludeAuthor:
+				blogs[i].Author = models.Author{Username: "ada"}
+			default:
+				return service.ErrInvalidInput
+			}
+		}
+	}
+	return nil
+}
+
+func (s *stubBlogService) LoadContent(blogs []models.Blog) error {
+	s.loadedContent = true
+	for i := range blogs {
+		blogs[i].Content = "full text"
+	}
+	return nil
+}
+
+func TestParseBlogView(t *testing.T) {
+	tests := []struct {
+		query       string
+		wantFields  map[string]bool
+		wantInclude []string
+	}{
+		{"", nil, nil},
+		{"include=tags", nil, []string{"tags"}},
+		{"fields=title", map[string]bool{"id": true, "title": true}, nil},
+		{"fields=title,%20excerpt,", map[string]bool{"id": true, "title": true, "excerpt": true}, nil},
+		{"fields=title&include=tags,stats", map[string]bool{"id": true, "title": true, "tags": true, "stats": true}, []string{"tags", "stats"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			view, err := parseBlogView(httptest.NewRequest("GET", "/blogs?"+tt.query, nil))
+			if err != nil {
+				t.Fatalf("parseBlogView: %v", err)
+			}
+			if !reflect.DeepEqual(view.fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", view.fields, tt.wantFields)
+			}
+			if !reflect.DeepEqual(view.include, tt.wantInclude) {
+				t.Errorf("include = %v, want %v", view.include, tt.wantInclude)
+			}
+		})
+	}
+}
+
+func TestParseBlogViewUnknownField(t *testing.T) {
+	for _, field := range []string{"password", "user_id", "UserId", "nope"} {
+		_, err := parseBlogView(httptest.NewRequest("GET", "/blogs?fields=title,"+field, nil))
+		if !errors.Is(err, service.ErrInvalidInput) {
+			t.Errorf("fields=%s: err = %v, want ErrInvalidInput", field, err)
+		}
+	}
+}
+
+func TestShapeBlogsSelectsFields(t *testing.T) {
+	blogs := []models.Blog{{ID: 1, Title: "One", Excerpt: "first"}, {ID: 2, Title: "Two", Excerpt: "second"}}
+	stub := &stubBlogService{}
+
+	shaped, err := shapeBlogs(stub, httptest.NewRequest("GET", "/blogs?fields=title&include=tags", nil), blogs, true)
+	if err != nil {
+		t.Fatalf("shapeBlogs: %v", err)
+	}
+	if !reflect.DeepEqual(stub.included, []string{"tags"}) {
+		t.Errorf("included %v, want [tags]", stub.included)
+	}
+	if stub.loadedContent {
+		t.Error("content was loaded without being asked for")
+	}
+	if len(shaped) != 2 {
+		t.Fatalf("got %d blogs, want 2", len(shaped))
+	}
+	for _, s := range shaped {
+		blog, ok := s.(map[string]json.RawMessage)
+		if !ok {
+			t.Fatalf("shaped blog is %T, want a field map", s)
+		}
+		var names []string
+		for name := range blog {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if want := []string{"id", "tags", "title"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("fields %v, want %v", names, want)
+		}
+	}
+}
+
+func TestShapeBlogsWithoutFields(t *testing.T) {
+	blogs := []models.Blog{{ID: 1, Title: "One"}}
+	stub := &stubBlogService{}
+
+	shaped, err := shapeBlogs(stub, httptest.NewRequest("GET", "/blogs", nil), blogs, true)
+	if err != nil {
+		t.Fatalf("shapeBlogs: %v", err)
+	}
+	if stub.included != nil || stub.loadedContent {
+		t.Error("shapeBlogs made lookups the request didn't ask for")
+	}
+	blog, ok := shaped[0].(models.Blog)
+	if !ok {
+		t.Fatalf("shaped blog is %T, want models.Blog", shaped[0])
+	}
+	if blog.Content != "" {
+		t.Errorf("list blog has content %q", blog.Content)
+	}
+}
+
+func TestShapeBlogsLoadsContentForLists(t *testing.T) {
+	blogs := []models.Blog{{ID: 1, Title: "One"}}
+	stub := &stubBlogService{}
+
+	shaped, err := shapeBlogs(stub, httptest.NewRequest("GET", "/blogs?fields=content", nil), blogs, true)
+	if err != nil {
+		t.Fatalf("shapeBlogs: %v", err)
+	}
+	if !stub.loadedContent {
+		t.Fatal("content was not loaded")
+	}
+	blog := shaped[0].(map[string]json.RawMessage)
+	if string(blog["content"]) != `"full text"` {
+		t.Errorf("content = %s, want \"full text\"", blog["content"])
+	}
+
+	// A single blog already has its content
+	stub = &stubBlogService{}
+	single := []models.Blog{{ID: 1, Content: "body"}}
+	if _, err := shapeBlogs(stub, httptest.NewRequest("GET", "/blogs/1?fields=content", nil), single, false); err != nil {
+		t.Fatalf("shapeBlogs: %v", err)
+	}
+	if stub.loadedContent {
+		t.Error("content was reloaded for a single blog")
+	}
+}
+
+func TestShapeBlogsRejectsBadRequests(t *testing.T) {
+	for _, query := range []string{"fields=secret", "include=comments"} {
+		_, err := shapeBlogs(&stubBlogService{}, httptest.NewRequest("GET", "/blogs?"+query, nil), []models.Blog{{ID: 1}}, true)
+		if !errors.Is(err, service.ErrInvalidInput) {
+			t.Errorf("%s: err = %v, want ErrInvalidInput", query, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"strconv"
@@ -13,23 +14,52 @@ import (
 )
 
 type BlogHandler struct {
-	blogService service.BlogService
+	blogService     service.BlogService
+	bookmarkService service.BookmarkService
 }
 
-func NewBlogHandler(blogService service.BlogService) *BlogHandler {
+func NewBlogHandler(blogService service.BlogService, bookmarkService service.BookmarkService) *BlogHandler {
 	return &BlogHandler{
-		blogService: blogService,
+		blogService:     blogService,
+		bookmarkService: bookmarkService,
 	}
 }
 
 type CreateBlogRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// Summary replaces the automatic excerpt shown in lists
+	Summary string `json:"summary"`
+	// Tags are up to five topic labels, e.g. ["go", "databases"]
+	Tags []string `json:"tags"`
+	// PublicationID posts to a publication the user is a member of
+	PublicationID *int64 `json:"publication_id"`
+	// Draft saves the post without publishing it
+	Draft bool `json:"draft"`
+	// Visibility defaults to public; Password is required for password posts
+	Visibility string `json:"visibility"`
+	Password   string `json:"password"`
+}
+
+type SetVisibilityRequest struct {
+	Visibility string `json:"visibility"`
+	Password   string `json:"password"`
+}
+
+// blogPasswordHeader carries the access password of a password-protected post
+const blogPasswordHeader = "X-Blog-Password"
+
+// blogPassword reads the access password a request supplied, if any.
+func blogPassword(r *http.Request) string {
+	return r.Header.Get(blogPasswordHeader)
 }
 
 type UpdateBlogRequest struct {
     Title   string `json:"title"`
     Content string `json:"content"`
+    Summary string `json:"summary"`
+    // Tags replaces the post's tags; leave it out to keep them
+    Tags []string `json:"tags"`
 }
 
 // CreateBlog handles POST /blogs/create
@@ -53,12 +83,28 @@ func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
 	}
 
 	blog := &models.Blog{
-		UserId:  userID,
-		Title:   req.Title,
-		Content: req.Content,
+		UserId:        userID,
+		Title:         req.Title,
+		Content:       req.Content,
+		Summary:       req.Summary,
+		Tags:          req.Tags,
+		PublicationID: req.PublicationID,
+		Status:        service.BlogStatusPublished,
+		Visibility:    req.Visibility,
+	}
+	if req.Draft {
+		blog.Status = service.BlogStatusDraft
 	}
 
-	if err := h.blogService.Create(blog); err != nil {
+	if err := h.blogService.Create(blog, req.Password); err != nil {
+		if errors.Is(err, service.ErrNotPublicationMember) {
+			respondWithError(w, http.StatusForbidden, "You are not a member of this publication")
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		log.Printf("Error creating blog: %v", err)
 		respondWithError(w, http.StatusInternalServerError, err.Error())
 		return
@@ -67,7 +113,8 @@ func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusCreated, blog)
 }
 
-// GetBlog handles GET /blogs/{id}
+// GetBlog handles GET /blogs/{id}. Password-protected posts need their
+// access password in the X-Blog-Password header.
 func (h *BlogHandler) GetBlog(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -84,21 +131,63 @@ func (h *BlogHandler) GetBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blog, err := h.blogService.GetByID(id)
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	blog, err := h.blogService.View(id, viewerID, blogPassword(r))
 	if err != nil {
-		if strings.Contains(err.Error(), "no rows") {
-			respondWithError(w, http.StatusNotFound, "Blog not found")
-			return
-		}
-		log.Printf("Error retrieving blog: %v", err)
-		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blog")
+		respondWithBlogViewError(w, err, "retrieve blog")
 		return
 	}
+	blogs := []models.Blog{*blog}
+	markBookmarked(h.bookmarkService, r, blogs)
 
-	respondWithJSON(w, http.StatusOK, blog)
+	respondWithBlog(w, r, h.blogService, &blogs[0])
+}
+
+// GetRelatedBlogs handles GET /blogs/{id}/related. Password-protected posts
+// need their access password in the X-Blog-Password header.
+func (h *BlogHandler) GetRelatedBlogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Extract ID from path: /blogs/123/related
+	path := strings.TrimPrefix(r.URL.Path, "/blogs/")
+	id, err := strconv.ParseInt(strings.Split(path, "/")[0], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	blogs, err := h.blogService.Related(id, viewerID, blogPassword(r))
+	if err != nil {
+		respondWithBlogViewError(w, err, "retrieve related blogs")
+		return
+	}
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
 }
 
-// GetMyBlogs handles GET /blogs/me (get blogs for the authenticated user)
+// respondWithBlogViewError maps errors from reading a single post
+func respondWithBlogViewError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrBlogNotFound) || strings.Contains(err.Error(), "no rows"):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrBlogPasswordRequired):
+		respondWithError(w, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, service.ErrFollowersOnly):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrTooManyPasswordTries):
+		respondWithError(w, http.StatusTooManyRequests, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// GetMyBlogs handles GET /blogs/me (get blogs for the authenticated user,
+// drafts included)
 func (h *BlogHandler) GetMyBlogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -112,14 +201,14 @@ func (h *BlogHandler) GetMyBlogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blogs, err := h.blogService.GetByUserID(userID)
+	blogs, err := h.blogService.GetMine(userID)
 	if err != nil {
 		log.Printf("Error retrieving user blogs: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blogs")
 		return
 	}
-
-	respondWithJSON(w, http.StatusOK, blogs)
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
 }
 
 // GetUserBlogs handles GET /users/{userId}/blogs
@@ -139,14 +228,15 @@ func (h *BlogHandler) GetUserBlogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blogs, err := h.blogService.GetByUserID(userID)
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	blogs, err := h.blogService.GetByUserID(userID, viewerID)
 	if err != nil {
 		log.Printf("Error retrieving user blogs: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blogs")
 		return
 	}
-
-	respondWithJSON(w, http.StatusOK, blogs)
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
 }
 
 func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
@@ -180,6 +270,8 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
         UserId:  userID,
         Title:   req.Title,
         Content: req.Content,
+        Summary: req.Summary,
+        Tags:    req.Tags,
     }
 
     if err := h.blogService.Update(blog); err != nil {
@@ -187,6 +279,14 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
             respondWithError(w, http.StatusNotFound, "Blog not found or unauthorized")
             return
         }
+        if strings.Contains(err.Error(), "unauthorized") {
+            respondWithError(w, http.StatusForbidden, "You do not have permission to edit this blog")
+            return
+        }
+        if errors.Is(err, service.ErrInvalidInput) {
+            respondWithError(w, http.StatusBadRequest, err.Error())
+            return
+        }
         respondWithError(w, http.StatusInternalServerError, err.Error())
         return
     }
@@ -194,7 +294,7 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
     respondWithJSON(w, http.StatusOK, blog)
 }
 
-// DeleteBlog handles DELETE /blogs/{id}
+// DeleteBlog handles DELETE /blogs/{id} (moves the blog to the trash)
 func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
 		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -228,10 +328,11 @@ func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Blog deleted successfully"})
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Blog moved to trash"})
 }
 
-// ListBlogs handles GET /blogs
+// ListBlogs handles GET /blogs. Like every blog list it returns summaries;
+// ?fields=content adds the post bodies.
 func (h *BlogHandler) ListBlogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -261,17 +362,18 @@ func (h *BlogHandler) ListBlogs(w http.ResponseWriter, r *http.Request) {
 		offset = parsedOffset
 	}
 
-	blogs, err := h.blogService.ListAll(limit, offset)
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	blogs, err := h.blogService.ListAll(viewerID, limit, offset)
 	if err != nil {
 		log.Printf("Error listing blogs: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blogs")
 		return
 	}
-
-	respondWithJSON(w, http.StatusOK, blogs)
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
 }
 
-// SearchBlogs handles GET /blogs/search?q=query
+// SearchBlogs handles GET /blogs/search?q=query[&fields=content]
 func (h *BlogHandler) SearchBlogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
@@ -285,12 +387,183 @@ func (h *BlogHandler) SearchBlogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	blogs, err := h.blogService.Search(query)
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	blogs, err := h.blogService.Search(query, viewerID)
 	if err != nil {
 		log.Printf("Error searching blogs: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to search blogs")
 		return
 	}
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
+
+// ChangeStatus handles POST /blogs/{id}/publish and /blogs/{id}/unpublish
+func (h *BlogHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/blogs/"), "/")
+	if len(parts) != 2 {
+		respondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	blogID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var blog *models.Blog
+	switch parts[1] {
+	case "publish":
+		blog, err = h.blogService.Publish(blogID, userID)
+	case "unpublish":
+		blog, err = h.blogService.Unpublish(blogID, userID)
+	default:
+		respondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBlogNotFound):
+			respondWithError(w, http.StatusNotFound, "Blog not found")
+		case errors.Is(err, service.ErrNotBlogOwner), errors.Is(err, service.ErrNotPublicationMember),
+			errors.Is(err, service.ErrPublicationRole):
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Error changing blog status: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to update blog status")
+		}
+		return
+	}
 
-	respondWithJSON(w, http.StatusOK, blogs)
+	respondWithJSON(w, http.StatusOK, blog)
+}
+
+// SetVisibility handles PUT /blogs/{id}/visibility
+func (h *BlogHandler) SetVisibility(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/blogs/"), "/")
+	blogID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || len(parts) != 2 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req SetVisibilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	blog, err := h.blogService.SetVisibility(blogID, userID, req.Visibility, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrBlogNotFound):
+			respondWithError(w, http.StatusNotFound, "Blog not found")
+		case errors.Is(err, service.ErrNotBlogOwner), errors.Is(err, service.ErrNotPublicationMember),
+			errors.Is(err, service.ErrPublicationRole):
+			respondWithError(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Error changing blog visibility: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to update blog visibility")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, blog)
+}
+
+// GetTrash handles GET /blogs/trash
+func (h *BlogHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogs, err := h.blogService.Trash(userID)
+	if err != nil {
+		log.Printf("Error retrieving trash: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve trash")
+		return
+	}
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
+
+// TrashItem handles POST /blogs/trash/{id}/restore and DELETE
+// /blogs/trash/{id} (delete permanently)
+func (h *BlogHandler) TrashItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/blogs/trash/"), "/")
+	blogID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "restore":
+		blog, err := h.blogService.Restore(blogID, userID)
+		if err != nil {
+			if errors.Is(err, service.ErrBlogNotFound) {
+				respondWithError(w, http.StatusNotFound, "Blog not found in trash")
+				return
+			}
+			log.Printf("Error restoring blog: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to restore blog")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, blog)
+	case r.Method == http.MethodDelete && len(parts) == 1:
+		if err := h.blogService.Purge(blogID, userID); err != nil {
+			if errors.Is(err, service.ErrBlogNotFound) {
+				respondWithError(w, http.StatusNotFound, "Blog not found in trash")
+				return
+			}
+			log.Printf("Error purging blog: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to delete blog")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Blog deleted permanently"})
+	default:
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	}
 }
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type BookmarkHandler struct {
+	bookmarkService service.BookmarkService
+	blogService     service.BlogService
+}
+
+func NewBookmarkHandler(bookmarkService service.BookmarkService, blogService service.BlogService) *BookmarkHandler {
+	return &BookmarkHandler{
+		bookmarkService: bookmarkService,
+		blogService:     blogService,
+	}
+}
+
+type BookmarkRequest struct {
+	BlogID int64 `json:"blog_id"`
+}
+
+// markBookmarked sets the bookmarked flag on blogs when the request is
+// signed in. The flag is a convenience, so failures are only logged.
+func markBookmarked(bookmarks service.BookmarkService, r *http.Request, blogs []models.Blog) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok || len(blogs) == 0 {
+		return
+	}
+	if err := bookmarks.MarkBookmarked(userID, blogs); err != nil {
+		log.Printf("⚠️  Failed to mark bookmarks for user %d: %v", userID, err)
+	}
+}
+
+// ListBookmarks handles GET /users/me/bookmarks?limit=&offset=
+func (h *BookmarkHandler) ListBookmarks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var limit, offset int64 = 10, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = parsed
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = parsed
+	}
+
+	blogs, err := h.bookmarkService.List(userID, limit, offset)
+	if err != nil {
+		log.Printf("Error listing bookmarks: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve bookmarks")
+		return
+	}
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
+
+// AddBookmark handles POST /users/me/bookmarks
+func (h *BookmarkHandler) AddBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.bookmarkService.Add(userID, req.BlogID); err != nil {
+		if errors.Is(err, service.ErrBlogNotFound) {
+			respondWithError(w, http.StatusNotFound, "Blog not found")
+			return
+		}
+		log.Printf("Error adding bookmark: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to add bookmark")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]any{"blog_id": req.BlogID, "bookmarked": true})
+}
+
+// RemoveBookmark handles DELETE /users/me/bookmarks/{blogId}
+func (h *BookmarkHandler) RemoveBookmark(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/users/me/bookmarks/")
+	blogID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	if err := h.bookmarkService.Remove(userID, blogID); err != nil {
+		if errors.Is(err, service.ErrBookmarkNotFound) {
+			respondWithError(w, http.StatusNotFound, "Bookmark not found")
+			return
+		}
+		log.Printf("Error removing bookmark: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to remove bookmark")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Bookmark removed successfully"})
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type CoAuthorHandler struct {
+	coAuthorService service.CoAuthorService
+}
+
+func NewCoAuthorHandler(coAuthorService service.CoAuthorService) *CoAuthorHandler {
+	return &CoAuthorHandler{
+		coAuthorService: coAuthorService,
+	}
+}
+
+type InviteCoAuthorRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// parseCoAuthorPath extracts the IDs from /blogs/{id}/authors[/{userId}];
+// userID is 0 when absent
+func parseCoAuthorPath(path string) (blogID, userID int64, err error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/blogs/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] != "authors" {
+		return 0, 0, errors.New("invalid co-author path")
+	}
+	if blogID, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 3 {
+		userID, err = strconv.ParseInt(parts[2], 10, 64)
+	}
+	return blogID, userID, err
+}
+
+// respondWithCoAuthorError maps co-author service errors to responses
+func respondWithCoAuthorError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrBlogNotFound):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		respondWithError(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrCoAuthorNotFound):
+		respondWithError(w, http.StatusNotFound, "Co-author not found")
+	case errors.Is(err, service.ErrNotBlogOwner):
+		respondWithError(w, http.StatusForbidden, "Only the owner of the post can do that")
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// ListCoAuthors handles GET /blogs/{id}/authors
+func (h *CoAuthorHandler) ListCoAuthors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	blogID, _, err := parseCoAuthorPath(r.URL.Path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	coAuthors, err := h.coAuthorService.List(blogID, viewerID)
+	if err != nil {
+		respondWithCoAuthorError(w, err, "retrieve co-authors")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, coAuthors)
+}
+
+// InviteCoAuthor handles POST /blogs/{id}/authors (owner only)
+func (h *CoAuthorHandler) InviteCoAuthor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, _, err := parseCoAuthorPath(r.URL.Path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req InviteCoAuthorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	coAuthor, err := h.coAuthorService.Invite(userID, blogID, req.Username, req.Role)
+	if err != nil {
+		respondWithCoAuthorError(w, err, "invite co-author")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, coAuthor)
+}
+
+// RemoveCoAuthor handles DELETE /blogs/{id}/authors/{userId}. Owners may
+// remove anyone; co-authors may remove themselves.
+func (h *CoAuthorHandler) RemoveCoAuthor(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, coAuthorID, err := parseCoAuthorPath(r.URL.Path)
+	if err != nil || coAuthorID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog or user ID")
+		return
+	}
+
+	if err := h.coAuthorService.Remove(userID, blogID, coAuthorID); err != nil {
+		respondWithCoAuthorError(w, err, "remove co-author")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Co-author removed successfully"})
+}
+
+// ListInvites handles GET /users/me/coauthor-invites
+func (h *CoAuthorHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	invites, err := h.coAuthorService.PendingInvites(userID)
+	if err != nil {
+		respondWithCoAuthorError(w, err, "retrieve co-author invites")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, invites)
+}
+
+// RespondToInvite handles POST (accept) and DELETE (decline)
+// /users/me/coauthor-invites/{blogId}
+func (h *CoAuthorHandler) RespondToInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/users/me/coauthor-invites/")
+	blogID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		if err := h.coAuthorService.Remove(userID, blogID, userID); err != nil {
+			respondWithCoAuthorError(w, err, "decline co-author invite")
+			return
+		}
+		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Invite declined"})
+		return
+	}
+
+	if err := h.coAuthorService.Accept(userID, blogID); err != nil {
+		respondWithCoAuthorError(w, err, "accept co-author invite")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "You are now a co-author of this post"})
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// RequestExport handles POST /users/me/export. The archive is built in the
+// background; poll GET /users/me/export or wait for the email.
+func (h *ExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.exportService.Request(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrExportInProgress) {
+			respondWithError(w, http.StatusConflict, "An export is already being prepared")
+			return
+		}
+		log.Printf("Error requesting export: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to start export")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, export)
+}
+
+// GetExport handles GET /users/me/export (status of the latest export)
+func (h *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.exportService.Latest(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrExportNotFound) {
+			respondWithError(w, http.StatusNotFound, "No export has been requested")
+			return
+		}
+		log.Printf("Error retrieving export: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve export")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, export)
+}
+
+// DownloadMyExport handles GET /users/me/export/download
+func (h *ExportHandler) DownloadMyExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	f, export, err := h.exportService.Open(userID)
+	h.serveExport(w, r, f, export, err)
+}
+
+// Download handles GET /exports/download?token=... from the emailed link
+func (h *ExportHandler) Download(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	f, export, err := h.exportService.OpenByToken(r.URL.Query().Get("token"))
+	h.serveExport(w, r, f, export, err)
+}
+
+func (h *ExportHandler) serveExport(w http.ResponseWriter, r *http.Request, f *os.File, export *models.DataExport, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrExportNotFound) {
+			respondWithError(w, http.StatusNotFound, "Export not found or link expired")
+			return
+		}
+		log.Printf("Error opening export: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve export")
+		return
+	}
+	defer f.Close()
+
+	name := fmt.Sprintf("blog-export-%s.zip", export.CreatedAt.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Cache-Control", "no-store")
+	http.ServeContent(w, r, name, export.CreatedAt, f)
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type FollowHandler struct {
+	followService service.FollowService
+}
+
+func NewFollowHandler(followService service.FollowService) *FollowHandler {
+	return &FollowHandler{
+		followService: followService,
+	}
+}
+
+// Follow handles POST (follow) and DELETE (unfollow) /users/{id}/follow
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	idStr := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/users/"), "/follow")
+	followeeID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		err = h.followService.Unfollow(userID, followeeID)
+	} else {
+		err = h.followService.Follow(userID, followeeID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			respondWithError(w, http.StatusNotFound, "User not found")
+		case errors.Is(err, service.ErrNotFollowing):
+			respondWithError(w, http.StatusNotFound, "You are not following this user")
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Error updating follow: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to update follow")
+		}
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		respondWithJSON(w, http.StatusOK, map[string]string{"message": "Unfollowed"})
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Following"})
+}
+
+// ListFollowing handles GET /users/me/following
+func (h *FollowHandler) ListFollowing(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	users, err := h.followService.Following(userID)
+	if err != nil {
+		log.Printf("Error listing followed users: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve followed users")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, users)
+}
@@ -2,7 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/service"
 )
 
 // respondWithJSON sends a JSON response with the given status code and data
@@ -17,4 +22,85 @@ func respondWithJSON(w http.ResponseWriter, status int, data interface{}) {
 // respondWithError sends a JSON error response with the given status code and message
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	respondWithJSON(w, code, map[string]string{"error": message})
-}
\ No newline at end of file
+}
+
+// respondWithInvalidInput sends a 400 for a validation error, including
+// per-field messages when the service reported them
+func respondWithInvalidInput(w http.ResponseWriter, err error) {
+	var ve *service.ValidationError
+	if errors.As(err, &ve) {
+		respondWithJSON(w, http.StatusBadRequest, map[string]any{
+			"error":  err.Error(),
+			"fields": ve.Fields,
+		})
+		return
+	}
+	respondWithError(w, http.StatusBadRequest, err.Error())
+}
+
+// clientIP returns the address of the connecting client without its port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// startSession records a login from this request and issues a JWT bound to it
+func startSession(sessionService service.SessionService, jwtSecret string, userID int64, r *http.Request) (string, int64, error) {
+	session, err := sessionService.Start(userID, r.UserAgent(), clientIP(r))
+	if err != nil {
+		return "", 0, err
+	}
+	token, err := auth.GenerateToken(userID, session.ID, jwtSecret)
+	if err != nil {
+		return "", 0, err
+	}
+	return token, session.ID, nil
+}
+
+// CookieOptions controls the attributes of browser session cookies
+type CookieOptions struct {
+	Secure   bool
+	SameSite http.SameSite
+}
+
+// setSessionCookies stores the JWT in an HttpOnly cookie and the matching
+// CSRF token in a cookie the frontend can read
+func setSessionCookies(w http.ResponseWriter, opts CookieOptions, token, csrfToken string) {
+	maxAge := int(auth.TokenTTL.Seconds())
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   opts.Secure,
+		SameSite: opts.SameSite,
+	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     auth.CSRFCookieName,
+		Value:    csrfToken,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: false,
+		Secure:   opts.Secure,
+		SameSite: opts.SameSite,
+	})
+}
+
+// clearSessionCookies removes the browser session cookies
+func clearSessionCookies(w http.ResponseWriter, opts CookieOptions) {
+	for _, name := range []string{auth.SessionCookieName, auth.CSRFCookieName} {
+		http.SetCookie(w, &http.Cookie{
+			Name:     name,
+			Value:    "",
+			Path:     "/",
+			MaxAge:   -1,
+			HttpOnly: name == auth.SessionCookieName,
+			Secure:   opts.Secure,
+			SameSite: opts.SameSite,
+		})
+	}
+}
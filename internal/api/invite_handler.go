@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type InviteHandler struct {
+	inviteService service.InviteService
+}
+
+func NewInviteHandler(inviteService service.InviteService) *InviteHandler {
+	return &InviteHandler{inviteService: inviteService}
+}
+
+type CreateInviteRequest struct {
+	MaxUses       int `json:"max_uses"`
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+type CreateInviteResponse struct {
+	models.Invite
+	Code string `json:"code"`
+}
+
+// CreateInvite handles POST /users/me/invites
+func (h *InviteHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	invite, code, err := h.inviteService.Create(userID, req.MaxUses, req.ExpiresInDays)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error creating invite: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create invite")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, CreateInviteResponse{Invite: *invite, Code: code})
+}
+
+// ListInvites handles GET /users/me/invites
+func (h *InviteHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	invites, err := h.inviteService.List(userID)
+	if err != nil {
+		log.Printf("Error listing invites: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve invites")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, invites)
+}
+
+// RevokeInvite handles DELETE /users/me/invites/{id}
+func (h *InviteHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/users/me/invites/")
+	inviteID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid invite ID")
+		return
+	}
+
+	if err := h.inviteService.Revoke(userID, inviteID); err != nil {
+		if errors.Is(err, service.ErrInviteNotFound) {
+			respondWithError(w, http.StatusNotFound, "Invite not found")
+			return
+		}
+		log.Printf("Error revoking invite: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke invite")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Invite revoked successfully"})
+}
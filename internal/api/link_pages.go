@@ -0,0 +1,92 @@
+package api
+
+import "net/http"
+
+// Emailed links open these pages in a browser. Each reads the token from
+// its own URL and POSTs it to the JSON endpoint at the same path, so mail
+// scanners that follow links can't redeem tokens by fetching them.
+
+const resetPasswordPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Reset your password</title>
+</head>
+<body>
+<h1>Reset your password</h1>
+<form id="form">
+<p><label>New password<br><input type="password" name="password" autocomplete="new-password" required></label></p>
+<p><label>Repeat new password<br><input type="password" name="confirm" autocomplete="new-password" required></label></p>
+<p><button type="submit">Set password</button></p>
+</form>
+<p id="result" role="status"></p>
+<script>
+const token = new URLSearchParams(location.search).get("token") || "";
+history.replaceState(null, "", location.pathname);
+const form = document.getElementById("form");
+const result = document.getElementById("result");
+form.addEventListener("submit", async (e) => {
+  e.preventDefault();
+  if (form.password.value !== form.confirm.value) {
+    result.textContent = "The passwords don't match.";
+    return;
+  }
+  const res = await fetch(location.pathname, {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({token: token, new_password: form.password.value}),
+  });
+  const body = await res.json().catch(() => ({}));
+  result.textContent = body.message || body.error || "Something went wrong.";
+  if (res.ok) form.hidden = true;
+});
+</script>
+</body>
+</html>
+`
+
+const verifyEmailPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Verify your email address</title>
+</head>
+<body>
+<h1>Verify your email address</h1>
+<form id="form">
+<p><button type="submit">Confirm my email address</button></p>
+</form>
+<p id="result" role="status"></p>
+<script>
+const token = new URLSearchParams(location.search).get("token") || "";
+history.replaceState(null, "", location.pathname);
+const form = document.getElementById("form");
+const result = document.getElementById("result");
+form.addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const res = await fetch(location.pathname, {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({token: token}),
+  });
+  const body = await res.json().catch(() => ({}));
+  result.textContent = body.message || body.error || "Something went wrong.";
+  if (res.ok) form.hidden = true;
+});
+</script>
+</body>
+</html>
+`
+
+// respondWithLinkPage sends one of the pages above. The token is in the
+// page's URL, so it must not leak through caches or the Referer header.
+func respondWithLinkPage(w http.ResponseWriter, page string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(page))
+}
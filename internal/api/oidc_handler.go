@@ -0,0 +1,250 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+// oidcStateCookieName holds the state of a sign-in or link in progress, so
+// the callback only completes in the browser that started it.
+const oidcStateCookieName = "blog_oidc_state"
+
+type OIDCHandler struct {
+	oidcService    service.OIDCService
+	sessionService service.SessionService
+	jwtSecret      string
+	cookies        CookieOptions
+}
+
+func NewOIDCHandler(oidcService service.OIDCService, sessionService service.SessionService, jwtSecret string, cookies CookieOptions) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:    oidcService,
+		sessionService: sessionService,
+		jwtSecret:      jwtSecret,
+		cookies:        cookies,
+	}
+}
+
+// setStateCookie binds state to this browser. It is Lax whatever the
+// session cookies use, as the provider's redirect back is a cross-site
+// navigation.
+func (h *OIDCHandler) setStateCookie(w http.ResponseWriter, state string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    state,
+		Path:     "/auth/oidc/",
+		MaxAge:   int(service.OIDCStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// takeStateCookie clears the state cookie and reports whether it held state.
+func (h *OIDCHandler) takeStateCookie(w http.ResponseWriter, r *http.Request, state string) bool {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     "/auth/oidc/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.cookies.Secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	cookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil || state == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) == 1
+}
+
+// parseOIDCPath splits /auth/oidc/{provider}/{action}
+func parseOIDCPath(path string) (provider, action string) {
+	rest := strings.TrimPrefix(path, "/auth/oidc/")
+	provider, action, _ = strings.Cut(rest, "/")
+	return provider, action
+}
+
+// ListProviders handles GET /auth/oidc/providers
+func (h *OIDCHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, map[string][]string{"providers": h.oidcService.Providers()})
+}
+
+// Login handles GET /auth/oidc/{provider}/login by redirecting to the provider
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	provider, _ := parseOIDCPath(r.URL.Path)
+	authURL, state, err := h.oidcService.BeginLogin(provider, 0)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			respondWithError(w, http.StatusNotFound, "Unknown identity provider")
+			return
+		}
+		log.Printf("Error starting OIDC login: %v", err)
+		respondWithError(w, http.StatusBadGateway, "Identity provider is unavailable")
+		return
+	}
+
+	h.setStateCookie(w, state)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Link handles POST /auth/oidc/{provider}/link. It returns the provider URL
+// the signed-in user should visit to link their external account, in the
+// same browser: the link only completes where the state cookie was set.
+func (h *OIDCHandler) Link(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	provider, _ := parseOIDCPath(r.URL.Path)
+	authURL, state, err := h.oidcService.BeginLogin(provider, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownProvider) {
+			respondWithError(w, http.StatusNotFound, "Unknown identity provider")
+			return
+		}
+		log.Printf("Error starting OIDC link: %v", err)
+		respondWithError(w, http.StatusBadGateway, "Identity provider is unavailable")
+		return
+	}
+
+	h.setStateCookie(w, state)
+	respondWithJSON(w, http.StatusOK, map[string]string{"url": authURL})
+}
+
+// Callback handles GET /auth/oidc/{provider}/callback and issues a blog JWT
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	// A state that didn't start in this browser was planted by someone else
+	if !h.takeStateCookie(w, r, q.Get("state")) {
+		respondWithError(w, http.StatusBadRequest, "Invalid or expired login state, please try again")
+		return
+	}
+	if errCode := q.Get("error"); errCode != "" {
+		respondWithError(w, http.StatusUnauthorized, "Sign-in was cancelled or denied: "+errCode)
+		return
+	}
+
+	provider, _ := parseOIDCPath(r.URL.Path)
+	user, err := h.oidcService.CompleteLogin(provider, q.Get("state"), q.Get("code"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnknownProvider):
+			respondWithError(w, http.StatusNotFound, "Unknown identity provider")
+		case errors.Is(err, service.ErrInvalidState):
+			respondWithError(w, http.StatusBadRequest, "Invalid or expired login state, please try again")
+		case errors.Is(err, service.ErrIdentityLinked):
+			respondWithError(w, http.StatusConflict, "This account is already linked to another user")
+		case errors.Is(err, service.ErrRegistrationClosed):
+			respondWithError(w, http.StatusForbidden, "No account is linked to this identity and registration is closed")
+		case errors.Is(err, service.ErrAccountPending):
+			respondWithError(w, http.StatusForbidden, "Your account is awaiting approval")
+		default:
+			log.Printf("Error completing OIDC login: %v", err)
+			respondWithError(w, http.StatusUnauthorized, "Sign-in failed")
+		}
+		return
+	}
+
+	tokenString, _, err := startSession(h.sessionService, h.jwtSecret, user.ID, r)
+	if err != nil {
+		log.Printf("Error starting session: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to generate authentication token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, AuthResponse{
+		Token:    tokenString,
+		Username: user.Username,
+	})
+}
+
+// ListIdentities handles GET /users/me/identities
+func (h *OIDCHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(userID)
+	if err != nil {
+		log.Printf("Error listing identities: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve identities")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, identities)
+}
+
+// UnlinkIdentity handles DELETE /users/me/identities/{id}
+func (h *OIDCHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	idStr := strings.TrimPrefix(r.URL.Path, "/users/me/identities/")
+	identityID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid identity ID")
+		return
+	}
+
+	if err := h.oidcService.Unlink(userID, identityID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrIdentityNotFound):
+			respondWithError(w, http.StatusNotFound, "Identity not found")
+		case errors.Is(err, service.ErrLastLoginMethod):
+			respondWithError(w, http.StatusConflict, "Set a password before removing your only linked account")
+		default:
+			log.Printf("Error unlinking identity: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to unlink identity")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Identity unlinked successfully"})
+}
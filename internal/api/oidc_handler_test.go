@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+// stubOIDCService starts every sign-in with the same state and records
+// whether a callback reached the service.
+type stubOIDCService struct {
+	service.OIDCService
+	completed bool
+}
+
+func (s *stubOIDCService) BeginLogin(provider string, linkUserID int64) (string, string, error) {
+	return "https://idp.test/authorize?state=the-state", "the-state", nil
+}
+
+func (s *stubOIDCService) CompleteLogin(provider, state, code string) (*models.User, error) {
+	s.completed = true
+	return nil, service.ErrInvalidState
+}
+
+func TestOIDCLoginSetsStateCookie(t *testing.T) {
+	h := NewOIDCHandler(&stubOIDCService{}, nil, "secret", CookieOptions{Secure: true})
+	w := httptest.NewRecorder()
+	h.Login(w, httptest.NewRequest(http.MethodGet, "/auth/oidc/mock/login", nil))
+
+	if w.Code != http.StatusFound {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusFound)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	c := cookies[0]
+	if c.Name != oidcStateCookieName || c.Value != "the-state" {
+		t.Errorf("cookie %s=%s, want %s=the-state", c.Name, c.Value, oidcStateCookieName)
+	}
+	if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode || c.MaxAge <= 0 {
+		t.Errorf("cookie attributes %+v, want HttpOnly, Secure, Lax and short-lived", c)
+	}
+}
+
+func TestOIDCCallbackRequiresStateCookie(t *testing.T) {
+	tests := []struct {
+		name     string
+		cookie   string
+		wantCall bool
+	}{
+		{"no cookie", "", false},
+		{"another browser's state", "other-state", false},
+		{"matching state", "the-state", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubOIDCService{}
+			h := NewOIDCHandler(stub, nil, "secret", CookieOptions{})
+			r := httptest.NewRequest(http.MethodGet, "/auth/oidc/mock/callback?state=the-state&code=abc", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: oidcStateCookieName, Value: tt.cookie})
+			}
+			w := httptest.NewRecorder()
+			h.Callback(w, r)
+
+			if stub.completed != tt.wantCall {
+				t.Errorf("CompleteLogin called = %v, want %v", stub.completed, tt.wantCall)
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			cleared := false
+			for _, c := range w.Result().Cookies() {
+				if c.Name == oidcStateCookieName && c.MaxAge < 0 {
+					cleared = true
+				}
+			}
+			if !cleared {
+				t.Error("the state cookie was not cleared")
+			}
+		})
+	}
+}
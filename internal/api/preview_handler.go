@@ -0,0 +1,177 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type PreviewHandler struct {
+	previewService service.PreviewService
+}
+
+func NewPreviewHandler(previewService service.PreviewService) *PreviewHandler {
+	return &PreviewHandler{previewService: previewService}
+}
+
+type CreatePreviewLinkRequest struct {
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+type CreatePreviewLinkResponse struct {
+	models.PreviewLink
+	URL string `json:"url"`
+}
+
+// parsePreviewLinkPath extracts the IDs from /blogs/{id}/previews[/{linkId}];
+// linkID is 0 when absent
+func parsePreviewLinkPath(path string) (blogID, linkID int64, err error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/blogs/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[1] != "previews" {
+		return 0, 0, errors.New("invalid preview link path")
+	}
+	if blogID, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if len(parts) == 3 {
+		linkID, err = strconv.ParseInt(parts[2], 10, 64)
+	}
+	return blogID, linkID, err
+}
+
+// respondWithPreviewError maps preview service errors to responses
+func respondWithPreviewError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrBlogNotFound):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrPreviewLinkNotFound):
+		respondWithError(w, http.StatusNotFound, "Preview link not found or expired")
+	case errors.Is(err, service.ErrNotBlogOwner):
+		respondWithError(w, http.StatusForbidden, "Only the authors of the post can do that")
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// CreatePreviewLink handles POST /blogs/{id}/previews
+func (h *PreviewHandler) CreatePreviewLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, linkID, err := parsePreviewLinkPath(r.URL.Path)
+	if err != nil || linkID != 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req CreatePreviewLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	link, url, err := h.previewService.Create(userID, blogID, req.ExpiresInDays)
+	if err != nil {
+		respondWithPreviewError(w, err, "create preview link")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, CreatePreviewLinkResponse{PreviewLink: *link, URL: url})
+}
+
+// ListPreviewLinks handles GET /blogs/{id}/previews
+func (h *PreviewHandler) ListPreviewLinks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, linkID, err := parsePreviewLinkPath(r.URL.Path)
+	if err != nil || linkID != 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	links, err := h.previewService.List(userID, blogID)
+	if err != nil {
+		respondWithPreviewError(w, err, "retrieve preview links")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, links)
+}
+
+// RevokePreviewLink handles DELETE /blogs/{id}/previews/{linkId}
+func (h *PreviewHandler) RevokePreviewLink(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, linkID, err := parsePreviewLinkPath(r.URL.Path)
+	if err != nil || linkID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog or preview link ID")
+		return
+	}
+
+	if err := h.previewService.Revoke(userID, blogID, linkID); err != nil {
+		respondWithPreviewError(w, err, "revoke preview link")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Preview link revoked"})
+}
+
+// OpenPreview handles GET /preview/{token} (public, read-only)
+func (h *PreviewHandler) OpenPreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Previews are private: keep them out of caches and search engines
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	blog, err := h.previewService.Open(strings.TrimPrefix(r.URL.Path, "/preview/"))
+	if err != nil {
+		respondWithPreviewError(w, err, "open preview")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, blog)
+}
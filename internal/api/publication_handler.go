@@ -0,0 +1,366 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type PublicationHandler struct {
+	publicationService service.PublicationService
+	bookmarkService    service.BookmarkService
+	blogService        service.BlogService
+}
+
+func NewPublicationHandler(publicationService service.PublicationService, bookmarkService service.BookmarkService, blogService service.BlogService) *PublicationHandler {
+	return &PublicationHandler{
+		publicationService: publicationService,
+		bookmarkService:    bookmarkService,
+		blogService:        blogService,
+	}
+}
+
+type CreatePublicationRequest struct {
+	Name        string `json:"name"`
+	Slug        string `json:"slug"`
+	Description string `json:"description"`
+}
+
+type UpdatePublicationRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type PublicationMemberRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+}
+
+// publicationPath holds the parts of /publications/{slug}[/{section}[/{userId}]]
+type publicationPath struct {
+	Slug    string
+	Section string
+	UserID  int64
+}
+
+func parsePublicationPath(path string) (publicationPath, bool) {
+	var p publicationPath
+	parts := strings.Split(strings.TrimPrefix(path, "/publications/"), "/")
+	if parts[0] == "" || len(parts) > 3 {
+		return p, false
+	}
+	p.Slug = parts[0]
+	if len(parts) > 1 {
+		p.Section = parts[1]
+	}
+	if len(parts) == 3 {
+		var err error
+		if p.UserID, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+			return p, false
+		}
+	}
+	return p, true
+}
+
+// respondWithPublicationError maps publication service errors to responses
+func respondWithPublicationError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrPublicationNotFound):
+		respondWithError(w, http.StatusNotFound, "Publication not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		respondWithError(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrSlugTaken):
+		respondWithError(w, http.StatusConflict, "Slug already taken")
+	case errors.Is(err, service.ErrNotPublicationMember), errors.Is(err, service.ErrPublicationRole):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrLastOwner):
+		respondWithError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// parsePage reads ?limit= and ?offset=, responding with 400 when invalid
+func parsePage(w http.ResponseWriter, r *http.Request) (limit, offset int64, ok bool) {
+	limit = 10
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+	return limit, offset, true
+}
+
+// CreatePublication handles POST /publications
+func (h *PublicationHandler) CreatePublication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreatePublicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	pub, err := h.publicationService.Create(userID, req.Name, req.Slug, req.Description)
+	if err != nil {
+		respondWithPublicationError(w, err, "create publication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, pub)
+}
+
+// ListMyPublications handles GET /users/me/publications
+func (h *PublicationHandler) ListMyPublications(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	pubs, err := h.publicationService.ListMine(userID)
+	if err != nil {
+		respondWithPublicationError(w, err, "retrieve publications")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pubs)
+}
+
+// GetPublication handles GET /publications/{slug}
+func (h *PublicationHandler) GetPublication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	pub, err := h.publicationService.Get(p.Slug)
+	if err != nil {
+		respondWithPublicationError(w, err, "retrieve publication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pub)
+}
+
+// UpdatePublication handles PATCH /publications/{slug} (owners and editors)
+func (h *PublicationHandler) UpdatePublication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req UpdatePublicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	pub, err := h.publicationService.Update(userID, p.Slug, service.PublicationUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondWithPublicationError(w, err, "update publication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pub)
+}
+
+// DeletePublication handles DELETE /publications/{slug} (owners only). Its
+// posts stay with their authors.
+func (h *PublicationHandler) DeletePublication(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	if err := h.publicationService.Delete(userID, p.Slug); err != nil {
+		respondWithPublicationError(w, err, "delete publication")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Publication deleted successfully"})
+}
+
+// SetMember handles POST /publications/{slug}/members
+func (h *PublicationHandler) SetMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req PublicationMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	pub, err := h.publicationService.SetMember(userID, p.Slug, req.Username, req.Role)
+	if err != nil {
+		respondWithPublicationError(w, err, "update publication members")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, pub)
+}
+
+// RemoveMember handles DELETE /publications/{slug}/members/{userId}
+func (h *PublicationHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parsePublicationPath(r.URL.Path)
+	if !ok || p.UserID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.publicationService.RemoveMember(userID, p.Slug, p.UserID); err != nil {
+		respondWithPublicationError(w, err, "remove publication member")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
+}
+
+// ListPosts handles GET /publications/{slug}/blogs?limit=&offset=
+func (h *PublicationHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	blogs, err := h.publicationService.Posts(p.Slug, limit, offset)
+	if err != nil {
+		respondWithPublicationError(w, err, "retrieve blogs")
+		return
+	}
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
+
+// ListSubmissions handles GET /publications/{slug}/submissions (editors and
+// owners)
+func (h *PublicationHandler) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	limit, offset, ok := parsePage(w, r)
+	if !ok {
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	blogs, err := h.publicationService.Submissions(userID, p.Slug, limit, offset)
+	if err != nil {
+		respondWithPublicationError(w, err, "retrieve submissions")
+		return
+	}
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
+
+// Feed handles GET /publications/{slug}/feed (RSS 2.0)
+func (h *PublicationHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	p, _ := parsePublicationPath(r.URL.Path)
+	feed, err := h.publicationService.Feed(p.Slug)
+	if err != nil {
+		respondWithPublicationError(w, err, "render feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(feed)
+}
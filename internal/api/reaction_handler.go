@@ -0,0 +1,183 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type ReactionHandler struct {
+	reactionService service.ReactionService
+	blogService     service.BlogService
+	bookmarkService service.BookmarkService
+}
+
+func NewReactionHandler(reactionService service.ReactionService, blogService service.BlogService, bookmarkService service.BookmarkService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+		blogService:     blogService,
+		bookmarkService: bookmarkService,
+	}
+}
+
+type ReactionRequest struct {
+	Type string `json:"type"`
+}
+
+// parseReactionPath extracts the blog ID from /blogs/{id}/reactions
+func parseReactionPath(path string) (int64, error) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(path, "/blogs/"), "/reactions")
+	return strconv.ParseInt(idStr, 10, 64)
+}
+
+// ListTypes handles GET /reactions (the available reactions and their emoji)
+func (h *ReactionHandler) ListTypes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, service.ReactionTypes)
+}
+
+// GetReactions handles GET /blogs/{id}/reactions. Like every reactions
+// endpoint, it needs the access password of a password-protected post in
+// the X-Blog-Password header.
+func (h *ReactionHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	blogID, err := parseReactionPath(r.URL.Path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+	summary, err := h.reactionService.Summary(blogID, viewerID, blogPassword(r))
+	h.respondWithSummary(w, summary, err, "retrieve reactions")
+}
+
+// ToggleReaction handles POST /blogs/{id}/reactions. Sending a reaction the
+// user already left removes it.
+func (h *ReactionHandler) ToggleReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, err := parseReactionPath(r.URL.Path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req ReactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	summary, err := h.reactionService.Toggle(blogID, userID, req.Type, blogPassword(r))
+	h.respondWithSummary(w, summary, err, "update reaction")
+}
+
+// RemoveReaction handles DELETE /blogs/{id}/reactions?type=like
+func (h *ReactionHandler) RemoveReaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogID, err := parseReactionPath(r.URL.Path)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	summary, err := h.reactionService.Remove(blogID, userID, r.URL.Query().Get("type"), blogPassword(r))
+	h.respondWithSummary(w, summary, err, "update reaction")
+}
+
+func (h *ReactionHandler) respondWithSummary(w http.ResponseWriter, summary *service.ReactionSummary, err error, action string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrBlogNotFound):
+			respondWithError(w, http.StatusNotFound, "Blog not found")
+		case errors.Is(err, service.ErrBlogPasswordRequired):
+			respondWithError(w, http.StatusUnauthorized, err.Error())
+		case errors.Is(err, service.ErrTooManyPasswordTries):
+			respondWithError(w, http.StatusTooManyRequests, err.Error())
+		default:
+			log.Printf("Error trying to %s: %v", action, err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+		}
+		return
+	}
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// ListLiked handles GET /users/me/likes?limit=&offset=
+func (h *ReactionHandler) ListLiked(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var limit, offset int64 = 10, 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 1 {
+			respondWithError(w, http.StatusBadRequest, "Invalid limit parameter")
+			return
+		}
+		limit = parsed
+	}
+	if v := r.URL.Query().Get("offset"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || parsed < 0 {
+			respondWithError(w, http.StatusBadRequest, "Invalid offset parameter")
+			return
+		}
+		offset = parsed
+	}
+
+	blogs, err := h.blogService.LikedBy(userID, limit, offset)
+	if err != nil {
+		log.Printf("Error listing liked blogs: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve blogs")
+		return
+	}
+	markBookmarked(h.bookmarkService, r, blogs)
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
@@ -0,0 +1,360 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type ReadingListHandler struct {
+	readingListService service.ReadingListService
+	bookmarkService    service.BookmarkService
+	blogService        service.BlogService
+}
+
+func NewReadingListHandler(readingListService service.ReadingListService, bookmarkService service.BookmarkService, blogService service.BlogService) *ReadingListHandler {
+	return &ReadingListHandler{
+		readingListService: readingListService,
+		bookmarkService:    bookmarkService,
+		blogService:        blogService,
+	}
+}
+
+type CreateReadingListRequest struct {
+	Name     string `json:"name"`
+	IsPublic bool   `json:"is_public"`
+}
+
+type UpdateReadingListRequest struct {
+	Name     *string `json:"name"`
+	IsPublic *bool   `json:"is_public"`
+}
+
+type ReorderReadingListRequest struct {
+	BlogIDs []int64 `json:"blog_ids"`
+}
+
+// readingListPath holds the IDs in /users/me/lists/{id}[/items[/{blogId}]]
+// or /lists/{id}
+type readingListPath struct {
+	ListID int64
+	Items  bool
+	BlogID int64
+}
+
+func parseReadingListPath(path string) (readingListPath, bool) {
+	var p readingListPath
+	rest := strings.TrimPrefix(path, "/users/me")
+	parts := strings.Split(strings.TrimPrefix(rest, "/lists/"), "/")
+
+	var err error
+	if p.ListID, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return p, false
+	}
+	switch len(parts) {
+	case 1:
+		return p, true
+	case 2:
+		p.Items = parts[1] == "items"
+		return p, p.Items
+	case 3:
+		p.Items = parts[1] == "items"
+		p.BlogID, err = strconv.ParseInt(parts[2], 10, 64)
+		return p, p.Items && err == nil
+	}
+	return p, false
+}
+
+// respondWithListError maps reading list service errors to responses
+func respondWithListError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrReadingListNotFound):
+		respondWithError(w, http.StatusNotFound, "Reading list not found")
+	case errors.Is(err, service.ErrBlogNotFound):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// ListLists handles GET /users/me/lists
+func (h *ReadingListHandler) ListLists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	lists, err := h.readingListService.List(userID)
+	if err != nil {
+		respondWithListError(w, err, "retrieve reading lists")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, lists)
+}
+
+// ListPublicLists handles GET /users/{userId}/lists
+func (h *ReadingListHandler) ListPublicLists(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Extract userID from path: /users/123/lists
+	path := strings.TrimPrefix(r.URL.Path, "/users/")
+	userID, err := strconv.ParseInt(strings.Split(path, "/")[0], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	lists, err := h.readingListService.ListPublic(userID)
+	if err != nil {
+		respondWithListError(w, err, "retrieve reading lists")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, lists)
+}
+
+// CreateList handles POST /users/me/lists
+func (h *ReadingListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateReadingListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	list, err := h.readingListService.Create(userID, req.Name, req.IsPublic)
+	if err != nil {
+		respondWithListError(w, err, "create reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, list)
+}
+
+// GetList handles GET /users/me/lists/{id} and GET /lists/{id}. Private
+// lists are only returned to their owner.
+func (h *ReadingListHandler) GetList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list ID")
+		return
+	}
+	viewerID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	list, err := h.readingListService.Get(p.ListID, viewerID)
+	if err != nil {
+		respondWithListError(w, err, "retrieve reading list")
+		return
+	}
+	markBookmarked(h.bookmarkService, r, list.Items)
+	items, err := shapeBlogs(h.blogService, r, list.Items, true)
+	if err != nil {
+		respondWithShapeError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, struct {
+		*models.ReadingList
+		Items []any `json:"items"`
+	}{list.ReadingList, items})
+}
+
+// UpdateList handles PATCH /users/me/lists/{id} (rename or change visibility)
+func (h *ReadingListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list ID")
+		return
+	}
+
+	var req UpdateReadingListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	list, err := h.readingListService.Update(userID, p.ListID, service.ReadingListUpdate{
+		Name:     req.Name,
+		IsPublic: req.IsPublic,
+	})
+	if err != nil {
+		respondWithListError(w, err, "update reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, list)
+}
+
+// DeleteList handles DELETE /users/me/lists/{id}
+func (h *ReadingListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list ID")
+		return
+	}
+
+	if err := h.readingListService.Delete(userID, p.ListID); err != nil {
+		respondWithListError(w, err, "delete reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Reading list deleted successfully"})
+}
+
+// AddItem handles POST /users/me/lists/{id}/items
+func (h *ReadingListHandler) AddItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list ID")
+		return
+	}
+
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.readingListService.AddItem(userID, p.ListID, req.BlogID); err != nil {
+		respondWithListError(w, err, "add to reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]string{"message": "Blog added to reading list"})
+}
+
+// ReorderItems handles PUT /users/me/lists/{id}/items with every blog ID on
+// the list in the new order
+func (h *ReadingListHandler) ReorderItems(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list ID")
+		return
+	}
+
+	var req ReorderReadingListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.readingListService.Reorder(userID, p.ListID, req.BlogIDs); err != nil {
+		respondWithListError(w, err, "reorder reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Reading list reordered"})
+}
+
+// RemoveItem handles DELETE /users/me/lists/{id}/items/{blogId}
+func (h *ReadingListHandler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReadingListPath(r.URL.Path)
+	if !ok || p.BlogID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid reading list or blog ID")
+		return
+	}
+
+	if err := h.readingListService.RemoveItem(userID, p.ListID, p.BlogID); err != nil {
+		respondWithListError(w, err, "remove from reading list")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Blog removed from reading list"})
+}
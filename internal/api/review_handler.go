@@ -0,0 +1,375 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type ReviewHandler struct {
+	reviewService service.ReviewService
+	blogService   service.BlogService
+}
+
+func NewReviewHandler(reviewService service.ReviewService, blogService service.BlogService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+		blogService:   blogService,
+	}
+}
+
+type SubmitForReviewRequest struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+type AssignReviewerRequest struct {
+	Username string `json:"username"`
+}
+
+type ReviewDecisionRequest struct {
+	Decision string `json:"decision"`
+	Note     string `json:"note"`
+}
+
+type ReviewCommentRequest struct {
+	Body  string `json:"body"`
+	Start *int   `json:"start"`
+	End   *int   `json:"end"`
+}
+
+type ResolveReviewCommentRequest struct {
+	Resolved bool `json:"resolved"`
+}
+
+// reviewPath holds the parts of /blogs/{id}/submit, /blogs/{id}/review,
+// /blogs/{id}/review/comments[/{commentId}] and /blogs/{id}/reviewers[/{userId}].
+// Section is "submit", "review", "comments" or "reviewers".
+type reviewPath struct {
+	BlogID  int64
+	Section string
+	ItemID  int64
+}
+
+func parseReviewPath(path string) (reviewPath, bool) {
+	var p reviewPath
+	parts := strings.Split(strings.TrimPrefix(path, "/blogs/"), "/")
+	if len(parts) < 2 {
+		return p, false
+	}
+
+	var err error
+	if p.BlogID, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return p, false
+	}
+
+	rest := parts[1:]
+	if len(rest) >= 2 && rest[0] == "review" && rest[1] == "comments" {
+		p.Section, rest = "comments", rest[2:]
+	} else {
+		p.Section, rest = rest[0], rest[1:]
+	}
+	switch p.Section {
+	case "submit", "review":
+		return p, len(rest) == 0
+	case "comments", "reviewers":
+		if len(rest) == 0 {
+			return p, true
+		}
+		p.ItemID, err = strconv.ParseInt(rest[0], 10, 64)
+		return p, len(rest) == 1 && err == nil
+	}
+	return p, false
+}
+
+// respondWithReviewError maps review service errors to responses
+func respondWithReviewError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrBlogNotFound):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrUserNotFound):
+		respondWithError(w, http.StatusNotFound, "User not found")
+	case errors.Is(err, service.ErrReviewerNotFound):
+		respondWithError(w, http.StatusNotFound, "Reviewer not found")
+	case errors.Is(err, service.ErrReviewCommentNotFound):
+		respondWithError(w, http.StatusNotFound, "Review comment not found")
+	case errors.Is(err, service.ErrNotBlogOwner), errors.Is(err, service.ErrNotReviewer),
+		errors.Is(err, service.ErrReviewNotAllowed):
+		respondWithError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// Submit handles POST /blogs/{id}/submit. The body may name reviewers;
+// personal posts need at least one.
+func (h *ReviewHandler) Submit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req SubmitForReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	summary, err := h.reviewService.Submit(userID, p.BlogID, req.Reviewers)
+	if err != nil {
+		respondWithReviewError(w, err, "submit blog for review")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// GetReview handles GET /blogs/{id}/review (reviewers, comments and
+// history; authors and reviewers only)
+func (h *ReviewHandler) GetReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	summary, err := h.reviewService.Summary(userID, p.BlogID)
+	if err != nil {
+		respondWithReviewError(w, err, "retrieve review")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// Decide handles POST /blogs/{id}/review with a decision of "approve" or
+// "request_changes"
+func (h *ReviewHandler) Decide(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req ReviewDecisionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	summary, err := h.reviewService.Decide(userID, p.BlogID, req.Decision, req.Note)
+	if err != nil {
+		respondWithReviewError(w, err, "record review decision")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// AddComment handles POST /blogs/{id}/review/comments. Start and end
+// anchor the comment to a rune range of the content.
+func (h *ReviewHandler) AddComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok || p.ItemID != 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req ReviewCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.reviewService.Comment(userID, p.BlogID, service.ReviewCommentInput{
+		Body:  req.Body,
+		Start: req.Start,
+		End:   req.End,
+	})
+	if err != nil {
+		respondWithReviewError(w, err, "add review comment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, comment)
+}
+
+// ResolveComment handles PATCH /blogs/{id}/review/comments/{commentId}
+func (h *ReviewHandler) ResolveComment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok || p.ItemID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog or comment ID")
+		return
+	}
+
+	var req ResolveReviewCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	comment, err := h.reviewService.ResolveComment(userID, p.BlogID, p.ItemID, req.Resolved)
+	if err != nil {
+		respondWithReviewError(w, err, "update review comment")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, comment)
+}
+
+// AssignReviewer handles POST /blogs/{id}/reviewers (owner or publication
+// editor)
+func (h *ReviewHandler) AssignReviewer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok || p.ItemID != 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	var req AssignReviewerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	summary, err := h.reviewService.AssignReviewer(userID, p.BlogID, req.Username)
+	if err != nil {
+		respondWithReviewError(w, err, "assign reviewer")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, summary)
+}
+
+// RemoveReviewer handles DELETE /blogs/{id}/reviewers/{userId}. Reviewers
+// may remove themselves.
+func (h *ReviewHandler) RemoveReviewer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseReviewPath(r.URL.Path)
+	if !ok || p.ItemID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid blog or user ID")
+		return
+	}
+
+	if err := h.reviewService.RemoveReviewer(userID, p.BlogID, p.ItemID); err != nil {
+		respondWithReviewError(w, err, "remove reviewer")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Reviewer removed successfully"})
+}
+
+// ListAwaitingReview handles GET /users/me/reviews
+func (h *ReviewHandler) ListAwaitingReview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	blogs, err := h.reviewService.AwaitingReview(userID)
+	if err != nil {
+		respondWithReviewError(w, err, "retrieve posts awaiting review")
+		return
+	}
+	respondWithBlogs(w, r, h.blogService, blogs)
+}
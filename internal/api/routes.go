@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"strings"
 
+	"github.com/Brownie44l1/blog/internal/auth"
 	"github.com/Brownie44l1/blog/internal/middleware"
 	"github.com/Brownie44l1/blog/internal/service"
 )
@@ -10,64 +12,430 @@ import (
 func SetupRoutes(
 	userService service.UserService,
 	blogService service.BlogService,
+	tokenService service.AccessTokenService,
+	oidcService service.OIDCService,
+	sessionService service.SessionService,
+	exportService service.ExportService,
+	inviteService service.InviteService,
+	reactionService service.ReactionService,
+	bookmarkService service.BookmarkService,
+	readingListService service.ReadingListService,
+	seriesService service.SeriesService,
+	coAuthorService service.CoAuthorService,
+	publicationService service.PublicationService,
+	reviewService service.ReviewService,
+	followService service.FollowService,
+	previewService service.PreviewService,
 	jwtSecret string,
+	cookies CookieOptions,
+	allowedOrigins []string,
 ) http.Handler {
 	mux := http.NewServeMux()
 
-	authHandler := NewAuthHandler(userService, jwtSecret)
-	blogHandler := NewBlogHandler(blogService)
+	authHandler := NewAuthHandler(userService, sessionService, jwtSecret, cookies)
+	blogHandler := NewBlogHandler(blogService, bookmarkService)
 	userHandler := NewUserHandler(userService)
+	tokenHandler := NewTokenHandler(tokenService)
+	oidcHandler := NewOIDCHandler(oidcService, sessionService, jwtSecret, cookies)
+	sessionHandler := NewSessionHandler(sessionService)
+	exportHandler := NewExportHandler(exportService)
+	inviteHandler := NewInviteHandler(inviteService)
+	adminHandler := NewAdminHandler(userService)
+	reactionHandler := NewReactionHandler(reactionService, blogService, bookmarkService)
+	bookmarkHandler := NewBookmarkHandler(bookmarkService, blogService)
+	readingListHandler := NewReadingListHandler(readingListService, bookmarkService, blogService)
+	seriesHandler := NewSeriesHandler(seriesService)
+	coAuthorHandler := NewCoAuthorHandler(coAuthorService)
+	publicationHandler := NewPublicationHandler(publicationService, bookmarkService, blogService)
+	reviewHandler := NewReviewHandler(reviewService, blogService)
+	followHandler := NewFollowHandler(followService)
+	previewHandler := NewPreviewHandler(previewService)
 
-	authMiddleware := middleware.AuthMiddleware(jwtSecret)
+	authMiddleware := middleware.AuthMiddleware(jwtSecret, tokenService, sessionService)
+	// optionalAuth identifies the viewer on public routes, e.g. for the bookmarked flag
+	optionalAuth := middleware.OptionalAuth(jwtSecret, tokenService, sessionService)
+	public := func(h http.HandlerFunc) http.Handler {
+		return optionalAuth(h)
+	}
+
+	// protected authenticates the request and, for access tokens, enforces scope
+	protected := func(scope string, h http.HandlerFunc) http.Handler {
+		return authMiddleware(middleware.RequireScope(scope)(h))
+	}
 
 	// ==================== AUTH ROUTES ====================
 	// Public routes - no authentication required
 	mux.HandleFunc("/register", authHandler.Register)
 	mux.HandleFunc("/login", authHandler.Login)
+	mux.Handle("/logout", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(authHandler.Logout))))
+	mux.HandleFunc("/password/forgot", authHandler.ForgotPassword)
+	mux.HandleFunc("/password/reset", authHandler.ResetPassword)
+	mux.HandleFunc("/email/verify", userHandler.VerifyEmail)
+
+	// OpenID Connect sign-in: /auth/oidc/{provider}/login|callback|link
+	mux.HandleFunc("/auth/oidc/providers", oidcHandler.ListProviders)
+	oidcLink := authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(oidcHandler.Link)))
+	mux.HandleFunc("/auth/oidc/", func(w http.ResponseWriter, r *http.Request) {
+		switch _, action := parseOIDCPath(r.URL.Path); action {
+		case "login":
+			oidcHandler.Login(w, r)
+		case "callback":
+			oidcHandler.Callback(w, r)
+		case "link":
+			oidcLink.ServeHTTP(w, r)
+		default:
+			respondWithError(w, http.StatusNotFound, "Not found")
+		}
+	})
 
 	// ==================== USER ROUTES ====================
 	// Get authenticated user's profile (protected)
-	mux.Handle("/users/me", authMiddleware(http.HandlerFunc(userHandler.GetMe)))
+	mux.HandleFunc("/users/me", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			protected(auth.ScopeProfileWrite, userHandler.UpdateMe).ServeHTTP(w, r)
+			return
+		}
+		protected(auth.ScopeProfileRead, userHandler.GetMe).ServeHTTP(w, r)
+	})
+
+	// Change username (protected, login only)
+	mux.Handle("/users/me/username", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(userHandler.ChangeUsername))))
+
+	// Data export (protected, login only)
+	mux.Handle("/users/me/export", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			exportHandler.RequestExport(w, r)
+			return
+		}
+		exportHandler.GetExport(w, r)
+	}))))
+	mux.Handle("/users/me/export/download", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(exportHandler.DownloadMyExport))))
+	mux.HandleFunc("/exports/download", exportHandler.Download)
+
+	// Account deletion (protected, login only)
+	mux.Handle("/users/me/deletion", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			userHandler.RequestDeletion(w, r)
+		case http.MethodDelete:
+			userHandler.CancelDeletion(w, r)
+		default:
+			userHandler.GetDeletion(w, r)
+		}
+	}))))
+
+	// Change password (protected, login only)
+	mux.Handle("/users/me/password", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(authHandler.ChangePassword))))
+
+	// Email management (protected, login only)
+	mux.Handle("/users/me/email", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(userHandler.ChangeEmail))))
+	mux.Handle("/users/me/email/resend", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(userHandler.ResendVerification))))
+
+	// Signed-in sessions (protected, login only)
+	mux.Handle("/users/me/sessions", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(sessionHandler.ListSessions))))
+	mux.Handle("/users/me/sessions/", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(sessionHandler.RevokeSession))))
+
+	// Linked identity providers (protected, login only)
+	mux.Handle("/users/me/identities", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(oidcHandler.ListIdentities))))
+	mux.Handle("/users/me/identities/", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(oidcHandler.UnlinkIdentity))))
+
+	// Personal access tokens (protected, login only)
+	mux.Handle("/users/me/tokens", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			tokenHandler.ListTokens(w, r)
+		case http.MethodPost:
+			tokenHandler.CreateToken(w, r)
+		default:
+			respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}))))
+	mux.Handle("/users/me/tokens/", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(tokenHandler.RevokeToken))))
+
+	// Registration invites (protected, login only)
+	mux.Handle("/users/me/invites", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			inviteHandler.ListInvites(w, r)
+		case http.MethodPost:
+			inviteHandler.CreateInvite(w, r)
+		default:
+			respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	}))))
+	mux.Handle("/users/me/invites/", authMiddleware(middleware.DenyAccessTokens(http.HandlerFunc(inviteHandler.RevokeInvite))))
+
+	// Bookmarks (protected)
+	mux.HandleFunc("/users/me/bookmarks", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			protected(auth.ScopeBlogsWrite, bookmarkHandler.AddBookmark).ServeHTTP(w, r)
+			return
+		}
+		protected(auth.ScopeBlogsRead, bookmarkHandler.ListBookmarks).ServeHTTP(w, r)
+	})
+	mux.Handle("/users/me/bookmarks/", protected(auth.ScopeBlogsWrite, bookmarkHandler.RemoveBookmark))
+
+	// Reading lists (protected): /users/me/lists/{id}[/items[/{blogId}]]
+	mux.HandleFunc("/users/me/lists", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			protected(auth.ScopeBlogsWrite, readingListHandler.CreateList).ServeHTTP(w, r)
+			return
+		}
+		protected(auth.ScopeBlogsRead, readingListHandler.ListLists).ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/users/me/lists/", func(w http.ResponseWriter, r *http.Request) {
+		p, _ := parseReadingListPath(r.URL.Path)
+		switch {
+		case p.BlogID != 0:
+			protected(auth.ScopeBlogsWrite, readingListHandler.RemoveItem).ServeHTTP(w, r)
+		case p.Items && r.Method == http.MethodPut:
+			protected(auth.ScopeBlogsWrite, readingListHandler.ReorderItems).ServeHTTP(w, r)
+		case p.Items:
+			protected(auth.ScopeBlogsWrite, readingListHandler.AddItem).ServeHTTP(w, r)
+		case r.Method == http.MethodGet:
+			protected(auth.ScopeBlogsRead, readingListHandler.GetList).ServeHTTP(w, r)
+		case r.Method == http.MethodPatch:
+			protected(auth.ScopeBlogsWrite, readingListHandler.UpdateList).ServeHTTP(w, r)
+		default:
+			protected(auth.ScopeBlogsWrite, readingListHandler.DeleteList).ServeHTTP(w, r)
+		}
+	})
+
+	// Public reading lists (public; owners also see their private lists)
+	mux.Handle("/lists/", public(readingListHandler.GetList))
+
+	// Co-author invites addressed to the authenticated user (protected)
+	mux.Handle("/users/me/coauthor-invites", protected(auth.ScopeBlogsRead, coAuthorHandler.ListInvites))
+	mux.Handle("/users/me/coauthor-invites/", protected(auth.ScopeBlogsWrite, coAuthorHandler.RespondToInvite))
+
+	// Publications the authenticated user belongs to (protected)
+	mux.Handle("/users/me/publications", protected(auth.ScopeBlogsRead, publicationHandler.ListMyPublications))
+
+	// Users the authenticated user follows (protected)
+	mux.Handle("/users/me/following", protected(auth.ScopeProfileRead, followHandler.ListFollowing))
+
+	// Submitted posts the authenticated user is asked to review (protected)
+	mux.Handle("/users/me/reviews", protected(auth.ScopeBlogsRead, reviewHandler.ListAwaitingReview))
+
+	// The authenticated user's series (protected)
+	mux.Handle("/users/me/series", protected(auth.ScopeBlogsRead, seriesHandler.ListMySeries))
+
+	// Look up a profile by @handle (public)
+	mux.HandleFunc("/users/by-username/", userHandler.GetProfileByUsername)
 
 	// Get any user's profile (public)
+	getUserBlogs := public(blogHandler.GetUserBlogs)
 	mux.HandleFunc("/users/", func(w http.ResponseWriter, r *http.Request) {
 		// Check if it's a user blog request: /users/{id}/blogs
 		if len(r.URL.Path) > 7 && r.URL.Path[len(r.URL.Path)-6:] == "/blogs" {
-			blogHandler.GetUserBlogs(w, r)
+			getUserBlogs.ServeHTTP(w, r)
+			return
+		}
+		// Follow a user: /users/{id}/follow (protected)
+		if strings.HasSuffix(r.URL.Path, "/follow") {
+			protected(auth.ScopeProfileWrite, followHandler.Follow).ServeHTTP(w, r)
+			return
+		}
+		// Series by a user: /users/{id}/series
+		if strings.HasSuffix(r.URL.Path, "/series") {
+			seriesHandler.ListUserSeries(w, r)
+			return
+		}
+		// Public reading lists: /users/{id}/lists
+		if strings.HasSuffix(r.URL.Path, "/lists") {
+			readingListHandler.ListPublicLists(w, r)
 			return
 		}
 		// Otherwise, it's a user profile request: /users/{id}
 		userHandler.GetProfile(w, r)
 	})
 
+	// ==================== ADMIN ROUTES ====================
+	admin := func(h http.HandlerFunc) http.Handler {
+		return authMiddleware(middleware.DenyAccessTokens(middleware.RequireAdmin(userService)(h)))
+	}
+	mux.Handle("/admin/registrations", admin(adminHandler.ListRegistrations))
+	mux.Handle("/admin/registrations/", admin(adminHandler.ReviewRegistration))
+
 	// ==================== BLOG ROUTES ====================
 	// Create blog (protected)
-	mux.Handle("/blogs/create", authMiddleware(http.HandlerFunc(blogHandler.CreateBlog)))
+	canPost := middleware.RequireCanPost(userService)
+	mux.Handle("/blogs/create", protected(auth.ScopeBlogsWrite, canPost(http.HandlerFunc(blogHandler.CreateBlog)).ServeHTTP))
 
 	// Get authenticated user's blogs (protected)
-	mux.Handle("/blogs/me", authMiddleware(http.HandlerFunc(blogHandler.GetMyBlogs)))
+	mux.Handle("/blogs/me", protected(auth.ScopeBlogsRead, blogHandler.GetMyBlogs))
+
+	// Trash (protected): /blogs/trash[/{id}[/restore]]
+	mux.Handle("/blogs/trash", protected(auth.ScopeBlogsRead, blogHandler.GetTrash))
+	mux.Handle("/blogs/trash/", protected(auth.ScopeBlogsWrite, blogHandler.TrashItem))
+
+	// Series (create protected, read public): /series/{id}[/posts[/{blogId}]]
+	mux.Handle("/series", protected(auth.ScopeBlogsWrite, canPost(http.HandlerFunc(seriesHandler.CreateSeries)).ServeHTTP))
+	mux.HandleFunc("/series/", func(w http.ResponseWriter, r *http.Request) {
+		p, _ := parseSeriesPath(r.URL.Path)
+		switch {
+		case p.BlogID != 0:
+			protected(auth.ScopeBlogsWrite, seriesHandler.RemovePost).ServeHTTP(w, r)
+		case p.Posts && r.Method == http.MethodPut:
+			protected(auth.ScopeBlogsWrite, seriesHandler.ReorderPosts).ServeHTTP(w, r)
+		case p.Posts:
+			protected(auth.ScopeBlogsWrite, seriesHandler.AddPost).ServeHTTP(w, r)
+		case r.Method == http.MethodGet:
+			seriesHandler.GetSeries(w, r)
+		case r.Method == http.MethodPatch:
+			protected(auth.ScopeBlogsWrite, seriesHandler.UpdateSeries).ServeHTTP(w, r)
+		default:
+			protected(auth.ScopeBlogsWrite, seriesHandler.DeleteSeries).ServeHTTP(w, r)
+		}
+	})
+
+	// Publications (create protected, read public):
+	// /publications/{slug}[/blogs|/feed|/submissions|/members[/{userId}]]
+	mux.Handle("/publications", protected(auth.ScopeBlogsWrite, canPost(http.HandlerFunc(publicationHandler.CreatePublication)).ServeHTTP))
+	mux.HandleFunc("/publications/", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := parsePublicationPath(r.URL.Path)
+		if !ok {
+			respondWithError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		switch p.Section {
+		case "":
+			switch r.Method {
+			case http.MethodGet:
+				publicationHandler.GetPublication(w, r)
+			case http.MethodPatch:
+				protected(auth.ScopeBlogsWrite, publicationHandler.UpdatePublication).ServeHTTP(w, r)
+			default:
+				protected(auth.ScopeBlogsWrite, publicationHandler.DeletePublication).ServeHTTP(w, r)
+			}
+		case "blogs":
+			public(publicationHandler.ListPosts).ServeHTTP(w, r)
+		case "feed":
+			publicationHandler.Feed(w, r)
+		case "submissions":
+			protected(auth.ScopeBlogsRead, publicationHandler.ListSubmissions).ServeHTTP(w, r)
+		case "members":
+			if r.Method == http.MethodPost {
+				protected(auth.ScopeBlogsWrite, publicationHandler.SetMember).ServeHTTP(w, r)
+				return
+			}
+			protected(auth.ScopeBlogsWrite, publicationHandler.RemoveMember).ServeHTTP(w, r)
+		default:
+			respondWithError(w, http.StatusNotFound, "Not found")
+		}
+	})
 
 	// Search blogs (public)
-	mux.HandleFunc("/blogs/search", blogHandler.SearchBlogs)
+	mux.Handle("/blogs/search", public(blogHandler.SearchBlogs))
+
+	// Posts the authenticated user liked (protected)
+	mux.Handle("/users/me/likes", protected(auth.ScopeBlogsRead, reactionHandler.ListLiked))
+
+	// Available reaction types (public)
+	mux.HandleFunc("/reactions", reactionHandler.ListTypes)
 
 	// Blog operations by ID
 	mux.HandleFunc("/blogs/", func(w http.ResponseWriter, r *http.Request) {
+		// Reactions: /blogs/{id}/reactions
+		if strings.HasSuffix(r.URL.Path, "/reactions") {
+			switch r.Method {
+			case http.MethodGet:
+				public(reactionHandler.GetReactions).ServeHTTP(w, r)
+			case http.MethodPost:
+				protected(auth.ScopeBlogsWrite, reactionHandler.ToggleReaction).ServeHTTP(w, r)
+			case http.MethodDelete:
+				protected(auth.ScopeBlogsWrite, reactionHandler.RemoveReaction).ServeHTTP(w, r)
+			default:
+				respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+			}
+			return
+		}
+
+		// Publishing workflow: /blogs/{id}/publish|unpublish
+		if strings.HasSuffix(r.URL.Path, "/publish") || strings.HasSuffix(r.URL.Path, "/unpublish") {
+			protected(auth.ScopeBlogsWrite, blogHandler.ChangeStatus).ServeHTTP(w, r)
+			return
+		}
+
+		// Preview links: /blogs/{id}/previews[/{linkId}]
+		if strings.Contains(r.URL.Path, "/previews") {
+			switch r.Method {
+			case http.MethodGet:
+				protected(auth.ScopeBlogsRead, previewHandler.ListPreviewLinks).ServeHTTP(w, r)
+			case http.MethodPost:
+				protected(auth.ScopeBlogsWrite, previewHandler.CreatePreviewLink).ServeHTTP(w, r)
+			default:
+				protected(auth.ScopeBlogsWrite, previewHandler.RevokePreviewLink).ServeHTTP(w, r)
+			}
+			return
+		}
+
+		// Related posts: /blogs/{id}/related
+		if strings.HasSuffix(r.URL.Path, "/related") {
+			public(blogHandler.GetRelatedBlogs).ServeHTTP(w, r)
+			return
+		}
+
+		// Visibility: /blogs/{id}/visibility
+		if strings.HasSuffix(r.URL.Path, "/visibility") {
+			protected(auth.ScopeBlogsWrite, blogHandler.SetVisibility).ServeHTTP(w, r)
+			return
+		}
+
+		// Editorial review: /blogs/{id}/submit, /blogs/{id}/review[/comments[/{commentId}]]
+		// and /blogs/{id}/reviewers[/{userId}]
+		if p, ok := parseReviewPath(r.URL.Path); ok {
+			switch {
+			case p.Section == "submit":
+				protected(auth.ScopeBlogsWrite, reviewHandler.Submit).ServeHTTP(w, r)
+			case p.Section == "review" && r.Method == http.MethodGet:
+				protected(auth.ScopeBlogsRead, reviewHandler.GetReview).ServeHTTP(w, r)
+			case p.Section == "review":
+				protected(auth.ScopeBlogsWrite, reviewHandler.Decide).ServeHTTP(w, r)
+			case p.Section == "comments" && p.ItemID != 0:
+				protected(auth.ScopeBlogsWrite, reviewHandler.ResolveComment).ServeHTTP(w, r)
+			case p.Section == "comments":
+				protected(auth.ScopeBlogsWrite, reviewHandler.AddComment).ServeHTTP(w, r)
+			case p.ItemID != 0:
+				protected(auth.ScopeBlogsWrite, reviewHandler.RemoveReviewer).ServeHTTP(w, r)
+			default:
+				protected(auth.ScopeBlogsWrite, reviewHandler.AssignReviewer).ServeHTTP(w, r)
+			}
+			return
+		}
+
+		// Co-authors: /blogs/{id}/authors[/{userId}]
+		if strings.Contains(r.URL.Path, "/authors") {
+			switch r.Method {
+			case http.MethodGet:
+				public(coAuthorHandler.ListCoAuthors).ServeHTTP(w, r)
+			case http.MethodPost:
+				protected(auth.ScopeBlogsWrite, coAuthorHandler.InviteCoAuthor).ServeHTTP(w, r)
+			default:
+				protected(auth.ScopeBlogsWrite, coAuthorHandler.RemoveCoAuthor).ServeHTTP(w, r)
+			}
+			return
+		}
+
 		switch r.Method {
 		case http.MethodGet:
 			// Public: anyone can view a blog
-			blogHandler.GetBlog(w, r)
+			public(blogHandler.GetBlog).ServeHTTP(w, r)
 		case http.MethodDelete:
-			// Protected: only owner can delete
-			authMiddleware(http.HandlerFunc(blogHandler.DeleteBlog)).ServeHTTP(w, r)
+			// Protected: only owner can delete (to the trash)
+			protected(auth.ScopeBlogsWrite, blogHandler.DeleteBlog).ServeHTTP(w, r)
 		case http.MethodPut:
-			authMiddleware(http.HandlerFunc(blogHandler.UpdateBlog)).ServeHTTP(w, r)
+			protected(auth.ScopeBlogsWrite, blogHandler.UpdateBlog).ServeHTTP(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
 	})
 
+	// Read-only previews through secret links (public)
+	mux.HandleFunc("/preview/", previewHandler.OpenPreview)
+
 	// List all blogs with pagination (public)
-	mux.HandleFunc("/blogs", blogHandler.ListBlogs)
+	mux.Handle("/blogs", public(blogHandler.ListBlogs))
 
-	return middleware.CORS(middleware.PerformanceMiddleware(mux))
+	return middleware.CORS(allowedOrigins)(middleware.PerformanceMiddleware(mux))
 }
\ No newline at end of file
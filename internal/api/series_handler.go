@@ -0,0 +1,352 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type SeriesHandler struct {
+	seriesService service.SeriesService
+}
+
+func NewSeriesHandler(seriesService service.SeriesService) *SeriesHandler {
+	return &SeriesHandler{
+		seriesService: seriesService,
+	}
+}
+
+type CreateSeriesRequest struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type UpdateSeriesRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+}
+
+type ReorderSeriesRequest struct {
+	BlogIDs []int64 `json:"blog_ids"`
+}
+
+// seriesPath holds the IDs in /series/{id}[/posts[/{blogId}]]
+type seriesPath struct {
+	SeriesID int64
+	Posts    bool
+	BlogID   int64
+}
+
+func parseSeriesPath(path string) (seriesPath, bool) {
+	var p seriesPath
+	parts := strings.Split(strings.TrimPrefix(path, "/series/"), "/")
+
+	var err error
+	if p.SeriesID, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+		return p, false
+	}
+	switch len(parts) {
+	case 1:
+		return p, true
+	case 2:
+		p.Posts = parts[1] == "posts"
+		return p, p.Posts
+	case 3:
+		p.Posts = parts[1] == "posts"
+		p.BlogID, err = strconv.ParseInt(parts[2], 10, 64)
+		return p, p.Posts && err == nil
+	}
+	return p, false
+}
+
+// respondWithSeriesError maps series service errors to responses
+func respondWithSeriesError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrSeriesNotFound):
+		respondWithError(w, http.StatusNotFound, "Series not found")
+	case errors.Is(err, service.ErrBlogNotFound):
+		respondWithError(w, http.StatusNotFound, "Blog not found")
+	case errors.Is(err, service.ErrInvalidInput):
+		respondWithError(w, http.StatusBadRequest, err.Error())
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to "+action)
+	}
+}
+
+// CreateSeries handles POST /series
+func (h *SeriesHandler) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	series, err := h.seriesService.Create(userID, req.Title, req.Description)
+	if err != nil {
+		respondWithSeriesError(w, err, "create series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, series)
+}
+
+// GetSeries handles GET /series/{id} (the series and its table of contents)
+func (h *SeriesHandler) GetSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	series, err := h.seriesService.Get(p.SeriesID)
+	if err != nil {
+		respondWithSeriesError(w, err, "retrieve series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// ListMySeries handles GET /users/me/series
+func (h *SeriesHandler) ListMySeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	series, err := h.seriesService.ListMine(userID)
+	if err != nil {
+		respondWithSeriesError(w, err, "retrieve series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// ListUserSeries handles GET /users/{userId}/series
+func (h *SeriesHandler) ListUserSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// Extract userID from path: /users/123/series
+	path := strings.TrimPrefix(r.URL.Path, "/users/")
+	userID, err := strconv.ParseInt(strings.Split(path, "/")[0], 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	series, err := h.seriesService.ListByUser(userID)
+	if err != nil {
+		respondWithSeriesError(w, err, "retrieve series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// UpdateSeries handles PATCH /series/{id}
+func (h *SeriesHandler) UpdateSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	var req UpdateSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	series, err := h.seriesService.Update(userID, p.SeriesID, service.SeriesUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondWithSeriesError(w, err, "update series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// DeleteSeries handles DELETE /series/{id}. The posts themselves are kept.
+func (h *SeriesHandler) DeleteSeries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	if err := h.seriesService.Delete(userID, p.SeriesID); err != nil {
+		respondWithSeriesError(w, err, "delete series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Series deleted successfully"})
+}
+
+// AddPost handles POST /series/{id}/posts
+func (h *SeriesHandler) AddPost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.seriesService.AddPost(userID, p.SeriesID, req.BlogID); err != nil {
+		respondWithSeriesError(w, err, "add post to series")
+		return
+	}
+
+	series, err := h.seriesService.Get(p.SeriesID)
+	if err != nil {
+		respondWithSeriesError(w, err, "retrieve series")
+		return
+	}
+	respondWithJSON(w, http.StatusCreated, series)
+}
+
+// ReorderPosts handles PUT /series/{id}/posts with every blog ID in the
+// series in the new order
+func (h *SeriesHandler) ReorderPosts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok {
+		respondWithError(w, http.StatusBadRequest, "Invalid series ID")
+		return
+	}
+
+	var req ReorderSeriesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.seriesService.Reorder(userID, p.SeriesID, req.BlogIDs); err != nil {
+		respondWithSeriesError(w, err, "reorder series")
+		return
+	}
+
+	series, err := h.seriesService.Get(p.SeriesID)
+	if err != nil {
+		respondWithSeriesError(w, err, "retrieve series")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// RemovePost handles DELETE /series/{id}/posts/{blogId}
+func (h *SeriesHandler) RemovePost(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	p, ok := parseSeriesPath(r.URL.Path)
+	if !ok || p.BlogID == 0 {
+		respondWithError(w, http.StatusBadRequest, "Invalid series or blog ID")
+		return
+	}
+
+	if err := h.seriesService.RemovePost(userID, p.SeriesID, p.BlogID); err != nil {
+		respondWithSeriesError(w, err, "remove post from series")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Post removed from series"})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/middleware"
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/service"
+)
+
+type TokenHandler struct {
+	tokenService service.AccessTokenService
+}
+
+func NewTokenHandler(tokenService service.AccessTokenService) *TokenHandler {
+	return &TokenHandler{tokenService: tokenService}
+}
+
+type CreateTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type CreateTokenResponse struct {
+	models.PersonalAccessToken
+	Token string `json:"token"`
+}
+
+// CreateToken handles POST /users/me/tokens
+func (h *TokenHandler) CreateToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req CreateTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	token, raw, err := h.tokenService.Create(userID, req.Name, req.Scopes, req.ExpiresInDays)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error creating access token: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to create token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, CreateTokenResponse{PersonalAccessToken: *token, Token: raw})
+}
+
+// ListTokens handles GET /users/me/tokens
+func (h *TokenHandler) ListTokens(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := h.tokenService.List(userID)
+	if err != nil {
+		log.Printf("Error listing access tokens: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve tokens")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tokens)
+}
+
+// RevokeToken handles DELETE /users/me/tokens/{id}
+func (h *TokenHandler) RevokeToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Extract ID from path: /users/me/tokens/123
+	idStr := strings.TrimPrefix(r.URL.Path, "/users/me/tokens/")
+	tokenID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid token ID")
+		return
+	}
+
+	if err := h.tokenService.Revoke(userID, tokenID); err != nil {
+		if errors.Is(err, service.ErrTokenNotFound) {
+			respondWithError(w, http.StatusNotFound, "Token not found")
+			return
+		}
+		log.Printf("Error revoking access token: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to revoke token")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Token revoked successfully"})
+}
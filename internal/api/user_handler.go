@@ -1,8 +1,11 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -55,6 +58,40 @@ func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, profile)
 }
 
+// GetProfileByUsername handles GET /users/by-username/{name}. A leading "@"
+// is ignored, and a recently changed username redirects to the current one.
+func (h *UserHandler) GetProfileByUsername(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/users/by-username/")
+	name = strings.TrimPrefix(name, "@")
+	if name == "" || strings.Contains(name, "/") {
+		respondWithError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	profile, err := h.userService.GetUserProfileByUsername(name)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			respondWithError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Error retrieving user profile: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
+		return
+	}
+
+	if !strings.EqualFold(profile.Username, name) {
+		http.Redirect(w, r, "/users/by-username/"+url.PathEscape(profile.Username), http.StatusMovedPermanently)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
 // GetMe handles GET /users/me (authenticated user's profile)
 func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -69,7 +106,7 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	profile, err := h.userService.GetUserProfile(userID)
+	profile, err := h.userService.GetMyProfile(userID)
 	if err != nil {
 		log.Printf("Error retrieving user profile: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve user profile")
@@ -78,3 +115,298 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	respondWithJSON(w, http.StatusOK, profile)
 }
+
+// UpdateMe handles PATCH /users/me. Only the fields present in the body are changed.
+func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req service.ProfileUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	profile, err := h.userService.UpdateProfile(userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error updating profile: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to update profile")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+type ChangeUsernameRequest struct {
+	Username string `json:"username"`
+}
+
+// ChangeUsername handles PUT /users/me/username
+func (h *UserHandler) ChangeUsername(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangeUsernameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	profile, err := h.userService.ChangeUsername(userID, req.Username)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrUsernameTaken):
+			respondWithError(w, http.StatusConflict, "Username already taken")
+		case errors.Is(err, service.ErrUsernameChangeTooSoon):
+			respondWithError(w, http.StatusTooManyRequests, err.Error())
+		default:
+			log.Printf("Error changing username: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to change username")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, profile)
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail handles POST /email/verify. The token may be given in the body
+// or as the ?token= query parameter. GET serves the page the emailed link
+// opens, which POSTs the token here once the user confirms.
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		respondWithLinkPage(w, verifyEmailPage)
+		return
+	}
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req VerifyEmailRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+	if req.Token == "" {
+		req.Token = r.URL.Query().Get("token")
+	}
+
+	if err := h.userService.VerifyEmail(req.Token); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			respondWithError(w, http.StatusBadRequest, "Invalid or expired verification token")
+		case errors.Is(err, service.ErrEmailTaken):
+			respondWithError(w, http.StatusConflict, "Email already in use")
+		default:
+			log.Printf("Error verifying email: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to verify email")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Email address verified"})
+}
+
+// ResendVerification handles POST /users/me/email/resend
+func (h *UserHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.ResendVerification(userID); err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("Error resending verification email: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to send verification email")
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Verification email sent"})
+}
+
+// ChangeEmail handles PUT /users/me/email. The new address only replaces the
+// current one once it has been verified.
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.userService.ChangeEmail(userID, req.Password, req.Email); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			respondWithError(w, http.StatusUnauthorized, "Password is incorrect")
+		case errors.Is(err, service.ErrEmailTaken):
+			respondWithError(w, http.StatusConflict, "Email already in use")
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		default:
+			log.Printf("Error changing email: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to change email")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"message": "Verification email sent to the new address"})
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	// Mode is "delete_posts" (default) or "anonymize" to keep posts under
+	// the "deleted" placeholder user.
+	Mode string `json:"mode"`
+}
+
+// RequestDeletion handles POST /users/me/deletion. Other sessions are
+// signed out and personal access tokens deleted.
+func (h *UserHandler) RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		return
+	}
+
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+	status, err := h.userService.RequestDeletion(userID, sessionID, req.Password, req.Mode)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidInput):
+			respondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, service.ErrInvalidCredentials):
+			respondWithError(w, http.StatusUnauthorized, "Password is incorrect")
+		case errors.Is(err, service.ErrDeletionPending):
+			respondWithError(w, http.StatusConflict, "Account deletion is already scheduled")
+		default:
+			log.Printf("Error requesting account deletion: %v", err)
+			respondWithError(w, http.StatusInternalServerError, "Failed to schedule account deletion")
+		}
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, status)
+}
+
+// GetDeletion handles GET /users/me/deletion
+func (h *UserHandler) GetDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.userService.GetDeletionStatus(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrDeletionNotRequested) {
+			respondWithError(w, http.StatusNotFound, "Account deletion has not been requested")
+			return
+		}
+		log.Printf("Error retrieving deletion status: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to retrieve deletion status")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, status)
+}
+
+// CancelDeletion handles DELETE /users/me/deletion
+func (h *UserHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		log.Println("❌ Failed to get user ID from context")
+		respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.userService.CancelDeletion(userID); err != nil {
+		if errors.Is(err, service.ErrDeletionNotRequested) {
+			respondWithError(w, http.StatusNotFound, "Account deletion has not been requested")
+			return
+		}
+		log.Printf("Error cancelling account deletion: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to cancel account deletion")
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"message": "Account deletion cancelled"})
+}
@@ -9,16 +9,23 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenTTL is how long an issued JWT stays valid.
+const TokenTTL = 24 * time.Hour
+
 type Claims struct {
-	UserID int64 `json:"user_id"`
+	UserID    int64 `json:"user_id"`
+	SessionID int64 `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int64, secret string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateToken issues a token bound to a session; revoking the session
+// invalidates the token.
+func GenerateToken(userID, sessionID int64, secret string) (string, error) {
+	expirationTime := time.Now().Add(TokenTTL)
 
 	claims := Claims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -38,14 +45,9 @@ func GenerateToken(userID int64, secret string) (string, error) {
 }
 
 func ValidateToken(tokenString, secret string) (*Claims, error) {
-	log.Printf("🔍 ValidateToken called")
-	log.Printf("🔍 Token length: %d", len(tokenString))
-	log.Printf("🔍 Secret length: %d", len(secret))
-	
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (any, error) {
-		log.Printf("🔍 Parsing token with method: %v", token.Method)
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			log.Printf("❌ Unexpected signing method: %v", token.Header["alg"])
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -71,7 +73,6 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 		log.Printf("❌ Token is not valid")
 		return nil, errors.New("invalid token")
 	}
-	
-	log.Printf("✅ Token validated successfully for user_id: %d", claims.UserID)
+
 	return claims, nil
 }
@@ -1,16 +1,139 @@
 package auth
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Argon2Params are the argon2id cost parameters used for new hashes.
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follow the OWASP baseline recommendation.
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+var (
+	paramsMu sync.RWMutex
+	params   = DefaultArgon2Params
+)
+
+var errInvalidHash = errors.New("invalid argon2id hash")
+
+// SetArgon2Params changes the parameters used by HashPassword. Existing
+// hashes with weaker parameters are reported by NeedsRehash.
+func SetArgon2Params(p Argon2Params) {
+	paramsMu.Lock()
+	defer paramsMu.Unlock()
+	params = p
+}
+
+func currentParams() Argon2Params {
+	paramsMu.RLock()
+	defer paramsMu.RUnlock()
+	return params
+}
+
+// HashPassword hashes a password with argon2id and returns it in PHC string
+// format: $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
+	p := currentParams()
+
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
 		return "", err
 	}
-	return string(bytes), nil
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
 }
 
+// VerifyPassword checks a password against an argon2id or legacy bcrypt hash.
 func VerifyPassword(hashedPassword, password string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		p, salt, key, err := decodeArgon2Hash(hashedPassword)
+		if err != nil {
+			return false
+		}
+		other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(key, other) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
-	return err == nil 
-}
\ No newline at end of file
+	return err == nil
+}
+
+// NeedsRehash reports whether a hash uses an older algorithm or weaker
+// parameters than the current ones and should be replaced on next login.
+func NeedsRehash(hashedPassword string) bool {
+	if !strings.HasPrefix(hashedPassword, "$argon2id$") {
+		return true
+	}
+
+	p, salt, key, err := decodeArgon2Hash(hashedPassword)
+	if err != nil {
+		return true
+	}
+
+	cur := currentParams()
+	return p.Memory < cur.Memory ||
+		p.Iterations < cur.Iterations ||
+		p.Parallelism < cur.Parallelism ||
+		uint32(len(salt)) < cur.SaltLength ||
+		uint32(len(key)) < cur.KeyLength
+}
+
+func decodeArgon2Hash(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, errInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, errInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, errInvalidHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	return p, salt, key, nil
+}
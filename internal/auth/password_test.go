@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheapParams keep the tests fast; the format and checks don't depend on cost.
+var cheapParams = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func withParams(t *testing.T, p Argon2Params) {
+	t.Helper()
+	old := currentParams()
+	SetArgon2Params(p)
+	t.Cleanup(func() { SetArgon2Params(old) })
+}
+
+func TestHashPasswordVerifies(t *testing.T) {
+	withParams(t, cheapParams)
+
+	hash, err := HashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Errorf("unexpected hash format %q", hash)
+	}
+	if !VerifyPassword(hash, "correct horse battery staple") {
+		t.Error("VerifyPassword rejected the right password")
+	}
+	if VerifyPassword(hash, "correct horse battery stapler") {
+		t.Error("VerifyPassword accepted a wrong password")
+	}
+}
+
+func TestHashPasswordSaltsEachHash(t *testing.T) {
+	withParams(t, cheapParams)
+
+	a, _ := HashPassword("same password")
+	b, _ := HashPassword("same password")
+	if a == b {
+		t.Error("two hashes of the same password are identical")
+	}
+}
+
+func TestVerifyPasswordLegacyBcrypt(t *testing.T) {
+	withParams(t, cheapParams)
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("old password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !VerifyPassword(string(legacy), "old password") {
+		t.Error("VerifyPassword rejected a bcrypt hash of the right password")
+	}
+	if VerifyPassword(string(legacy), "new password") {
+		t.Error("VerifyPassword accepted a wrong password for a bcrypt hash")
+	}
+	if !NeedsRehash(string(legacy)) {
+		t.Error("bcrypt hashes should need a rehash")
+	}
+}
+
+func TestVerifyPasswordMalformed(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"$argon2id$",
+		"$argon2id$v=19$m=1024,t=1,p=1$salt",
+		"$argon2id$v=18$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5",
+		"$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5",
+	} {
+		if VerifyPassword(hash, "password") {
+			t.Errorf("VerifyPassword(%q) = true", hash)
+		}
+		if !NeedsRehash(hash) {
+			t.Errorf("NeedsRehash(%q) = false", hash)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	withParams(t, cheapParams)
+	hash, err := HashPassword("password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if NeedsRehash(hash) {
+		t.Error("a hash with the current parameters needs a rehash")
+	}
+
+	tests := []struct {
+		name   string
+		change func(p *Argon2Params)
+	}{
+		{"memory", func(p *Argon2Params) { p.Memory *= 2 }},
+		{"iterations", func(p *Argon2Params) { p.Iterations++ }},
+		{"parallelism", func(p *Argon2Params) { p.Parallelism++ }},
+		{"salt length", func(p *Argon2Params) { p.SaltLength *= 2 }},
+		{"key length", func(p *Argon2Params) { p.KeyLength *= 2 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stronger := cheapParams
+			tt.change(&stronger)
+			withParams(t, stronger)
+			if !NeedsRehash(hash) {
+				t.Errorf("raising %s should require a rehash", tt.name)
+			}
+		})
+	}
+
+	// Lowering the cost must not downgrade existing hashes
+	weaker := cheapParams
+	weaker.Iterations = 1
+	weaker.Memory = 512
+	withParams(t, weaker)
+	if NeedsRehash(hash) {
+		t.Error("lowering the parameters should not require a rehash")
+	}
+}
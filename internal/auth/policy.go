@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/bloom"
+)
+
+// maxPasswordLength bounds the work done hashing a password.
+const maxPasswordLength = 128
+
+// breachedFalsePositiveRate is the chance an unbreached password is rejected.
+const breachedFalsePositiveRate = 0.001
+
+// PasswordPolicy describes the passwords users may choose.
+type PasswordPolicy struct {
+	MinLength int
+	// MinEntropyBits is the minimum estimated strength, see EstimateEntropy
+	MinEntropyBits float64
+	// Breached, when set, holds known-compromised passwords
+	Breached *bloom.Filter
+}
+
+// Check returns a user-facing reason the password is not acceptable, or ""
+// if it is. personal are values such as the username that must not appear
+// in the password.
+func (p PasswordPolicy) Check(password string, personal ...string) string {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Sprintf("must be at least %d characters", p.MinLength)
+	}
+	if length > maxPasswordLength {
+		return fmt.Sprintf("cannot exceed %d characters", maxPasswordLength)
+	}
+
+	lower := strings.ToLower(password)
+	for _, v := range personal {
+		v = strings.ToLower(strings.TrimSpace(v))
+		if len(v) >= 3 && strings.Contains(lower, v) {
+			return "must not contain your username or email"
+		}
+	}
+
+	if p.Breached != nil && p.Breached.Contains([]byte(breachedKey(password))) {
+		return "appears in a list of breached passwords, please choose another"
+	}
+
+	if EstimateEntropy(password) < p.MinEntropyBits {
+		return "is too easy to guess, use a longer password or mix in other kinds of characters"
+	}
+	return ""
+}
+
+// EstimateEntropy gives a rough strength estimate in bits: the size of the
+// character classes used, raised to the password length. Repeated and
+// sequential characters (aaa, abc, 123) only count for half.
+func EstimateEntropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	var effective float64
+	var prev rune
+	for i, r := range password {
+		switch {
+		case r < unicode.MaxASCII && unicode.IsLower(r):
+			lower = true
+		case r < unicode.MaxASCII && unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+
+		if i > 0 && (r == prev || r == prev+1 || r == prev-1) {
+			effective += 0.5
+		} else {
+			effective++
+		}
+		prev = r
+	}
+
+	pool := 0
+	if lower {
+		pool += 26
+	}
+	if upper {
+		pool += 26
+	}
+	if digit {
+		pool += 10
+	}
+	if symbol {
+		pool += 33
+	}
+	if other {
+		pool += 100
+	}
+	if pool == 0 {
+		return 0
+	}
+	return effective * math.Log2(float64(pool))
+}
+
+// breachedKey is the form stored in the breached-password filter: the
+// uppercase hex SHA-1, as used by the Have I Been Pwned password lists.
+func breachedKey(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// LoadBreachedPasswords builds a Bloom filter from a file with one entry per
+// line. Entries are plaintext passwords or SHA-1 hashes in the
+// "HASH" or "HASH:COUNT" format of the Have I Been Pwned downloads.
+func LoadBreachedPasswords(path string) (*bloom.Filter, int, error) {
+	count, err := countLines(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	filter := bloom.New(count, breachedFalsePositiveRate)
+	added := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			filter.Add([]byte(strings.ToUpper(hash)))
+		} else {
+			filter.Add([]byte(breachedKey(line)))
+		}
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return filter, added, nil
+}
+
+func countLines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		n++
+	}
+	return n, scanner.Err()
+}
+
+func isSHA1Hex(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/bloom"
+)
+
+func TestPasswordPolicyCheck(t *testing.T) {
+	breached := bloom.New(10, 0.001)
+	breached.Add([]byte(breachedKey("Tr0ub4dor&3xyz")))
+
+	policy := PasswordPolicy{MinLength: 8, MinEntropyBits: 35, Breached: breached}
+
+	tests := []struct {
+		name     string
+		password string
+		personal []string
+		wantErr  string // substring of the reason, "" when acceptable
+	}{
+		{"acceptable", "purple-Rain-42", nil, ""},
+		{"too short", "aB3$", nil, "at least 8"},
+		{"too long", strings.Repeat("aB3$", 33), nil, "cannot exceed 128"},
+		{"contains username", "xx-alice-2024!", []string{"alice"}, "username or email"},
+		{"username case-insensitive", "xx-ALICE-2024!", []string{"Alice"}, "username or email"},
+		{"short personal values ignored", "purple-Rain-42", []string{"ra"}, ""},
+		{"breached", "Tr0ub4dor&3xyz", nil, "breached"},
+		{"low entropy", "aaaaaaaaaaaa", nil, "too easy to guess"},
+		{"sequential", "abcdefghijkl", nil, "too easy to guess"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.Check(tt.password, tt.personal...)
+			if tt.wantErr == "" {
+				if got != "" {
+					t.Errorf("Check(%q) = %q, want acceptable", tt.password, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.wantErr) {
+				t.Errorf("Check(%q) = %q, want it to mention %q", tt.password, got, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEstimateEntropy(t *testing.T) {
+	if got := EstimateEntropy(""); got != 0 {
+		t.Errorf("empty password: %v bits, want 0", got)
+	}
+	// Mixing character classes and avoiding runs must raise the estimate
+	if EstimateEntropy("aaaaaaaa") >= EstimateEntropy("qxzvmwpk") {
+		t.Error("repeated characters should count for less")
+	}
+	if EstimateEntropy("qxzvmwpk") >= EstimateEntropy("qX7%mWp!") {
+		t.Error("more character classes should count for more")
+	}
+}
+
+func TestLoadBreachedPasswords(t *testing.T) {
+	sum := sha1.Sum([]byte("hunter2"))
+	hashLine := strings.ToLower(hex.EncodeToString(sum[:])) + ":12345"
+
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	content := "password123\r\n\n" + hashLine + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, added, err := LoadBreachedPasswords(path)
+	if err != nil {
+		t.Fatalf("LoadBreachedPasswords: %v", err)
+	}
+	if added != 2 {
+		t.Errorf("added %d entries, want 2", added)
+	}
+	for _, pw := range []string{"password123", "hunter2"} {
+		if !filter.Contains([]byte(breachedKey(pw))) {
+			t.Errorf("%q not found in the filter", pw)
+		}
+	}
+	if filter.Contains([]byte(breachedKey("not-in-the-list"))) {
+		t.Error("unlisted password found in the filter")
+	}
+}
+
+func TestLoadBreachedPasswordsMissingFile(t *testing.T) {
+	if _, _, err := LoadBreachedPasswords(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
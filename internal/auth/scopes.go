@@ -0,0 +1,26 @@
+package auth
+
+// Scopes that can be granted to personal access tokens.
+const (
+	ScopeBlogsRead    = "blogs:read"
+	ScopeBlogsWrite   = "blogs:write"
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+)
+
+var AllScopes = []string{
+	ScopeBlogsRead,
+	ScopeBlogsWrite,
+	ScopeProfileRead,
+	ScopeProfileWrite,
+}
+
+// ValidScope reports whether scope is one of AllScopes.
+func ValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
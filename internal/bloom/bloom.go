@@ -0,0 +1,78 @@
+// Package bloom implements a Bloom filter: a compact set that answers
+// "definitely not present" or "probably present".
+package bloom
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// Filter is a fixed-size Bloom filter. It is safe for concurrent reads once
+// all items have been added.
+type Filter struct {
+	bits []uint64
+	m    uint64 // number of bits
+	k    uint64 // number of hash functions
+}
+
+// New returns a filter sized for n items at false-positive rate p.
+func New(n int, p float64) *Filter {
+	if n < 1 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.001
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &Filter{
+		bits: make([]uint64, (m+63)/64),
+		m:    m,
+		k:    k,
+	}
+}
+
+// hashes derives two independent 64-bit hashes; the k probe positions are
+// h1 + i*h2 (Kirsch-Mitzenmacher).
+func hashes(item []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(item)
+	sum := h.Sum(nil)
+	var h1, h2 uint64
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+		h2 = h2<<8 | uint64(sum[8+i])
+	}
+	return h1, h2 | 1
+}
+
+// Add inserts item into the filter.
+func (f *Filter) Add(item []byte) {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		f.bits[pos/64] |= 1 << (pos % 64)
+	}
+}
+
+// Contains reports whether item may have been added. False positives are
+// possible at roughly the configured rate; false negatives are not.
+func (f *Filter) Contains(item []byte) bool {
+	h1, h2 := hashes(item)
+	for i := uint64(0); i < f.k; i++ {
+		pos := (h1 + i*h2) % f.m
+		if f.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SizeBytes returns the memory used by the bit array.
+func (f *Filter) SizeBytes() int {
+	return len(f.bits) * 8
+}
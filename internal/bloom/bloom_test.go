@@ -0,0 +1,61 @@
+package bloom
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFilterHasNoFalseNegatives(t *testing.T) {
+	f := New(1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.Add([]byte("item-" + strconv.Itoa(i)))
+	}
+	for i := 0; i < 1000; i++ {
+		if !f.Contains([]byte("item-" + strconv.Itoa(i))) {
+			t.Fatalf("added item %d not found", i)
+		}
+	}
+}
+
+func TestFilterFalsePositiveRate(t *testing.T) {
+	const n, p = 10000, 0.01
+	f := New(n, p)
+	for i := 0; i < n; i++ {
+		f.Add([]byte("in-" + strconv.Itoa(i)))
+	}
+
+	falsePositives := 0
+	const trials = 100000
+	for i := 0; i < trials; i++ {
+		if f.Contains([]byte("out-" + strconv.Itoa(i))) {
+			falsePositives++
+		}
+	}
+	// Allow generous slack over the configured rate
+	if rate := float64(falsePositives) / trials; rate > 2*p {
+		t.Errorf("false-positive rate %.4f, want about %.2f", rate, p)
+	}
+}
+
+func TestNewClampsArguments(t *testing.T) {
+	for _, tt := range []struct {
+		n int
+		p float64
+	}{{0, 0.01}, {-5, 0.01}, {10, 0}, {10, 1}, {10, -1}} {
+		f := New(tt.n, tt.p)
+		if f.m == 0 || f.k == 0 || len(f.bits) == 0 {
+			t.Errorf("New(%d, %v) built an empty filter", tt.n, tt.p)
+		}
+		f.Add([]byte("x"))
+		if !f.Contains([]byte("x")) {
+			t.Errorf("New(%d, %v): added item not found", tt.n, tt.p)
+		}
+	}
+}
+
+func TestEmptyFilterContainsNothing(t *testing.T) {
+	f := New(100, 0.01)
+	if f.Contains([]byte("anything")) {
+		t.Error("empty filter reports an item as present")
+	}
+}
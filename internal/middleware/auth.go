@@ -2,16 +2,55 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"log"
 	"net/http"
 	"strings"
 
 	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
 )
 
 type contextKey string
 
-const UserIDContextKey contextKey = "userID"
+const (
+	UserIDContextKey     contextKey = "userID"
+	ScopesContextKey     contextKey = "scopes"
+	AuthMethodContextKey contextKey = "authMethod"
+	SessionIDContextKey  contextKey = "sessionID"
+)
+
+// Authentication methods stored under AuthMethodContextKey.
+const (
+	AuthMethodJWT         = "jwt"
+	AuthMethodAccessToken = "access_token"
+	AuthMethodCookie      = "cookie"
+)
+
+// accessTokenPrefix mirrors service.AccessTokenPrefix; it is duplicated here
+// so the middleware does not depend on the service package.
+const accessTokenPrefix = "blog_pat_"
+
+// AccessTokenVerifier resolves personal access tokens presented as bearer credentials.
+type AccessTokenVerifier interface {
+	Verify(token string) (*models.PersonalAccessToken, error)
+}
+
+// SessionValidator checks that the session a JWT is bound to has not been
+// revoked.
+type SessionValidator interface {
+	ValidateSession(claims *auth.Claims) error
+}
+
+// AdminChecker reports whether a user has admin rights.
+type AdminChecker interface {
+	IsAdmin(userID int64) (bool, error)
+}
+
+// PostingPolicy decides whether a user may publish content.
+type PostingPolicy interface {
+	CheckCanPost(userID int64) error
+}
 
 func respondWithError(w http.ResponseWriter, code int, message string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -19,41 +58,198 @@ func respondWithError(w http.ResponseWriter, code int, message string) {
 	w.Write([]byte(`{"error": "` + message + `"}`))
 }
 
-func AuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
+// authError is a rejected credential, with the response to send.
+type authError struct {
+	status  int
+	message string
+}
+
+// authenticate resolves the credentials on r to a context carrying the user
+// ID, auth method and session or scopes. It reports ok=false when the
+// request carries no credentials at all.
+func authenticate(r *http.Request, jwtSecret string, tokens AccessTokenVerifier, sessions SessionValidator) (ctx context.Context, ok bool, authErr *authError) {
+	authHeader := r.Header.Get("Authorization")
+	method := AuthMethodJWT
+
+	var tokenString string
+	if authHeader == "" {
+		// Fall back to the browser session cookie
+		cookie, err := r.Cookie(auth.SessionCookieName)
+		if err != nil {
+			return nil, false, nil
+		}
+		tokenString = cookie.Value
+		method = AuthMethodCookie
+	} else {
+		const bearerPrefix = "Bearer "
+		if !strings.HasPrefix(authHeader, bearerPrefix) {
+			return nil, true, &authError{http.StatusUnauthorized, "Invalid authorization format. Must be 'Bearer <token>'"}
+		}
+		tokenString = strings.TrimPrefix(authHeader, bearerPrefix)
+	}
+
+	// Personal access tokens carry their own scopes
+	if method != AuthMethodCookie && strings.HasPrefix(tokenString, accessTokenPrefix) {
+		token, err := tokens.Verify(tokenString)
+		if err != nil {
+			log.Printf("❌ Access token validation error: %v", err)
+			return nil, true, &authError{http.StatusUnauthorized, "Invalid or expired token"}
+		}
+
+		ctx = context.WithValue(r.Context(), UserIDContextKey, token.UserID)
+		ctx = context.WithValue(ctx, ScopesContextKey, []string(token.Scopes))
+		ctx = context.WithValue(ctx, AuthMethodContextKey, AuthMethodAccessToken)
+		return ctx, true, nil
+	}
+
+	claims, err := auth.ValidateToken(tokenString, jwtSecret)
+	if err != nil {
+		log.Printf("❌ Token validation error: %v", err)
+		return nil, true, &authError{http.StatusUnauthorized, "Invalid or expired token"}
+	}
+
+	if err := sessions.ValidateSession(claims); err != nil {
+		log.Printf("❌ Session validation error: %v", err)
+		return nil, true, &authError{http.StatusUnauthorized, "Invalid or expired token"}
+	}
+
+	if method == AuthMethodCookie && !validCSRF(r, claims.SessionID, jwtSecret) {
+		return nil, true, &authError{http.StatusForbidden, "Missing or invalid CSRF token"}
+	}
+
+	ctx = context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
+	ctx = context.WithValue(ctx, AuthMethodContextKey, method)
+	ctx = context.WithValue(ctx, SessionIDContextKey, claims.SessionID)
+	return ctx, true, nil
+}
+
+func AuthMiddleware(jwtSecret string, tokens AccessTokenVerifier, sessions SessionValidator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			authHeader := r.Header.Get("Authorization")
-			if authHeader == "" {
+			ctx, ok, authErr := authenticate(r, jwtSecret, tokens, sessions)
+			if !ok {
 				respondWithError(w, http.StatusUnauthorized, "Authorization header required")
 				return
 			}
-
-			const bearerPrefix = "Bearer "
-			if !strings.HasPrefix(authHeader, bearerPrefix) {
-				respondWithError(w, http.StatusUnauthorized, "Invalid authorization format. Must be 'Bearer <token>'")
+			if authErr != nil {
+				respondWithError(w, authErr.status, authErr.message)
 				return
 			}
 
-			tokenString := strings.TrimPrefix(authHeader, bearerPrefix)
-			log.Printf("DEBUG: Received token: %s...", tokenString[:50])
-			log.Printf("DEBUG: JWT Secret length: %d", len(jwtSecret))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-			claims, err := auth.ValidateToken(tokenString, jwtSecret)
-			if err != nil {
-				log.Printf("❌ Token validation error: %v", err) // Add this
-				respondWithError(w, http.StatusUnauthorized, "Invalid or expired token")
-				return
+// OptionalAuth is for public routes whose response depends on who is
+// asking. Valid credentials put the user in the context as AuthMiddleware
+// does; missing or invalid ones leave the request anonymous rather than
+// rejecting it, so a stale session cookie never hides public content.
+func OptionalAuth(jwtSecret string, tokens AccessTokenVerifier, sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ctx, ok, authErr := authenticate(r, jwtSecret, tokens, sessions); ok && authErr == nil {
+				r = r.WithContext(ctx)
 			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			log.Printf("✅ Middleware: Token validated for user ID: %d", claims.UserID) // Add this
+// validCSRF implements the double-submit check for cookie-authenticated
+// requests: unsafe methods must send the CSRF cookie's value in the CSRF
+// header, and that value must belong to the session.
+func validCSRF(r *http.Request, sessionID int64, secret string) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+
+	cookie, err := r.Cookie(auth.CSRFCookieName)
+	if err != nil {
+		return false
+	}
+	header := r.Header.Get(auth.CSRFHeaderName)
+	expected := auth.CSRFToken(sessionID, secret)
 
-			ctx := context.WithValue(r.Context(), UserIDContextKey, claims.UserID)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(expected)) == 1
+}
 
-			r = r.WithContext(ctx)
+// RequireScope rejects access-token requests that were not granted scope.
+// JWT-authenticated requests carry no scopes and are always allowed through.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := r.Context().Value(ScopesContextKey).([]string)
+			if ok {
+				granted := false
+				for _, s := range scopes {
+					if s == scope {
+						granted = true
+						break
+					}
+				}
+				if !granted {
+					respondWithError(w, http.StatusForbidden, "Token is missing required scope: "+scope)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyAccessTokens only lets through requests authenticated by a user login,
+// e.g. so a leaked access token cannot be used to mint further tokens.
+func DenyAccessTokens(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetAuthMethodFromContext(r.Context()) == AuthMethodAccessToken {
+			respondWithError(w, http.StatusForbidden, "This endpoint cannot be used with an access token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireCanPost rejects authenticated users that are not (yet) allowed to
+// post, e.g. because their email address has not been verified.
+func RequireCanPost(policy PostingPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+			if err := policy.CheckCanPost(userID); err != nil {
+				log.Printf("❌ User %d may not post: %v", userID, err)
+				respondWithError(w, http.StatusForbidden, "Please verify your email address before posting")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
 
-			log.Printf("✅ Middleware: Calling next handler") // Add this
+// RequireAdmin only lets through authenticated admins.
+func RequireAdmin(admins AdminChecker) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				respondWithError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+			isAdmin, err := admins.IsAdmin(userID)
+			if err != nil {
+				log.Printf("❌ Failed to check admin rights for user %d: %v", userID, err)
+			}
+			if !isAdmin {
+				respondWithError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
 			next.ServeHTTP(w, r)
-			log.Printf("✅ Middleware: Handler completed") // Add this
 		})
 	}
 }
@@ -62,3 +258,14 @@ func GetUserIDFromContext(ctx context.Context) (int64, bool) {
 	userID, ok := ctx.Value(UserIDContextKey).(int64)
 	return userID, ok
 }
+
+// GetSessionIDFromContext returns the session of a JWT-authenticated request.
+func GetSessionIDFromContext(ctx context.Context) (int64, bool) {
+	sessionID, ok := ctx.Value(SessionIDContextKey).(int64)
+	return sessionID, ok
+}
+
+func GetAuthMethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(AuthMethodContextKey).(string)
+	return method
+}
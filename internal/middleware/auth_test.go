@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+)
+
+const testCSRFSecret = "test-secret"
+
+func TestValidCSRF(t *testing.T) {
+	token := auth.CSRFToken(42, testCSRFSecret)
+	otherToken := auth.CSRFToken(43, testCSRFSecret)
+
+	tests := []struct {
+		name   string
+		method string
+		cookie string
+		header string
+		want   bool
+	}{
+		{"GET needs no token", http.MethodGet, "", "", true},
+		{"HEAD needs no token", http.MethodHead, "", "", true},
+		{"OPTIONS needs no token", http.MethodOptions, "", "", true},
+		{"valid token", http.MethodPost, token, token, true},
+		{"valid token on DELETE", http.MethodDelete, token, token, true},
+		{"missing cookie", http.MethodPost, "", token, false},
+		{"missing header", http.MethodPost, token, "", false},
+		{"header differs from cookie", http.MethodPost, token, otherToken, false},
+		{"token for another session", http.MethodPost, otherToken, otherToken, false},
+		{"forged token", http.MethodPut, "forged", "forged", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/blogs", nil)
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: auth.CSRFCookieName, Value: tt.cookie})
+			}
+			if tt.header != "" {
+				r.Header.Set(auth.CSRFHeaderName, tt.header)
+			}
+			if got := validCSRF(r, 42, testCSRFSecret); got != tt.want {
+				t.Errorf("validCSRF = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCSRFTokenDependsOnSecret(t *testing.T) {
+	if auth.CSRFToken(42, "one secret") == auth.CSRFToken(42, "another secret") {
+		t.Error("tokens signed with different secrets are equal")
+	}
+}
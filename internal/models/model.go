@@ -1,20 +1,383 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type User struct {
-	ID        int64  `db:"id" json:"id"`
-	Username  string `db:"username" json:"username"`
-	Password  string `db:"password" json:"-"`
-	BlogCount int    `db:"blog_count" json:"blog_count"`
+	ID                  int64       `db:"id" json:"id"`
+	Username            string      `db:"username" json:"username"`
+	Password            string      `db:"password" json:"-"`
+	Email               *string     `db:"email" json:"email,omitempty"`
+	EmailVerifiedAt     *time.Time  `db:"email_verified_at" json:"-"`
+	DisplayName         string      `db:"display_name" json:"display_name"`
+	Bio                 string      `db:"bio" json:"bio"`
+	AvatarURL           string      `db:"avatar_url" json:"avatar_url"`
+	Website             string      `db:"website" json:"website"`
+	Location            string      `db:"location" json:"location"`
+	SocialLinks         SocialLinks `db:"social_links" json:"social_links"`
+	UsernameChangedAt   *time.Time  `db:"username_changed_at" json:"-"`
+	DeletionRequestedAt *time.Time  `db:"deletion_requested_at" json:"-"`
+	DeletionMode        *string     `db:"deletion_mode" json:"-"`
+	Status              string      `db:"status" json:"-"`
+	IsAdmin             bool        `db:"is_admin" json:"-"`
+	InvitedBy           *int64      `db:"invited_by" json:"-"`
+	InviteID            *int64      `db:"invite_id" json:"-"`
+	CreatedAt           time.Time   `db:"created_at" json:"created_at"`
+	BlogCount           int         `db:"blog_count" json:"blog_count"`
+}
+
+// SocialLinks maps a network name (e.g. "github") to a profile URL. It is
+// stored as a JSONB column.
+type SocialLinks map[string]string
+
+func (l SocialLinks) Value() (driver.Value, error) {
+	if l == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *SocialLinks) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*l = SocialLinks{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into SocialLinks", src)
+	}
+	return json.Unmarshal(b, l)
+}
+
+// Author is the compact user object embedded in blog responses. The
+// profile fields below AvatarURL are only filled in when a client asks for
+// the author with ?include=author.
+type Author struct {
+	ID          int64       `db:"id" json:"id"`
+	Username    string      `db:"username" json:"username"`
+	DisplayName string      `db:"display_name" json:"display_name,omitempty"`
+	AvatarURL   string      `db:"avatar_url" json:"avatar_url,omitempty"`
+	Bio         string      `db:"bio" json:"bio,omitempty"`
+	Website     string      `db:"website" json:"website,omitempty"`
+	Location    string      `db:"location" json:"location,omitempty"`
+	SocialLinks SocialLinks `db:"social_links" json:"social_links,omitempty"`
+	JoinedAt    *time.Time  `db:"joined_at" json:"joined_at,omitempty"`
+}
+
+// CoAuthor is a blog co-author with their role on that blog.
+type CoAuthor struct {
+	Author
+	Role string `json:"role"`
+}
+
+// CoAuthors is selected as a JSON array alongside each blog.
+type CoAuthors []CoAuthor
+
+func (c *CoAuthors) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*c = CoAuthors{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into CoAuthors", src)
+	}
+	return json.Unmarshal(b, c)
 }
 
 type Blog struct {
 	ID        int64     `db:"id" json:"id"`
-	UserId    int64     `db:"user_id" json:"user_id"`
+	UserId    int64     `db:"user_id" json:"-"`
+	Author    Author    `db:"author" json:"author"`
 	Title     string    `db:"title" json:"title"`
-	Content   string    `db:"content" json:"content"`
+	// Content is left out of lists unless requested
+	Content   string    `db:"content" json:"content,omitempty"`
 	ViewCount int       `db:"view_count" json:"view_count"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt *time.Time `db:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	// Status is draft, submitted or published
+	Status        string     `db:"status" json:"status"`
+	PublicationID *int64     `db:"publication_id" json:"publication_id,omitempty"`
+	PublishedAt   *time.Time `db:"published_at" json:"published_at,omitempty"`
+	// Visibility is public, unlisted, followers, private or password
+	Visibility string `db:"visibility" json:"visibility"`
+	// Summary is the author's own summary; Excerpt is Summary or the start
+	// of Content. Both, WordCount and ReadingTime are set on save.
+	Summary     string `db:"summary" json:"summary,omitempty"`
+	Excerpt     string `db:"excerpt" json:"excerpt"`
+	WordCount   int    `db:"word_count" json:"word_count"`
+	ReadingTime int    `db:"reading_time" json:"reading_time_minutes"`
+	// DeletedAt is set on posts in the trash
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	// CoAuthors lists accepted co-authors besides the owner (Author)
+	CoAuthors CoAuthors `db:"co_authors" json:"co_authors"`
+	// Reactions maps reaction type to count; filled in by the service
+	Reactions map[string]int `db:"-" json:"reactions"`
+	// Bookmarked is set only when the viewer is signed in
+	Bookmarked *bool `db:"-" json:"bookmarked,omitempty"`
+	// Series is set when viewing a single blog that is part of a series
+	Series *SeriesInfo `db:"-" json:"series,omitempty"`
+	// Tags and Stats are set on request with ?include=tags,stats
+	Tags  []string   `db:"-" json:"tags,omitempty"`
+	Stats *BlogStats `db:"-" json:"stats,omitempty"`
+}
+
+// BlogStats are a blog's engagement numbers.
+type BlogStats struct {
+	BlogID       int64 `db:"blog_id" json:"-"`
+	Views        int   `db:"views" json:"views"`
+	Words        int   `db:"words" json:"words"`
+	ReadingTime  int   `db:"reading_time" json:"reading_time_minutes"`
+	Reactions    int   `db:"reactions" json:"reactions"`
+	Bookmarks    int   `db:"bookmarks" json:"bookmarks"`
+	ReadingLists int   `db:"reading_lists" json:"reading_lists"`
+}
+
+// BlogContent is the content of one blog, loaded separately for lists.
+type BlogContent struct {
+	BlogID  int64  `db:"blog_id"`
+	Content string `db:"content"`
+}
+
+// BlogTag is one tag on a blog.
+type BlogTag struct {
+	BlogID int64  `db:"blog_id"`
+	Tag    string `db:"tag"`
+}
+
+type PersonalAccessToken struct {
+	ID         int64          `db:"id" json:"id"`
+	UserID     int64          `db:"user_id" json:"-"`
+	Name       string         `db:"name" json:"name"`
+	TokenHash  string         `db:"token_hash" json:"-"`
+	Prefix     string         `db:"token_prefix" json:"prefix"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at"`
+	LastUsedAt *time.Time     `db:"last_used_at" json:"last_used_at"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// UserToken is a single-use token mailed to a user, identified by Purpose.
+// Email holds the address being verified for email verification tokens.
+type UserToken struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	Purpose   string     `db:"purpose"`
+	TokenHash string     `db:"token_hash"`
+	Email     *string    `db:"email"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
+
+// UserIdentity links an account at an external OpenID Connect provider to a user.
+type UserIdentity struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"-"`
+	Provider  string    `db:"provider" json:"provider"`
+	Subject   string    `db:"subject" json:"-"`
+	Email     *string   `db:"email" json:"email,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Session is a signed-in device. Current is set when listing sessions for
+// the session making the request.
+type Session struct {
+	ID         int64      `db:"id" json:"id"`
+	UserID     int64      `db:"user_id" json:"-"`
+	Device     string     `db:"device" json:"device"`
+	IP         string     `db:"ip" json:"ip"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastSeenAt time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	RevokedAt  *time.Time `db:"revoked_at" json:"-"`
+	Current    bool       `db:"-" json:"current"`
+}
+
+// DataExport is a ZIP archive of everything a user has stored with us.
+// FilePath and TokenHash are set once the archive is ready.
+type DataExport struct {
+	ID          int64      `db:"id" json:"id"`
+	UserID      int64      `db:"user_id" json:"-"`
+	Status      string     `db:"status" json:"status"`
+	FilePath    string     `db:"file_path" json:"-"`
+	SizeBytes   int64      `db:"size_bytes" json:"size_bytes"`
+	TokenHash   *string    `db:"token_hash" json:"-"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	CompletedAt *time.Time `db:"completed_at" json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+}
+
+// Invite is a registration code. Only a hash of the code is stored; the
+// plaintext is returned once, when the invite is created.
+type Invite struct {
+	ID         int64          `db:"id" json:"id"`
+	CreatedBy  int64          `db:"created_by" json:"-"`
+	CodeHash   string         `db:"code_hash" json:"-"`
+	CodePrefix string         `db:"code_prefix" json:"code_prefix"`
+	MaxUses    int            `db:"max_uses" json:"max_uses"`
+	UseCount   int            `db:"use_count" json:"use_count"`
+	ExpiresAt  *time.Time     `db:"expires_at" json:"expires_at,omitempty"`
+	RevokedAt  *time.Time     `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+	Invitees   pq.StringArray `db:"invitees" json:"invitees"`
+}
+
+// ReactionCount is one aggregated row of reactions on a blog.
+type ReactionCount struct {
+	BlogID   int64  `db:"blog_id"`
+	Reaction string `db:"reaction"`
+	Count    int    `db:"count"`
+}
+
+// ReadingList is a user's named collection of blogs.
+type ReadingList struct {
+	ID        int64     `db:"id" json:"id"`
+	UserID    int64     `db:"user_id" json:"-"`
+	Name      string    `db:"name" json:"name"`
+	IsPublic  bool      `db:"is_public" json:"is_public"`
+	ItemCount int       `db:"item_count" json:"item_count"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Series is an ordered, multi-part collection of one author's blogs.
+type Series struct {
+	ID          int64     `db:"id" json:"id"`
+	UserID      int64     `db:"user_id" json:"user_id"`
+	Title       string    `db:"title" json:"title"`
+	Description string    `db:"description" json:"description"`
+	PostCount   int       `db:"post_count" json:"post_count"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// SeriesEntry is one part of a series in its table of contents. Position
+// is 1-based.
+type SeriesEntry struct {
+	BlogID   int64  `db:"blog_id" json:"blog_id"`
+	Title    string `db:"title" json:"title"`
+	Position int    `db:"position" json:"position"`
+}
+
+// SeriesInfo places a blog within its series.
+type SeriesInfo struct {
+	ID       int64         `json:"id"`
+	Title    string        `json:"title"`
+	Position int           `json:"position"`
+	Total    int           `json:"total"`
+	Previous *SeriesEntry  `json:"previous"`
+	Next     *SeriesEntry  `json:"next"`
+	Contents []SeriesEntry `json:"contents"`
+}
+
+// BlogCoAuthor is a co-author invitation or membership on a blog.
+type BlogCoAuthor struct {
+	BlogID      int64      `db:"blog_id" json:"blog_id"`
+	UserID      int64      `db:"user_id" json:"user_id"`
+	Username    string     `db:"username" json:"username"`
+	DisplayName string     `db:"display_name" json:"display_name,omitempty"`
+	Role        string     `db:"role" json:"role"`
+	InvitedBy   *int64     `db:"invited_by" json:"-"`
+	CreatedAt   time.Time  `db:"created_at" json:"invited_at"`
+	AcceptedAt  *time.Time `db:"accepted_at" json:"accepted_at"`
+}
+
+// CoAuthorInvite is a pending co-author invitation as seen by the invitee.
+type CoAuthorInvite struct {
+	BlogID    int64     `db:"blog_id" json:"blog_id"`
+	BlogTitle string    `db:"blog_title" json:"blog_title"`
+	Role      string    `db:"role" json:"role"`
+	InvitedBy string    `db:"invited_by" json:"invited_by"`
+	CreatedAt time.Time `db:"created_at" json:"invited_at"`
+}
+
+// Publication is a team blog whose posts are written by its members.
+type Publication struct {
+	ID          int64     `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Slug        string    `db:"slug" json:"slug"`
+	Description string    `db:"description" json:"description"`
+	CreatedBy   *int64    `db:"created_by" json:"-"`
+	MemberCount int       `db:"member_count" json:"member_count"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PublicationMember is a user's role in a publication.
+type PublicationMember struct {
+	PublicationID int64     `db:"publication_id" json:"-"`
+	UserID        int64     `db:"user_id" json:"user_id"`
+	Username      string    `db:"username" json:"username"`
+	DisplayName   string    `db:"display_name" json:"display_name,omitempty"`
+	Role          string    `db:"role" json:"role"`
+	CreatedAt     time.Time `db:"created_at" json:"joined_at"`
+}
+
+// BlogReviewer is a user asked to review a submitted blog. Decision is
+// their latest decision since the blog was last submitted, if any.
+type BlogReviewer struct {
+	BlogID      int64     `db:"blog_id" json:"-"`
+	UserID      int64     `db:"user_id" json:"user_id"`
+	Username    string    `db:"username" json:"username"`
+	DisplayName string    `db:"display_name" json:"display_name,omitempty"`
+	AssignedAt  time.Time `db:"assigned_at" json:"assigned_at"`
+	Decision    *string   `db:"decision" json:"decision"`
+}
+
+// ReviewComment is a review remark, anchored to the rune range
+// [AnchorStart, AnchorEnd) of the blog content when both are set.
+// Outdated is set when the content at that range no longer matches
+// QuotedText.
+type ReviewComment struct {
+	ID          int64      `db:"id" json:"id"`
+	BlogID      int64      `db:"blog_id" json:"-"`
+	UserID      *int64     `db:"user_id" json:"user_id"`
+	Username    string     `db:"username" json:"username"`
+	Body        string     `db:"body" json:"body"`
+	AnchorStart *int       `db:"anchor_start" json:"anchor_start"`
+	AnchorEnd   *int       `db:"anchor_end" json:"anchor_end"`
+	QuotedText  string     `db:"quoted_text" json:"quoted_text,omitempty"`
+	ResolvedAt  *time.Time `db:"resolved_at" json:"resolved_at"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	Outdated    bool       `db:"-" json:"outdated"`
+}
+
+// ReviewEvent is one entry in a blog's review history.
+type ReviewEvent struct {
+	ID        int64     `db:"id" json:"id"`
+	BlogID    int64     `db:"blog_id" json:"-"`
+	UserID    *int64    `db:"user_id" json:"user_id"`
+	Username  string    `db:"username" json:"username"`
+	Action    string    `db:"action" json:"action"`
+	Note      string    `db:"note" json:"note,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// PreviewLink is a secret, expiring link to read one blog regardless of its
+// status or visibility. The token itself is only shown when created.
+type PreviewLink struct {
+	ID             int64      `db:"id" json:"id"`
+	BlogID         int64      `db:"blog_id" json:"blog_id"`
+	CreatedBy      int64      `db:"created_by" json:"-"`
+	TokenHash      string     `db:"token_hash" json:"-"`
+	TokenPrefix    string     `db:"token_prefix" json:"token_prefix"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	RevokedAt      *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+	AccessCount    int        `db:"access_count" json:"access_count"`
+	LastAccessedAt *time.Time `db:"last_accessed_at" json:"last_accessed_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccessTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewAccessTokenRepo(db *sqlx.DB) *AccessTokenRepo {
+	return &AccessTokenRepo{db: db}
+}
+
+func (r *AccessTokenRepo) CreateToken(token *models.PersonalAccessToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	return r.db.QueryRow(
+		query, token.UserID, token.Name, token.TokenHash, token.Prefix, token.Scopes, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+func (r *AccessTokenRepo) GetTokensByUserID(userID int64) ([]models.PersonalAccessToken, error) {
+	tokens := []models.PersonalAccessToken{}
+	query := `
+		SELECT id, user_id, name, token_prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+	err := r.db.Select(&tokens, query, userID)
+	if err != nil {
+		log.Printf("Error getting access tokens for user %d: %v", userID, err)
+	}
+	return tokens, err
+}
+
+func (r *AccessTokenRepo) GetTokenByHash(tokenHash string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	query := `
+		SELECT id, user_id, name, token_hash, token_prefix, scopes, expires_at, last_used_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1`
+	if err := r.db.Get(&token, query, tokenHash); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// TouchToken records that the token was just used.
+func (r *AccessTokenRepo) TouchToken(tokenID int64) error {
+	query := `UPDATE personal_access_tokens SET last_used_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(query, tokenID); err != nil {
+		log.Printf("Error updating last_used_at for token %d: %v", tokenID, err)
+		return fmt.Errorf("failed to update token usage: %w", err)
+	}
+	return nil
+}
+
+func (r *AccessTokenRepo) DeleteToken(tokenID, userID int64) error {
+	query := `
+		DELETE FROM personal_access_tokens
+		WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, tokenID, userID)
+	if err != nil {
+		log.Printf("Error deleting token %d by user %d: %v", tokenID, userID, err)
+		return fmt.Errorf("failed to delete token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows after delete: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no token found with ID %d for user %d", tokenID, userID)
+	}
+
+	return nil
+}
@@ -4,10 +4,57 @@ import (
 	"fmt" 
 	"log" 
 	"strings"
+	"time"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/Brownie44l1/blog/internal/models"
 )
 
+// blogColumns selects a blog together with its author and accepted
+// co-authors. Queries using it must alias blogs as b and join users as u.
+const blogColumns = `b.content, ` + blogSummaryColumns
+
+// blogSummaryColumns is blogColumns without the content, for lists; callers
+// that want it load it with GetBlogContents.
+const blogSummaryColumns = `
+	b.id, b.user_id, b.title, b.view_count, b.created_at, b.updated_at,
+	b.summary, b.excerpt, b.word_count, b.reading_time,
+	b.status, b.publication_id, b.published_at, b.visibility, b.deleted_at,
+	u.id AS "author.id", u.username AS "author.username",
+	u.display_name AS "author.display_name", u.avatar_url AS "author.avatar_url",
+	COALESCE((
+		SELECT json_agg(json_build_object(
+			'id', cu.id, 'username', cu.username, 'display_name', cu.display_name,
+			'avatar_url', cu.avatar_url, 'role', ba.role) ORDER BY ba.accepted_at)
+		FROM blog_authors ba JOIN users cu ON cu.id = ba.user_id
+		WHERE ba.blog_id = b.id AND ba.accepted_at IS NOT NULL
+	), '[]') AS co_authors`
+
+// liveBlogFilter leaves out posts in the trash; every read of blogs aliased
+// as b applies it, directly or through the filters below.
+const liveBlogFilter = `b.deleted_at IS NULL`
+
+// publicBlogFilter limits a query aliasing blogs as b to posts anyone may
+// find in listings, search and feeds.
+const publicBlogFilter = liveBlogFilter + ` AND b.status = 'published' AND b.visibility = 'public'`
+
+// visibleBlogFilter is publicBlogFilter plus followers-only posts by users
+// the viewer (the query parameter viewerParam, 0 when anonymous) follows.
+func visibleBlogFilter(viewerParam string) string {
+	return liveBlogFilter + ` AND b.status = 'published' AND (b.visibility = 'public' OR (b.visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows f WHERE f.follower_id = ` + viewerParam + ` AND f.followee_id = b.user_id
+		)))`
+}
+
+// linkedBlogFilter is visibleBlogFilter plus unlisted posts, for posts a
+// user saved (bookmarks, reactions and reading lists): unlisted posts are
+// read by link, which is how they got there.
+func linkedBlogFilter(viewerParam string) string {
+	return liveBlogFilter + ` AND b.status = 'published' AND (b.visibility IN ('public', 'unlisted') OR (b.visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows f WHERE f.follower_id = ` + viewerParam + ` AND f.followee_id = b.user_id
+		)))`
+}
+
 type BlogRepo struct {
 	db *sqlx.DB
 }
@@ -16,19 +63,24 @@ func NewBlogRepo(db *sqlx.DB) *BlogRepo {
 	return &BlogRepo{db: db}
 }
 
-func (r *BlogRepo) CreateBlog(blog *models.Blog) error {
+// CreateBlog inserts a blog; accessPasswordHash is set for password posts.
+func (r *BlogRepo) CreateBlog(blog *models.Blog, accessPasswordHash *string) error {
 	query := `
-		INSERT INTO blogs (user_id, title, content)
-		VALUES($1, $2, $3)
-		RETURNING id, created_at`
-	return r.db.QueryRow(
-		query, blog.UserId, blog.Title, blog.Content,
-	).Scan(&blog.ID, &blog.CreatedAt)
+		WITH b AS (
+			INSERT INTO blogs (user_id, title, content, status, publication_id, published_at, visibility, access_password_hash,
+				summary, excerpt, word_count, reading_time)
+			VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			RETURNING *
+		)
+		SELECT ` + blogColumns + `
+		FROM b JOIN users u ON u.id = b.user_id`
+	return r.db.Get(blog, query, blog.UserId, blog.Title, blog.Content, blog.Status, blog.PublicationID, blog.PublishedAt,
+		blog.Visibility, accessPasswordHash, blog.Summary, blog.Excerpt, blog.WordCount, blog.ReadingTime)
 }
 
 func (r *BlogRepo) GetBlogByID(id int64) (*models.Blog, error) {
 	var blog models.Blog
-	query := `SELECT id, user_id, title, content, created_at FROM blogs WHERE id=$1`
+	query := `SELECT ` + blogColumns + ` FROM blogs b JOIN users u ON u.id = b.user_id WHERE b.id=$1 AND ` + liveBlogFilter
 	err := r.db.Get(&blog, query, id)
 	if err != nil {
 		log.Printf("Error getting blog by ID %d: %v", id, err)
@@ -39,7 +91,7 @@ func (r *BlogRepo) GetBlogByID(id int64) (*models.Blog, error) {
 
 func (r *BlogRepo) GetBlogByUserID(userID int64) ([]models.Blog, error) {
 	blogs := []models.Blog{}
-	query := `SELECT id, user_id, title, content, created_at FROM blogs WHERE user_id=$1 ORDER BY created_at DESC`
+	query := `SELECT ` + blogColumns + ` FROM blogs b JOIN users u ON u.id = b.user_id WHERE b.user_id=$1 AND ` + liveBlogFilter + ` ORDER BY b.created_at DESC`
 	err := r.db.Select(&blogs, query, userID)
 	if err != nil {
 		log.Printf("Error getting blogs for user %d: %v", userID, err)
@@ -47,21 +99,49 @@ func (r *BlogRepo) GetBlogByUserID(userID int64) ([]models.Blog, error) {
 	return blogs, err
 }
 
+// GetBlogsByAuthor returns blogs the user owns or has accepted a co-author
+// invite on, newest first. With publishedOnly, only posts viewerID may find
+// in listings are returned; otherwise drafts, submitted and non-public
+// posts are included.
+func (r *BlogRepo) GetBlogsByAuthor(userID, viewerID int64, publishedOnly bool) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b JOIN users u ON u.id = b.user_id
+		WHERE (b.user_id = $1 OR EXISTS (
+			SELECT 1 FROM blog_authors ba
+			WHERE ba.blog_id = b.id AND ba.user_id = $1 AND ba.accepted_at IS NOT NULL
+		))
+		AND ` + liveBlogFilter + ` AND (NOT $2 OR ` + visibleBlogFilter("$3") + `)
+		ORDER BY b.created_at DESC`
+	err := r.db.Select(&blogs, query, userID, publishedOnly, viewerID)
+	if err != nil {
+		log.Printf("Error getting authored blogs for user %d: %v", userID, err)
+	}
+	return blogs, err
+}
+
 func (r *BlogRepo) UpdateBlog(blog *models.Blog) error {
     query := `
-        UPDATE blogs 
-        SET title = $1, content = $2, updated_at = NOW()
-        WHERE id = $3 AND user_id = $4
-        RETURNING id, user_id, title, content, created_at, updated_at
+        WITH b AS (
+            UPDATE blogs
+            SET title = $1, content = $2, summary = $5, excerpt = $6, word_count = $7, reading_time = $8,
+                updated_at = NOW()
+            WHERE id = $3 AND user_id = $4 AND deleted_at IS NULL
+            RETURNING *
+        )
+        SELECT ` + blogColumns + `
+        FROM b JOIN users u ON u.id = b.user_id
     `
-    return r.db.QueryRow(query, blog.Title, blog.Content, blog.ID, blog.UserId).
-        Scan(&blog.ID, &blog.UserId, &blog.Title, &blog.Content, &blog.CreatedAt, &blog.UpdatedAt)
+    return r.db.Get(blog, query, blog.Title, blog.Content, blog.ID, blog.UserId,
+        blog.Summary, blog.Excerpt, blog.WordCount, blog.ReadingTime)
 }
 
+// DeleteBlog moves a blog to the trash; PurgeTrashedBlogs removes it for good.
 func (r *BlogRepo) DeleteBlog(blogID, userID int64) error {
 	query := `
-		DELETE FROM blogs
-		WHERE id = $1 AND user_id = $2`
+		UPDATE blogs SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	result, err := r.db.Exec(query, blogID, userID)
 	if err != nil {
@@ -80,32 +160,380 @@ func (r *BlogRepo) DeleteBlog(blogID, userID int64) error {
 	return nil
 }
 
-func (r *BlogRepo) GetAllBlogs(limit, offset int64) ([]models.Blog, error) {
+// GetAllBlogs lists the posts viewerID may find, newest first.
+func (r *BlogRepo) GetAllBlogs(viewerID, limit, offset int64) ([]models.Blog, error) {
 	blogs := []models.Blog{}
 	query := `
-		SELECT id, user_id, title, content, created_at FROM blogs
-		ORDER BY created_at DESC
+		SELECT ` + blogSummaryColumns + ` FROM blogs b
+		JOIN users u ON u.id = b.user_id
+		WHERE ` + visibleBlogFilter("$3") + `
+		ORDER BY b.created_at DESC
 		LIMIT $1 OFFSET $2`
-	err := r.db.Select(&blogs, query, limit, offset)
+	err := r.db.Select(&blogs, query, limit, offset, viewerID)
 	if err != nil {
 		log.Printf("Error getting all blogs with limit %d offset %d: %v", limit, offset, err)
 	}
 	return blogs, err
 }
 
-func (r *BlogRepo) SearchBlogs(searchQuery string) ([]models.Blog, error) {
+func (r *BlogRepo) SearchBlogs(searchQuery string, viewerID int64) ([]models.Blog, error) {
 	blogs := []models.Blog{}
 	searchPattern := "%" + strings.ToLower(searchQuery) + "%"
 	query := `
-		SELECT id, user_id, title, content, created_at 
-		FROM blogs
-		WHERE search_vector @@ plainto_tsquery('english', $1)
-		ORDER BY created_at DESC`
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.search_vector @@ plainto_tsquery('english', $1) AND ` + visibleBlogFilter("$2") + `
+		ORDER BY b.created_at DESC`
 
-	err := r.db.Select(&blogs, query, searchPattern)
+	err := r.db.Select(&blogs, query, searchPattern, viewerID)
 	if err != nil {
 		log.Printf("Error searching blogs for query %s: %v", searchQuery, err)
 	}
 	return blogs, err
 }
 
+
+// GetBlogsReactedByUser lists blogs the user reacted to with reaction, most
+// recent reaction first.
+func (r *BlogRepo) GetBlogsReactedByUser(userID int64, reaction string, limit, offset int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blog_reactions br
+		JOIN blogs b ON b.id = br.blog_id
+		JOIN users u ON u.id = b.user_id
+		WHERE br.user_id = $1 AND br.reaction = $2 AND ` + linkedBlogFilter("$1") + `
+		ORDER BY br.created_at DESC
+		LIMIT $3 OFFSET $4`
+	err := r.db.Select(&blogs, query, userID, reaction, limit, offset)
+	if err != nil {
+		log.Printf("Error getting blogs with %s reactions by user %d: %v", reaction, userID, err)
+	}
+	return blogs, err
+}
+
+// GetBookmarkedBlogs lists the user's bookmarks, most recently saved first.
+func (r *BlogRepo) GetBookmarkedBlogs(userID, limit, offset int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM bookmarks bm
+		JOIN blogs b ON b.id = bm.blog_id
+		JOIN users u ON u.id = b.user_id
+		WHERE bm.user_id = $1 AND ` + linkedBlogFilter("$1") + `
+		ORDER BY bm.created_at DESC
+		LIMIT $2 OFFSET $3`
+	err := r.db.Select(&blogs, query, userID, limit, offset)
+	if err != nil {
+		log.Printf("Error getting bookmarked blogs for user %d: %v", userID, err)
+	}
+	return blogs, err
+}
+
+// GetReadingListBlogs returns the blogs on a reading list that viewerID may
+// read, in reading order.
+func (r *BlogRepo) GetReadingListBlogs(listID, viewerID int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM reading_list_items i
+		JOIN blogs b ON b.id = i.blog_id
+		JOIN users u ON u.id = b.user_id
+		WHERE i.list_id = $1 AND ` + linkedBlogFilter("$2") + `
+		ORDER BY i.position`
+	err := r.db.Select(&blogs, query, listID, viewerID)
+	if err != nil {
+		log.Printf("Error getting blogs on reading list %d: %v", listID, err)
+	}
+	return blogs, err
+}
+
+// SetBlogStatus moves a blog to status; published_at is stamped the first
+// time it is published.
+func (r *BlogRepo) SetBlogStatus(blogID int64, status string) error {
+	query := `
+		UPDATE blogs SET status = $1,
+			published_at = CASE WHEN $3 THEN COALESCE(published_at, NOW()) ELSE published_at END
+		WHERE id = $2`
+	result, err := r.db.Exec(query, status, blogID, status == "published")
+	if err != nil {
+		log.Printf("Error setting status of blog %d to %s: %v", blogID, status, err)
+		return fmt.Errorf("failed to update blog status: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check updated blog: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no blog found with ID %d", blogID)
+	}
+	return nil
+}
+
+// GetPublicationBlogs lists a publication's posts with the given status;
+// published posts come newest first, others oldest first. Published posts
+// are limited to public ones.
+func (r *BlogRepo) GetPublicationBlogs(publicationID int64, status string, limit, offset int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b JOIN users u ON u.id = b.user_id
+		WHERE b.publication_id = $1 AND b.status = $2 AND ` + liveBlogFilter + `
+			AND (b.status <> 'published' OR b.visibility = 'public')
+		ORDER BY
+			CASE WHEN b.status = 'published' THEN b.published_at END DESC,
+			b.created_at
+		LIMIT $3 OFFSET $4`
+	err := r.db.Select(&blogs, query, publicationID, status, limit, offset)
+	if err != nil {
+		log.Printf("Error getting %s blogs of publication %d: %v", status, publicationID, err)
+	}
+	return blogs, err
+}
+
+// GetBlogsAwaitingReview lists submitted blogs the user has been assigned
+// to review, oldest submission first.
+func (r *BlogRepo) GetBlogsAwaitingReview(userID int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b JOIN users u ON u.id = b.user_id
+		JOIN blog_reviewers br ON br.blog_id = b.id AND br.user_id = $1
+		WHERE b.status = 'submitted' AND ` + liveBlogFilter + `
+		ORDER BY br.assigned_at`
+	err := r.db.Select(&blogs, query, userID)
+	if err != nil {
+		log.Printf("Error getting blogs awaiting review by user %d: %v", userID, err)
+	}
+	return blogs, err
+}
+
+// SetBlogVisibility changes who may read a blog; accessPasswordHash is set
+// for password posts and cleared otherwise.
+func (r *BlogRepo) SetBlogVisibility(blogID int64, visibility string, accessPasswordHash *string) error {
+	query := `UPDATE blogs SET visibility = $2, access_password_hash = $3, updated_at = NOW() WHERE id = $1`
+	result, err := r.db.Exec(query, blogID, visibility, accessPasswordHash)
+	if err != nil {
+		log.Printf("Error setting visibility of blog %d: %v", blogID, err)
+		return fmt.Errorf("failed to set blog visibility: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check blog visibility update: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no blog found with ID %d", blogID)
+	}
+	return nil
+}
+
+// GetBlogAccessPasswordHash returns the access password hash of a password
+// post, or "" when it has none.
+func (r *BlogRepo) GetBlogAccessPasswordHash(blogID int64) (string, error) {
+	var hash string
+	query := `SELECT COALESCE(access_password_hash, '') FROM blogs WHERE id = $1`
+	if err := r.db.Get(&hash, query, blogID); err != nil {
+		log.Printf("Error getting access password of blog %d: %v", blogID, err)
+		return "", err
+	}
+	return hash, nil
+}
+
+// GetTrashedBlogs lists the user's blogs in the trash, most recently
+// deleted first.
+func (r *BlogRepo) GetTrashedBlogs(userID int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b JOIN users u ON u.id = b.user_id
+		WHERE b.user_id = $1 AND b.deleted_at IS NOT NULL
+		ORDER BY b.deleted_at DESC`
+	err := r.db.Select(&blogs, query, userID)
+	if err != nil {
+		log.Printf("Error getting trashed blogs for user %d: %v", userID, err)
+	}
+	return blogs, err
+}
+
+// RestoreBlog takes a blog out of the trash.
+func (r *BlogRepo) RestoreBlog(blogID, userID int64) error {
+	query := `UPDATE blogs SET deleted_at = NULL WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`
+	result, err := r.db.Exec(query, blogID, userID)
+	if err != nil {
+		log.Printf("Error restoring blog %d for user %d: %v", blogID, userID, err)
+		return fmt.Errorf("failed to restore blog: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check restored blog: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no trashed blog found with ID %d for user %d", blogID, userID)
+	}
+	return nil
+}
+
+// PurgeBlog permanently deletes a blog from the user's trash.
+func (r *BlogRepo) PurgeBlog(blogID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM blogs WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`, blogID, userID)
+	if err != nil {
+		log.Printf("Error purging blog %d for user %d: %v", blogID, userID, err)
+		return fmt.Errorf("failed to purge blog: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check purged blog: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no trashed blog found with ID %d for user %d", blogID, userID)
+	}
+	return nil
+}
+
+// PurgeTrashedBlogs permanently deletes blogs trashed before cutoff and
+// returns how many were removed.
+func (r *BlogRepo) PurgeTrashedBlogs(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM blogs WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		log.Printf("Error purging trashed blogs: %v", err)
+		return 0, fmt.Errorf("failed to purge trashed blogs: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// SetBlogTags replaces a blog's tags.
+func (r *BlogRepo) SetBlogTags(blogID int64, tags []string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM blog_tags WHERE blog_id = $1`, blogID); err != nil {
+		log.Printf("Error clearing tags of blog %d: %v", blogID, err)
+		return fmt.Errorf("failed to set blog tags: %w", err)
+	}
+	if len(tags) > 0 {
+		query := `INSERT INTO blog_tags (blog_id, tag) SELECT $1, unnest($2::VARCHAR[]) ON CONFLICT DO NOTHING`
+		if _, err := tx.Exec(query, blogID, pq.Array(tags)); err != nil {
+			log.Printf("Error tagging blog %d: %v", blogID, err)
+			return fmt.Errorf("failed to set blog tags: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// GetBlogTags returns the tags of several blogs in one query, ordered by
+// blog and tag.
+func (r *BlogRepo) GetBlogTags(blogIDs []int64) ([]models.BlogTag, error) {
+	tags := []models.BlogTag{}
+	if len(blogIDs) == 0 {
+		return tags, nil
+	}
+	query := `SELECT blog_id, tag FROM blog_tags WHERE blog_id = ANY($1) ORDER BY blog_id, tag`
+	err := r.db.Select(&tags, query, pq.Array(blogIDs))
+	if err != nil {
+		log.Printf("Error getting tags of %d blogs: %v", len(blogIDs), err)
+	}
+	return tags, err
+}
+
+// GetBlogStats returns the engagement numbers of several blogs in one query.
+func (r *BlogRepo) GetBlogStats(blogIDs []int64) ([]models.BlogStats, error) {
+	stats := []models.BlogStats{}
+	if len(blogIDs) == 0 {
+		return stats, nil
+	}
+	query := `
+		SELECT b.id AS blog_id, b.view_count AS views, b.word_count AS words, b.reading_time,
+			(SELECT COUNT(*) FROM blog_reactions br WHERE br.blog_id = b.id) AS reactions,
+			(SELECT COUNT(*) FROM bookmarks bm WHERE bm.blog_id = b.id) AS bookmarks,
+			(SELECT COUNT(*) FROM reading_list_items i WHERE i.blog_id = b.id) AS reading_lists
+		FROM blogs b
+		WHERE b.id = ANY($1)`
+	err := r.db.Select(&stats, query, pq.Array(blogIDs))
+	if err != nil {
+		log.Printf("Error getting stats of %d blogs: %v", len(blogIDs), err)
+	}
+	return stats, err
+}
+
+// GetRelatedBlogIDs returns the IDs of up to limit public posts related to
+// a blog, best match first. A candidate scores 3 per shared tag, ten times
+// the text rank of its search_vector against the blog's most frequent
+// terms, and 1 for having the same author.
+func (r *BlogRepo) GetRelatedBlogIDs(blogID int64, limit int) ([]int64, error) {
+	ids := []int64{}
+	query := `
+		WITH src AS (
+			SELECT s.id, s.user_id, to_tsquery('simple', COALESCE((
+				SELECT string_agg(quote_literal(top.lexeme), ' | ')
+				FROM (
+					SELECT lexeme FROM unnest(s.search_vector)
+					ORDER BY array_length(positions, 1) DESC NULLS LAST
+					LIMIT 24
+				) top
+			), '')) AS terms
+			FROM blogs s
+			WHERE s.id = $1
+		)
+		SELECT b.id
+		FROM blogs b, src
+		WHERE b.id <> src.id AND ` + publicBlogFilter + `
+			AND (b.search_vector @@ src.terms OR b.user_id = src.user_id OR EXISTS (
+				SELECT 1 FROM blog_tags t JOIN blog_tags st ON st.tag = t.tag
+				WHERE t.blog_id = b.id AND st.blog_id = src.id))
+		ORDER BY
+			3 * (SELECT COUNT(*) FROM blog_tags t JOIN blog_tags st ON st.tag = t.tag
+				WHERE t.blog_id = b.id AND st.blog_id = src.id)
+			+ 10 * COALESCE(ts_rank(b.search_vector, src.terms), 0)
+			+ CASE WHEN b.user_id = src.user_id THEN 1 ELSE 0 END DESC,
+			b.published_at DESC
+		LIMIT $2`
+
+	err := r.db.Select(&ids, query, blogID, limit)
+	if err != nil {
+		log.Printf("Error getting blogs related to blog %d: %v", blogID, err)
+	}
+	return ids, err
+}
+
+// GetPublicBlogsByIDs loads the posts among ids that anyone may currently
+// find, in the order of ids.
+func (r *BlogRepo) GetPublicBlogsByIDs(ids []int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	if len(ids) == 0 {
+		return blogs, nil
+	}
+	query := `
+		SELECT ` + blogSummaryColumns + `
+		FROM blogs b
+		JOIN users u ON u.id = b.user_id
+		WHERE b.id = ANY($1) AND ` + publicBlogFilter + `
+		ORDER BY array_position($1, b.id)`
+
+	err := r.db.Select(&blogs, query, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error getting %d public blogs: %v", len(ids), err)
+	}
+	return blogs, err
+}
+
+// GetBlogContents returns the content of several blogs in one query.
+func (r *BlogRepo) GetBlogContents(blogIDs []int64) ([]models.BlogContent, error) {
+	contents := []models.BlogContent{}
+	if len(blogIDs) == 0 {
+		return contents, nil
+	}
+	query := `SELECT id AS blog_id, content FROM blogs WHERE id = ANY($1)`
+	err := r.db.Select(&contents, query, pq.Array(blogIDs))
+	if err != nil {
+		log.Printf("Error getting content of %d blogs: %v", len(blogIDs), err)
+	}
+	return contents, err
+}
@@ -0,0 +1,310 @@
+package repo
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// openTestDB loads db/schema.sql into a fresh Postgres schema and returns a
+// connection that uses it. The tests are skipped unless TEST_DATABASE_URL
+// points at a database they may create schemas in.
+func openTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connecting to test database: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("blog_test_%d", time.Now().UnixNano())
+	admin.MustExec(`CREATE SCHEMA ` + schema)
+	t.Cleanup(func() { admin.MustExec(`DROP SCHEMA ` + schema + ` CASCADE`) })
+
+	// schema.sql drops and recreates its tables, so it must only ever see
+	// the test schema
+	if strings.Contains(dsn, "://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			t.Fatalf("parsing TEST_DATABASE_URL: %v", err)
+		}
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		dsn = u.String()
+	} else {
+		dsn += " search_path=" + schema
+	}
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatalf("connecting to test schema: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ddl, err := os.ReadFile("../../db/schema.sql")
+	if err != nil {
+		t.Fatalf("reading schema: %v", err)
+	}
+	if _, err := db.Exec(string(ddl)); err != nil {
+		t.Fatalf("loading schema: %v", err)
+	}
+	return db
+}
+
+// visibilityFixture is an author with one post of each kind, a follower of
+// the author and a stranger. Both readers bookmarked and liked every post,
+// and saved them all to a public reading list.
+type visibilityFixture struct {
+	author, follower, stranger int64
+	followerList, strangerList int64
+}
+
+func newVisibilityFixture(t *testing.T, db *sqlx.DB) visibilityFixture {
+	t.Helper()
+	var f visibilityFixture
+	for _, u := range []struct {
+		name string
+		id   *int64
+	}{{"author", &f.author}, {"follower", &f.follower}, {"stranger", &f.stranger}} {
+		if err := db.Get(u.id, `INSERT INTO users (username, password) VALUES ($1, '') RETURNING id`, u.name); err != nil {
+			t.Fatalf("creating user %s: %v", u.name, err)
+		}
+	}
+	db.MustExec(`INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2)`, f.follower, f.author)
+	lists := map[int64]int64{}
+	for _, reader := range []int64{f.follower, f.stranger} {
+		var id int64
+		if err := db.Get(&id, `INSERT INTO reading_lists (user_id, name, is_public) VALUES ($1, 'saved', TRUE) RETURNING id`, reader); err != nil {
+			t.Fatalf("creating reading list: %v", err)
+		}
+		lists[reader] = id
+	}
+	f.followerList, f.strangerList = lists[f.follower], lists[f.stranger]
+
+	posts := []struct {
+		title, status, visibility string
+		trashed                   bool
+	}{
+		{"public", "published", "public", false},
+		{"unlisted", "published", "unlisted", false},
+		{"followers", "published", "followers", false},
+		{"private", "published", "private", false},
+		{"password", "published", "password", false},
+		{"draft", "draft", "public", false},
+		{"trashed", "published", "public", true},
+	}
+	for i, p := range posts {
+		var id int64
+		err := db.Get(&id, `
+			INSERT INTO blogs (user_id, title, content, status, visibility, deleted_at, created_at)
+			VALUES ($1, $2, 'a post about zebras', $3, $4, CASE WHEN $5::boolean THEN NOW() END, NOW() - $6::int * INTERVAL '1 minute')
+			RETURNING id`, f.author, p.title, p.status, p.visibility, p.trashed, i)
+		if err != nil {
+			t.Fatalf("creating blog %s: %v", p.title, err)
+		}
+		for _, reader := range []int64{f.follower, f.stranger} {
+			db.MustExec(`INSERT INTO bookmarks (user_id, blog_id) VALUES ($1, $2)`, reader, id)
+			db.MustExec(`INSERT INTO blog_reactions (blog_id, user_id, reaction) VALUES ($1, $2, 'like')`, id, reader)
+			db.MustExec(`INSERT INTO reading_list_items (list_id, blog_id, position) VALUES ($1, $2, $3)`, lists[reader], id, i)
+		}
+	}
+	return f
+}
+
+func titles(blogs []models.Blog) []string {
+	names := make([]string, 0, len(blogs))
+	for _, b := range blogs {
+		names = append(names, b.Title)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestBlogVisibilityFilters(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	blogs := NewBlogRepo(db)
+
+	everything := []string{"draft", "followers", "password", "private", "public", "unlisted"}
+	tests := []struct {
+		name  string
+		query func() ([]models.Blog, error)
+		want  []string
+	}{
+		{"listing, anonymous", func() ([]models.Blog, error) { return blogs.GetAllBlogs(0, 50, 0) }, []string{"public"}},
+		{"listing, stranger", func() ([]models.Blog, error) { return blogs.GetAllBlogs(f.stranger, 50, 0) }, []string{"public"}},
+		{"listing, follower", func() ([]models.Blog, error) { return blogs.GetAllBlogs(f.follower, 50, 0) }, []string{"followers", "public"}},
+		{"search, anonymous", func() ([]models.Blog, error) { return blogs.SearchBlogs("zebras", 0) }, []string{"public"}},
+		{"search, follower", func() ([]models.Blog, error) { return blogs.SearchBlogs("zebras", f.follower) }, []string{"followers", "public"}},
+		{"author page, stranger", func() ([]models.Blog, error) { return blogs.GetBlogsByAuthor(f.author, f.stranger, true) }, []string{"public"}},
+		{"author page, follower", func() ([]models.Blog, error) { return blogs.GetBlogsByAuthor(f.author, f.follower, true) }, []string{"followers", "public"}},
+		{"author's own posts", func() ([]models.Blog, error) { return blogs.GetBlogsByAuthor(f.author, f.author, false) }, everything},
+		{"bookmarks, stranger", func() ([]models.Blog, error) { return blogs.GetBookmarkedBlogs(f.stranger, 50, 0) }, []string{"public", "unlisted"}},
+		{"bookmarks, follower", func() ([]models.Blog, error) { return blogs.GetBookmarkedBlogs(f.follower, 50, 0) }, []string{"followers", "public", "unlisted"}},
+		{"liked, stranger", func() ([]models.Blog, error) { return blogs.GetBlogsReactedByUser(f.stranger, "like", 50, 0) }, []string{"public", "unlisted"}},
+		{"liked, follower", func() ([]models.Blog, error) { return blogs.GetBlogsReactedByUser(f.follower, "like", 50, 0) }, []string{"followers", "public", "unlisted"}},
+		{"reading list, stranger", func() ([]models.Blog, error) { return blogs.GetReadingListBlogs(f.strangerList, f.stranger) }, []string{"public", "unlisted"}},
+		{"reading list, follower", func() ([]models.Blog, error) { return blogs.GetReadingListBlogs(f.followerList, f.follower) }, []string{"followers", "public", "unlisted"}},
+		{"follower's list, anonymous", func() ([]models.Blog, error) { return blogs.GetReadingListBlogs(f.followerList, 0) }, []string{"public", "unlisted"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.query()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := titles(result)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBlogVisibilityAfterUnfollow(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	blogs := NewBlogRepo(db)
+
+	db.MustExec(`DELETE FROM follows WHERE follower_id = $1`, f.follower)
+	result, err := blogs.GetBookmarkedBlogs(f.follower, 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := titles(result)
+	if want := []string{"public", "unlisted"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("bookmarks after unfollowing: got %v, want %v", got, want)
+	}
+}
+
+func TestBlogTrash(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	blogs := NewBlogRepo(db)
+
+	idOf := func(title string) int64 {
+		t.Helper()
+		var id int64
+		if err := db.Get(&id, `SELECT id FROM blogs WHERE user_id = $1 AND title = $2`, f.author, title); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	trashed, public := idOf("trashed"), idOf("public")
+
+	if _, err := blogs.GetBlogByID(trashed); err == nil {
+		t.Error("GetBlogByID returned a trashed post")
+	}
+	trash, err := blogs.GetTrashedBlogs(f.author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(trash); !reflect.DeepEqual(got, []string{"trashed"}) {
+		t.Errorf("trash = %v, want [trashed]", got)
+	}
+
+	if err := blogs.RestoreBlog(trashed, f.stranger); err == nil || !strings.Contains(err.Error(), "no trashed blog found") {
+		t.Errorf("stranger restoring: err = %v", err)
+	}
+	if err := blogs.PurgeBlog(public, f.author); err == nil || !strings.Contains(err.Error(), "no trashed blog found") {
+		t.Errorf("purging a live post: err = %v", err)
+	}
+	if err := blogs.RestoreBlog(trashed, f.author); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := blogs.GetAllBlogs(0, 50, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(listed); !reflect.DeepEqual(got, []string{"public", "trashed"}) {
+		t.Errorf("listing after restoring = %v, want [public trashed]", got)
+	}
+
+	if err := blogs.DeleteBlog(trashed, f.author); err != nil {
+		t.Fatal(err)
+	}
+	if err := blogs.PurgeBlog(trashed, f.author); err != nil {
+		t.Fatal(err)
+	}
+	var left int
+	if err := db.Get(&left, `SELECT COUNT(*) FROM blogs WHERE id = $1`, trashed); err != nil {
+		t.Fatal(err)
+	}
+	if left != 0 {
+		t.Error("purged post still stored")
+	}
+
+	// Only posts trashed before the cutoff are purged
+	db.MustExec(`UPDATE blogs SET deleted_at = NOW() - INTERVAL '30 days' WHERE id = $1`, public)
+	n, err := blogs.PurgeTrashedBlogs(time.Now().Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("purged %d posts, want 1", n)
+	}
+}
+
+func TestRelatedBlogsArePublic(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	blogs := NewBlogRepo(db)
+
+	ids := map[string]int64{}
+	rows, err := db.Queryx(`SELECT title, id FROM blogs WHERE user_id = $1`, f.author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var all []int64
+	for rows.Next() {
+		var title string
+		var id int64
+		if err := rows.Scan(&title, &id); err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = id
+		all = append(all, id)
+	}
+	rows.Close()
+
+	// Every post shares its author and text with the others, so only the
+	// visibility filter keeps them out
+	for _, source := range []string{"unlisted", "followers", "private", "password", "draft", "trashed"} {
+		related, err := blogs.GetRelatedBlogIDs(ids[source], 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(related) != 1 || related[0] != ids["public"] {
+			t.Errorf("related to %s = %v, want only the public post %d", source, related, ids["public"])
+		}
+	}
+	related, err := blogs.GetRelatedBlogIDs(ids["public"], 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(related) != 0 {
+		t.Errorf("related to public = %v, want none", related)
+	}
+
+	loaded, err := blogs.GetPublicBlogsByIDs(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(loaded); !reflect.DeepEqual(got, []string{"public"}) {
+		t.Errorf("GetPublicBlogsByIDs = %v, want [public]", got)
+	}
+}
@@ -0,0 +1,64 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type BookmarkRepo struct {
+	db *sqlx.DB
+}
+
+func NewBookmarkRepo(db *sqlx.DB) *BookmarkRepo {
+	return &BookmarkRepo{db: db}
+}
+
+// AddBookmark saves a blog for later; it reports false if it already was.
+func (r *BookmarkRepo) AddBookmark(userID, blogID int64) (bool, error) {
+	query := `
+		INSERT INTO bookmarks (user_id, blog_id)
+		VALUES($1, $2)
+		ON CONFLICT DO NOTHING`
+	result, err := r.db.Exec(query, userID, blogID)
+	if err != nil {
+		log.Printf("Error bookmarking blog %d for user %d: %v", blogID, userID, err)
+		return false, fmt.Errorf("failed to add bookmark: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *BookmarkRepo) RemoveBookmark(userID, blogID int64) error {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND blog_id = $2`
+	result, err := r.db.Exec(query, userID, blogID)
+	if err != nil {
+		log.Printf("Error removing bookmark on blog %d for user %d: %v", blogID, userID, err)
+		return fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed bookmark: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no bookmark found for blog %d and user %d", blogID, userID)
+	}
+	return nil
+}
+
+// GetBookmarkedIDs returns which of blogIDs the user has bookmarked.
+func (r *BookmarkRepo) GetBookmarkedIDs(userID int64, blogIDs []int64) ([]int64, error) {
+	ids := []int64{}
+	if len(blogIDs) == 0 {
+		return ids, nil
+	}
+	query := `SELECT blog_id FROM bookmarks WHERE user_id = $1 AND blog_id = ANY($2)`
+	err := r.db.Select(&ids, query, userID, pq.Array(blogIDs))
+	if err != nil {
+		log.Printf("Error checking bookmarks for user %d: %v", userID, err)
+	}
+	return ids, err
+}
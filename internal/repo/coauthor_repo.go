@@ -0,0 +1,116 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type CoAuthorRepo struct {
+	db *sqlx.DB
+}
+
+func NewCoAuthorRepo(db *sqlx.DB) *CoAuthorRepo {
+	return &CoAuthorRepo{db: db}
+}
+
+// InviteCoAuthor invites a user to co-author a blog. Inviting an existing
+// co-author again only changes their role.
+func (r *CoAuthorRepo) InviteCoAuthor(blogID, userID int64, role string, invitedBy int64) error {
+	query := `
+		INSERT INTO blog_authors (blog_id, user_id, role, invited_by)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT (blog_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+	if _, err := r.db.Exec(query, blogID, userID, role, invitedBy); err != nil {
+		log.Printf("Error inviting user %d to co-author blog %d: %v", userID, blogID, err)
+		return fmt.Errorf("failed to invite co-author: %w", err)
+	}
+	return nil
+}
+
+// GetCoAuthor returns a user's invite or membership on a blog, or
+// sql.ErrNoRows if there is none.
+func (r *CoAuthorRepo) GetCoAuthor(blogID, userID int64) (*models.BlogCoAuthor, error) {
+	var coAuthor models.BlogCoAuthor
+	query := `
+		SELECT ba.blog_id, ba.user_id, u.username, u.display_name, ba.role,
+			ba.invited_by, ba.created_at, ba.accepted_at
+		FROM blog_authors ba JOIN users u ON u.id = ba.user_id
+		WHERE ba.blog_id = $1 AND ba.user_id = $2`
+	if err := r.db.Get(&coAuthor, query, blogID, userID); err != nil {
+		return nil, err
+	}
+	return &coAuthor, nil
+}
+
+// GetCoAuthorsByBlog lists a blog's co-authors, pending invites included.
+func (r *CoAuthorRepo) GetCoAuthorsByBlog(blogID int64) ([]models.BlogCoAuthor, error) {
+	coAuthors := []models.BlogCoAuthor{}
+	query := `
+		SELECT ba.blog_id, ba.user_id, u.username, u.display_name, ba.role,
+			ba.invited_by, ba.created_at, ba.accepted_at
+		FROM blog_authors ba JOIN users u ON u.id = ba.user_id
+		WHERE ba.blog_id = $1
+		ORDER BY ba.created_at`
+	err := r.db.Select(&coAuthors, query, blogID)
+	if err != nil {
+		log.Printf("Error getting co-authors of blog %d: %v", blogID, err)
+	}
+	return coAuthors, err
+}
+
+// GetPendingInvites lists co-author invites the user has not answered yet.
+func (r *CoAuthorRepo) GetPendingInvites(userID int64) ([]models.CoAuthorInvite, error) {
+	invites := []models.CoAuthorInvite{}
+	query := `
+		SELECT ba.blog_id, b.title AS blog_title, ba.role,
+			COALESCE(inviter.username, '') AS invited_by, ba.created_at
+		FROM blog_authors ba
+		JOIN blogs b ON b.id = ba.blog_id
+		LEFT JOIN users inviter ON inviter.id = ba.invited_by
+		WHERE ba.user_id = $1 AND ba.accepted_at IS NULL AND ` + liveBlogFilter + `
+		ORDER BY ba.created_at DESC`
+	err := r.db.Select(&invites, query, userID)
+	if err != nil {
+		log.Printf("Error getting co-author invites for user %d: %v", userID, err)
+	}
+	return invites, err
+}
+
+func (r *CoAuthorRepo) AcceptInvite(blogID, userID int64) error {
+	query := `UPDATE blog_authors SET accepted_at = NOW() WHERE blog_id = $1 AND user_id = $2 AND accepted_at IS NULL`
+	result, err := r.db.Exec(query, blogID, userID)
+	if err != nil {
+		log.Printf("Error accepting co-author invite on blog %d: %v", blogID, err)
+		return fmt.Errorf("failed to accept invite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check accepted invite: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no co-author invite found for blog %d and user %d", blogID, userID)
+	}
+	return nil
+}
+
+// RemoveCoAuthor deletes a co-author or a pending invite.
+func (r *CoAuthorRepo) RemoveCoAuthor(blogID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM blog_authors WHERE blog_id = $1 AND user_id = $2`, blogID, userID)
+	if err != nil {
+		log.Printf("Error removing user %d from blog %d: %v", userID, blogID, err)
+		return fmt.Errorf("failed to remove co-author: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed co-author: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no co-author found for blog %d and user %d", blogID, userID)
+	}
+	return nil
+}
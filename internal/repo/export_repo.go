@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const exportColumns = `id, user_id, status, file_path, size_bytes, token_hash, created_at, completed_at, expires_at`
+
+type ExportRepo struct {
+	db *sqlx.DB
+}
+
+func NewExportRepo(db *sqlx.DB) *ExportRepo {
+	return &ExportRepo{db: db}
+}
+
+func (r *ExportRepo) CreateExport(export *models.DataExport) error {
+	query := `
+		INSERT INTO data_exports (user_id, status)
+		VALUES($1, $2)
+		RETURNING id, created_at`
+	return r.db.QueryRow(query, export.UserID, export.Status).Scan(&export.ID, &export.CreatedAt)
+}
+
+// GetLatestExport returns the user's most recently requested export.
+func (r *ExportRepo) GetLatestExport(userID int64) (*models.DataExport, error) {
+	var export models.DataExport
+	query := `SELECT ` + exportColumns + ` FROM data_exports WHERE user_id = $1 ORDER BY created_at DESC LIMIT 1`
+	if err := r.db.Get(&export, query, userID); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+// GetExportByTokenHash returns a ready, unexpired export by its download token.
+func (r *ExportRepo) GetExportByTokenHash(tokenHash string) (*models.DataExport, error) {
+	var export models.DataExport
+	query := `
+		SELECT ` + exportColumns + ` FROM data_exports
+		WHERE token_hash = $1 AND status = 'ready' AND expires_at > NOW()`
+	if err := r.db.Get(&export, query, tokenHash); err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *ExportRepo) MarkExportReady(exportID int64, filePath string, size int64, tokenHash string, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = 'ready', file_path = $2, size_bytes = $3, token_hash = $4,
+		    completed_at = NOW(), expires_at = $5
+		WHERE id = $1`
+	if _, err := r.db.Exec(query, exportID, filePath, size, tokenHash, expiresAt); err != nil {
+		log.Printf("Error marking export %d ready: %v", exportID, err)
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
+
+func (r *ExportRepo) MarkExportFailed(exportID int64) error {
+	query := `UPDATE data_exports SET status = 'failed', completed_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(query, exportID); err != nil {
+		log.Printf("Error marking export %d failed: %v", exportID, err)
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
+
+// FailStaleExports marks exports still pending after maxAge as failed, e.g.
+// because the server restarted while building them.
+func (r *ExportRepo) FailStaleExports(maxAge time.Duration) error {
+	query := `
+		UPDATE data_exports SET status = 'failed', completed_at = NOW()
+		WHERE status = 'pending' AND created_at < $1`
+	if _, err := r.db.Exec(query, time.Now().Add(-maxAge)); err != nil {
+		return fmt.Errorf("failed to expire stale exports: %w", err)
+	}
+	return nil
+}
+
+// GetExpiredExports returns ready exports whose download link has expired.
+func (r *ExportRepo) GetExpiredExports() ([]models.DataExport, error) {
+	exports := []models.DataExport{}
+	query := `SELECT ` + exportColumns + ` FROM data_exports WHERE status = 'ready' AND expires_at <= NOW()`
+	err := r.db.Select(&exports, query)
+	return exports, err
+}
+
+func (r *ExportRepo) MarkExportExpired(exportID int64) error {
+	query := `UPDATE data_exports SET status = 'expired', file_path = '', token_hash = NULL WHERE id = $1`
+	if _, err := r.db.Exec(query, exportID); err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type FollowRepo struct {
+	db *sqlx.DB
+}
+
+func NewFollowRepo(db *sqlx.DB) *FollowRepo {
+	return &FollowRepo{db: db}
+}
+
+// Follow makes followerID follow followeeID; following twice is a no-op.
+func (r *FollowRepo) Follow(followerID, followeeID int64) error {
+	query := `
+		INSERT INTO follows (follower_id, followee_id)
+		VALUES($1, $2)
+		ON CONFLICT (follower_id, followee_id) DO NOTHING`
+	if _, err := r.db.Exec(query, followerID, followeeID); err != nil {
+		log.Printf("Error making user %d follow user %d: %v", followerID, followeeID, err)
+		return fmt.Errorf("failed to follow user: %w", err)
+	}
+	return nil
+}
+
+func (r *FollowRepo) Unfollow(followerID, followeeID int64) error {
+	result, err := r.db.Exec(`DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	if err != nil {
+		log.Printf("Error making user %d unfollow user %d: %v", followerID, followeeID, err)
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check unfollow: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no follow found for user %d and user %d", followerID, followeeID)
+	}
+	return nil
+}
+
+func (r *FollowRepo) IsFollowing(followerID, followeeID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)`
+	if err := r.db.Get(&exists, query, followerID, followeeID); err != nil {
+		log.Printf("Error checking whether user %d follows user %d: %v", followerID, followeeID, err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetFollowing lists the users userID follows, most recently followed first.
+func (r *FollowRepo) GetFollowing(userID int64) ([]models.Author, error) {
+	users := []models.Author{}
+	query := `
+		SELECT u.id, u.username, u.display_name, u.avatar_url
+		FROM follows f JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC`
+	err := r.db.Select(&users, query, userID)
+	if err != nil {
+		log.Printf("Error getting users followed by user %d: %v", userID, err)
+	}
+	return users, err
+}
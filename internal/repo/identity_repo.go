@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type IdentityRepo struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepo(db *sqlx.DB) *IdentityRepo {
+	return &IdentityRepo{db: db}
+}
+
+func (r *IdentityRepo) CreateIdentity(identity *models.UserIdentity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES($1, $2, $3, $4)
+		RETURNING id, created_at`
+	return r.db.QueryRow(
+		query, identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	).Scan(&identity.ID, &identity.CreatedAt)
+}
+
+func (r *IdentityRepo) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2`
+	if err := r.db.Get(&identity, query, provider, subject); err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepo) GetIdentitiesByUserID(userID int64) ([]models.UserIdentity, error) {
+	identities := []models.UserIdentity{}
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at`
+	err := r.db.Select(&identities, query, userID)
+	if err != nil {
+		log.Printf("Error getting identities for user %d: %v", userID, err)
+	}
+	return identities, err
+}
+
+func (r *IdentityRepo) DeleteIdentity(identityID, userID int64) error {
+	query := `
+		DELETE FROM user_identities
+		WHERE id = $1 AND user_id = $2`
+
+	result, err := r.db.Exec(query, identityID, userID)
+	if err != nil {
+		log.Printf("Error deleting identity %d by user %d: %v", identityID, userID, err)
+		return fmt.Errorf("failed to delete identity: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows after delete: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no identity found with ID %d for user %d", identityID, userID)
+	}
+
+	return nil
+}
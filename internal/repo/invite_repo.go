@@ -0,0 +1,97 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type InviteRepo struct {
+	db *sqlx.DB
+}
+
+func NewInviteRepo(db *sqlx.DB) *InviteRepo {
+	return &InviteRepo{db: db}
+}
+
+func (r *InviteRepo) CreateInvite(invite *models.Invite) error {
+	query := `
+		INSERT INTO invites (created_by, code_hash, code_prefix, max_uses, expires_at)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	return r.db.QueryRow(
+		query, invite.CreatedBy, invite.CodeHash, invite.CodePrefix, invite.MaxUses, invite.ExpiresAt,
+	).Scan(&invite.ID, &invite.CreatedAt)
+}
+
+// GetInvitesByCreator lists a user's invites with the usernames that
+// registered through each one.
+func (r *InviteRepo) GetInvitesByCreator(userID int64) ([]models.Invite, error) {
+	invites := []models.Invite{}
+	query := `
+		SELECT i.id, i.created_by, i.code_prefix, i.max_uses, i.use_count,
+		       i.expires_at, i.revoked_at, i.created_at,
+		       COALESCE(array_agg(u.username ORDER BY u.id) FILTER (WHERE u.id IS NOT NULL), '{}') AS invitees
+		FROM invites i
+		LEFT JOIN users u ON u.invite_id = i.id
+		WHERE i.created_by = $1
+		GROUP BY i.id
+		ORDER BY i.created_at DESC`
+	err := r.db.Select(&invites, query, userID)
+	if err != nil {
+		log.Printf("Error getting invites for user %d: %v", userID, err)
+	}
+	return invites, err
+}
+
+// CountActiveInvites counts a user's invites that can still be used.
+func (r *InviteRepo) CountActiveInvites(userID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM invites
+		WHERE created_by = $1 AND revoked_at IS NULL AND use_count < max_uses
+		  AND (expires_at IS NULL OR expires_at > NOW())`
+	err := r.db.Get(&count, query, userID)
+	return count, err
+}
+
+// UseInvite atomically records one use of a valid invite and returns it.
+func (r *InviteRepo) UseInvite(codeHash string) (*models.Invite, error) {
+	var invite models.Invite
+	query := `
+		UPDATE invites SET use_count = use_count + 1
+		WHERE code_hash = $1 AND revoked_at IS NULL AND use_count < max_uses
+		  AND (expires_at IS NULL OR expires_at > NOW())
+		RETURNING id, created_by, code_prefix, max_uses, use_count, expires_at, created_at`
+	if err := r.db.Get(&invite, query, codeHash); err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// ReleaseInvite gives back a use taken by UseInvite when registration fails.
+func (r *InviteRepo) ReleaseInvite(inviteID int64) error {
+	query := `UPDATE invites SET use_count = use_count - 1 WHERE id = $1 AND use_count > 0`
+	_, err := r.db.Exec(query, inviteID)
+	return err
+}
+
+func (r *InviteRepo) RevokeInvite(inviteID, userID int64) error {
+	query := `UPDATE invites SET revoked_at = NOW() WHERE id = $1 AND created_by = $2 AND revoked_at IS NULL`
+	result, err := r.db.Exec(query, inviteID, userID)
+	if err != nil {
+		log.Printf("Error revoking invite %d: %v", inviteID, err)
+		return fmt.Errorf("failed to revoke invite: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check revoked invite: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no invite found with ID %d for user %d", inviteID, userID)
+	}
+	return nil
+}
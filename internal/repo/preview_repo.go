@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type PreviewRepo struct {
+	db *sqlx.DB
+}
+
+func NewPreviewRepo(db *sqlx.DB) *PreviewRepo {
+	return &PreviewRepo{db: db}
+}
+
+func (r *PreviewRepo) CreatePreviewLink(link *models.PreviewLink) error {
+	query := `
+		INSERT INTO preview_links (blog_id, created_by, token_hash, token_prefix, expires_at)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	return r.db.QueryRow(
+		query, link.BlogID, link.CreatedBy, link.TokenHash, link.TokenPrefix, link.ExpiresAt,
+	).Scan(&link.ID, &link.CreatedAt)
+}
+
+// GetPreviewLinksByBlog lists a blog's preview links, newest first.
+func (r *PreviewRepo) GetPreviewLinksByBlog(blogID int64) ([]models.PreviewLink, error) {
+	links := []models.PreviewLink{}
+	query := `
+		SELECT id, blog_id, created_by, token_prefix, expires_at, revoked_at,
+		       access_count, last_accessed_at, created_at
+		FROM preview_links
+		WHERE blog_id = $1
+		ORDER BY created_at DESC`
+	err := r.db.Select(&links, query, blogID)
+	if err != nil {
+		log.Printf("Error getting preview links of blog %d: %v", blogID, err)
+	}
+	return links, err
+}
+
+// CountActivePreviewLinks counts a blog's links that can still be opened.
+func (r *PreviewRepo) CountActivePreviewLinks(blogID int64) (int, error) {
+	var count int
+	query := `
+		SELECT COUNT(*) FROM preview_links
+		WHERE blog_id = $1 AND revoked_at IS NULL AND expires_at > NOW()`
+	err := r.db.Get(&count, query, blogID)
+	return count, err
+}
+
+// UsePreviewLink records one access through a valid link and returns it,
+// or sql.ErrNoRows when the link is unknown, revoked or expired, or its post
+// is in the trash; such opens are not counted.
+func (r *PreviewRepo) UsePreviewLink(tokenHash string) (*models.PreviewLink, error) {
+	var link models.PreviewLink
+	query := `
+		UPDATE preview_links
+		SET access_count = access_count + 1, last_accessed_at = NOW()
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		AND EXISTS (SELECT 1 FROM blogs b WHERE b.id = preview_links.blog_id AND ` + liveBlogFilter + `)
+		RETURNING id, blog_id, created_by, token_prefix, expires_at, access_count, last_accessed_at, created_at`
+	if err := r.db.Get(&link, query, tokenHash); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r *PreviewRepo) RevokePreviewLink(blogID, linkID int64) error {
+	query := `UPDATE preview_links SET revoked_at = NOW() WHERE id = $1 AND blog_id = $2 AND revoked_at IS NULL`
+	result, err := r.db.Exec(query, linkID, blogID)
+	if err != nil {
+		log.Printf("Error revoking preview link %d: %v", linkID, err)
+		return fmt.Errorf("failed to revoke preview link: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check revoked preview link: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no preview link found with ID %d for blog %d", linkID, blogID)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestUsePreviewLinkSkipsTrashedPosts(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	repo := NewPreviewRepo(db)
+
+	tests := []struct {
+		title string
+		want  error
+	}{
+		{"draft", nil},
+		{"trashed", sql.ErrNoRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			var blogID int64
+			if err := db.Get(&blogID, `SELECT id FROM blogs WHERE user_id = $1 AND title = $2`, f.author, tt.title); err != nil {
+				t.Fatal(err)
+			}
+			link := &models.PreviewLink{
+				BlogID:      blogID,
+				CreatedBy:   f.author,
+				TokenHash:   "hash-" + tt.title,
+				TokenPrefix: "pvw_test",
+				ExpiresAt:   time.Now().Add(time.Hour),
+			}
+			if err := repo.CreatePreviewLink(link); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := repo.UsePreviewLink(link.TokenHash); !errors.Is(err, tt.want) {
+				t.Fatalf("UsePreviewLink err = %v, want %v", err, tt.want)
+			}
+			links, err := repo.GetPreviewLinksByBlog(blogID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantCount := 1
+			if tt.want != nil {
+				wantCount = 0
+			}
+			if len(links) != 1 || links[0].AccessCount != wantCount {
+				t.Errorf("links = %+v, want one with access_count %d", links, wantCount)
+			}
+		})
+	}
+}
+
+func TestUsePreviewLinkRefusesRevokedAndExpiredLinks(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	repo := NewPreviewRepo(db)
+
+	var blogID int64
+	if err := db.Get(&blogID, `SELECT id FROM blogs WHERE user_id = $1 AND title = 'draft'`, f.author); err != nil {
+		t.Fatal(err)
+	}
+	newLink := func(hash string, expiresAt time.Time) *models.PreviewLink {
+		link := &models.PreviewLink{
+			BlogID: blogID, CreatedBy: f.author, TokenHash: hash, TokenPrefix: "pvw_test", ExpiresAt: expiresAt,
+		}
+		if err := repo.CreatePreviewLink(link); err != nil {
+			t.Fatal(err)
+		}
+		return link
+	}
+
+	revoked := newLink("hash-revoked", time.Now().Add(time.Hour))
+	if err := repo.RevokePreviewLink(blogID, revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RevokePreviewLink(blogID, revoked.ID); err == nil {
+		t.Error("revoking a link twice succeeded")
+	}
+	expired := newLink("hash-expired", time.Now().Add(-time.Minute))
+	active := newLink("hash-active", time.Now().Add(time.Hour))
+
+	for _, link := range []*models.PreviewLink{revoked, expired} {
+		if _, err := repo.UsePreviewLink(link.TokenHash); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("UsePreviewLink(%s) err = %v, want sql.ErrNoRows", link.TokenHash, err)
+		}
+	}
+	if _, err := repo.UsePreviewLink(active.TokenHash); err != nil {
+		t.Fatalf("UsePreviewLink(active): %v", err)
+	}
+
+	count, err := repo.CountActivePreviewLinks(blogID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("active links = %d, want 1", count)
+	}
+	links, err := repo.GetPreviewLinksByBlog(blogID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, link := range links {
+		want := 0
+		if link.ID == active.ID {
+			want = 1
+		}
+		if link.AccessCount != want {
+			t.Errorf("link %d access_count = %d, want %d", link.ID, link.AccessCount, want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// publicationColumns selects a publication with its member count; queries
+// using it must alias publications as p.
+const publicationColumns = `
+	p.id, p.name, p.slug, p.description, p.created_by, p.created_at, p.updated_at,
+	(SELECT COUNT(*) FROM publication_members pm WHERE pm.publication_id = p.id) AS member_count`
+
+type PublicationRepo struct {
+	db *sqlx.DB
+}
+
+func NewPublicationRepo(db *sqlx.DB) *PublicationRepo {
+	return &PublicationRepo{db: db}
+}
+
+// CreatePublication inserts a publication and makes its creator the owner
+// in one transaction.
+func (r *PublicationRepo) CreatePublication(pub *models.Publication, ownerID int64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO publications (name, slug, description, created_by)
+		VALUES($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+	if err := tx.QueryRow(query, pub.Name, pub.Slug, pub.Description, ownerID).
+		Scan(&pub.ID, &pub.CreatedAt, &pub.UpdatedAt); err != nil {
+		log.Printf("Error creating publication %q: %v", pub.Slug, err)
+		return fmt.Errorf("failed to create publication: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO publication_members (publication_id, user_id, role) VALUES($1, $2, 'owner')`, pub.ID, ownerID); err != nil {
+		log.Printf("Error adding owner to publication %d: %v", pub.ID, err)
+		return fmt.Errorf("failed to add publication owner: %w", err)
+	}
+
+	pub.CreatedBy = &ownerID
+	pub.MemberCount = 1
+	return tx.Commit()
+}
+
+func (r *PublicationRepo) GetPublicationBySlug(slug string) (*models.Publication, error) {
+	var pub models.Publication
+	query := `SELECT ` + publicationColumns + ` FROM publications p WHERE p.slug = $1`
+	if err := r.db.Get(&pub, query, slug); err != nil {
+		return nil, err
+	}
+	return &pub, nil
+}
+
+func (r *PublicationRepo) GetPublicationByID(id int64) (*models.Publication, error) {
+	var pub models.Publication
+	query := `SELECT ` + publicationColumns + ` FROM publications p WHERE p.id = $1`
+	if err := r.db.Get(&pub, query, id); err != nil {
+		return nil, err
+	}
+	return &pub, nil
+}
+
+// SlugExists reports whether a publication already uses slug.
+func (r *PublicationRepo) SlugExists(slug string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `SELECT EXISTS(SELECT 1 FROM publications WHERE slug = $1)`, slug)
+	return exists, err
+}
+
+// GetPublicationsByMember lists the publications a user belongs to.
+func (r *PublicationRepo) GetPublicationsByMember(userID int64) ([]models.Publication, error) {
+	pubs := []models.Publication{}
+	query := `
+		SELECT ` + publicationColumns + `
+		FROM publications p
+		JOIN publication_members m ON m.publication_id = p.id
+		WHERE m.user_id = $1
+		ORDER BY p.name`
+	err := r.db.Select(&pubs, query, userID)
+	if err != nil {
+		log.Printf("Error getting publications for user %d: %v", userID, err)
+	}
+	return pubs, err
+}
+
+func (r *PublicationRepo) UpdatePublication(pub *models.Publication) error {
+	query := `
+		UPDATE publications SET name = $1, description = $2
+		WHERE id = $3
+		RETURNING updated_at`
+	if err := r.db.QueryRow(query, pub.Name, pub.Description, pub.ID).Scan(&pub.UpdatedAt); err != nil {
+		log.Printf("Error updating publication %d: %v", pub.ID, err)
+		return fmt.Errorf("failed to update publication: %w", err)
+	}
+	return nil
+}
+
+// DeletePublication removes a publication; its posts become personal posts
+// of their authors.
+func (r *PublicationRepo) DeletePublication(id int64) error {
+	if _, err := r.db.Exec(`DELETE FROM publications WHERE id = $1`, id); err != nil {
+		log.Printf("Error deleting publication %d: %v", id, err)
+		return fmt.Errorf("failed to delete publication: %w", err)
+	}
+	return nil
+}
+
+// GetMemberRole returns a user's role in a publication, or sql.ErrNoRows
+// if they are not a member.
+func (r *PublicationRepo) GetMemberRole(publicationID, userID int64) (string, error) {
+	var role string
+	err := r.db.Get(&role, `SELECT role FROM publication_members WHERE publication_id = $1 AND user_id = $2`, publicationID, userID)
+	return role, err
+}
+
+func (r *PublicationRepo) GetMembers(publicationID int64) ([]models.PublicationMember, error) {
+	members := []models.PublicationMember{}
+	query := `
+		SELECT m.publication_id, m.user_id, u.username, u.display_name, m.role, m.created_at
+		FROM publication_members m JOIN users u ON u.id = m.user_id
+		WHERE m.publication_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END, m.created_at`
+	err := r.db.Select(&members, query, publicationID)
+	if err != nil {
+		log.Printf("Error getting members of publication %d: %v", publicationID, err)
+	}
+	return members, err
+}
+
+func (r *PublicationRepo) CountOwners(publicationID int64) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM publication_members WHERE publication_id = $1 AND role = 'owner'`, publicationID)
+	return count, err
+}
+
+// SetMember adds a member or changes their role.
+func (r *PublicationRepo) SetMember(publicationID, userID int64, role string) error {
+	query := `
+		INSERT INTO publication_members (publication_id, user_id, role)
+		VALUES($1, $2, $3)
+		ON CONFLICT (publication_id, user_id) DO UPDATE SET role = EXCLUDED.role`
+	if _, err := r.db.Exec(query, publicationID, userID, role); err != nil {
+		log.Printf("Error setting user %d as %s of publication %d: %v", userID, role, publicationID, err)
+		return fmt.Errorf("failed to set publication member: %w", err)
+	}
+	return nil
+}
+
+func (r *PublicationRepo) RemoveMember(publicationID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM publication_members WHERE publication_id = $1 AND user_id = $2`, publicationID, userID)
+	if err != nil {
+		log.Printf("Error removing user %d from publication %d: %v", userID, publicationID, err)
+		return fmt.Errorf("failed to remove publication member: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed member: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no member found with ID %d in publication %d", userID, publicationID)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type ReactionRepo struct {
+	db *sqlx.DB
+}
+
+func NewReactionRepo(db *sqlx.DB) *ReactionRepo {
+	return &ReactionRepo{db: db}
+}
+
+// AddReaction records a reaction; it reports false if it already existed.
+func (r *ReactionRepo) AddReaction(blogID, userID int64, reaction string) (bool, error) {
+	query := `
+		INSERT INTO blog_reactions (blog_id, user_id, reaction)
+		VALUES($1, $2, $3)
+		ON CONFLICT DO NOTHING`
+	result, err := r.db.Exec(query, blogID, userID, reaction)
+	if err != nil {
+		log.Printf("Error adding %s reaction to blog %d: %v", reaction, blogID, err)
+		return false, fmt.Errorf("failed to add reaction: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// RemoveReaction deletes a reaction; it reports false if there was none.
+func (r *ReactionRepo) RemoveReaction(blogID, userID int64, reaction string) (bool, error) {
+	query := `DELETE FROM blog_reactions WHERE blog_id = $1 AND user_id = $2 AND reaction = $3`
+	result, err := r.db.Exec(query, blogID, userID, reaction)
+	if err != nil {
+		log.Printf("Error removing %s reaction from blog %d: %v", reaction, blogID, err)
+		return false, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetReactionCounts aggregates reactions for several blogs in one query.
+func (r *ReactionRepo) GetReactionCounts(blogIDs []int64) ([]models.ReactionCount, error) {
+	counts := []models.ReactionCount{}
+	if len(blogIDs) == 0 {
+		return counts, nil
+	}
+	query := `
+		SELECT blog_id, reaction, COUNT(*) AS count
+		FROM blog_reactions
+		WHERE blog_id = ANY($1)
+		GROUP BY blog_id, reaction`
+	err := r.db.Select(&counts, query, pq.Array(blogIDs))
+	if err != nil {
+		log.Printf("Error counting reactions: %v", err)
+	}
+	return counts, err
+}
+
+// GetUserReactions returns the reaction types the user left on a blog.
+func (r *ReactionRepo) GetUserReactions(blogID, userID int64) ([]string, error) {
+	reactions := []string{}
+	query := `SELECT reaction FROM blog_reactions WHERE blog_id = $1 AND user_id = $2 ORDER BY created_at`
+	err := r.db.Select(&reactions, query, blogID, userID)
+	return reactions, err
+}
@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// readingListColumns selects a list with its item count; queries using it
+// must alias reading_lists as l.
+const readingListColumns = `
+	l.id, l.user_id, l.name, l.is_public, l.created_at, l.updated_at,
+	(SELECT COUNT(*) FROM reading_list_items i WHERE i.list_id = l.id) AS item_count`
+
+type ReadingListRepo struct {
+	db *sqlx.DB
+}
+
+func NewReadingListRepo(db *sqlx.DB) *ReadingListRepo {
+	return &ReadingListRepo{db: db}
+}
+
+func (r *ReadingListRepo) CreateList(list *models.ReadingList) error {
+	query := `
+		INSERT INTO reading_lists (user_id, name, is_public)
+		VALUES($1, $2, $3)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRow(query, list.UserID, list.Name, list.IsPublic).
+		Scan(&list.ID, &list.CreatedAt, &list.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating reading list for user %d: %v", list.UserID, err)
+		return fmt.Errorf("failed to create reading list: %w", err)
+	}
+	return nil
+}
+
+func (r *ReadingListRepo) GetListByID(id int64) (*models.ReadingList, error) {
+	var list models.ReadingList
+	query := `SELECT ` + readingListColumns + ` FROM reading_lists l WHERE l.id = $1`
+	if err := r.db.Get(&list, query, id); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// GetListsByUser returns the user's lists, only the public ones when
+// publicOnly is set.
+func (r *ReadingListRepo) GetListsByUser(userID int64, publicOnly bool) ([]models.ReadingList, error) {
+	lists := []models.ReadingList{}
+	query := `
+		SELECT ` + readingListColumns + `
+		FROM reading_lists l
+		WHERE l.user_id = $1 AND (l.is_public OR NOT $2)
+		ORDER BY l.created_at`
+	err := r.db.Select(&lists, query, userID, publicOnly)
+	if err != nil {
+		log.Printf("Error getting reading lists for user %d: %v", userID, err)
+	}
+	return lists, err
+}
+
+func (r *ReadingListRepo) CountLists(userID int64) (int, error) {
+	var count int
+	err := r.db.Get(&count, `SELECT COUNT(*) FROM reading_lists WHERE user_id = $1`, userID)
+	return count, err
+}
+
+// UpdateList saves the name and visibility of a list owned by list.UserID.
+func (r *ReadingListRepo) UpdateList(list *models.ReadingList) error {
+	query := `
+		UPDATE reading_lists SET name = $1, is_public = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING updated_at`
+	err := r.db.QueryRow(query, list.Name, list.IsPublic, list.ID, list.UserID).Scan(&list.UpdatedAt)
+	if err != nil {
+		log.Printf("Error updating reading list %d: %v", list.ID, err)
+		return fmt.Errorf("failed to update reading list: %w", err)
+	}
+	return nil
+}
+
+func (r *ReadingListRepo) DeleteList(listID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM reading_lists WHERE id = $1 AND user_id = $2`, listID, userID)
+	if err != nil {
+		log.Printf("Error deleting reading list %d: %v", listID, err)
+		return fmt.Errorf("failed to delete reading list: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted reading list: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no reading list found with ID %d for user %d", listID, userID)
+	}
+	return nil
+}
+
+// AddItem appends a blog to the end of a list; it reports false if the blog
+// was already on it.
+func (r *ReadingListRepo) AddItem(listID, blogID int64) (bool, error) {
+	query := `
+		INSERT INTO reading_list_items (list_id, blog_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1
+		FROM reading_list_items WHERE list_id = $1
+		ON CONFLICT DO NOTHING`
+	result, err := r.db.Exec(query, listID, blogID)
+	if err != nil {
+		log.Printf("Error adding blog %d to reading list %d: %v", blogID, listID, err)
+		return false, fmt.Errorf("failed to add to reading list: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+func (r *ReadingListRepo) RemoveItem(listID, blogID int64) error {
+	result, err := r.db.Exec(`DELETE FROM reading_list_items WHERE list_id = $1 AND blog_id = $2`, listID, blogID)
+	if err != nil {
+		log.Printf("Error removing blog %d from reading list %d: %v", blogID, listID, err)
+		return fmt.Errorf("failed to remove from reading list: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed item: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no reading list item found for blog %d in list %d", blogID, listID)
+	}
+	return nil
+}
+
+// GetItemIDs returns the blog IDs on a list in reading order.
+func (r *ReadingListRepo) GetItemIDs(listID int64) ([]int64, error) {
+	ids := []int64{}
+	err := r.db.Select(&ids, `SELECT blog_id FROM reading_list_items WHERE list_id = $1 ORDER BY position`, listID)
+	return ids, err
+}
+
+// ReorderItems sets each item's position to its index in blogIDs, which
+// must list every item on the list.
+func (r *ReadingListRepo) ReorderItems(listID int64, blogIDs []int64) error {
+	query := `
+		UPDATE reading_list_items i SET position = o.position
+		FROM unnest($2::BIGINT[]) WITH ORDINALITY AS o(blog_id, position)
+		WHERE i.list_id = $1 AND i.blog_id = o.blog_id`
+	if _, err := r.db.Exec(query, listID, pq.Array(blogIDs)); err != nil {
+		log.Printf("Error reordering reading list %d: %v", listID, err)
+		return fmt.Errorf("failed to reorder reading list: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type ReviewRepo struct {
+	db *sqlx.DB
+}
+
+func NewReviewRepo(db *sqlx.DB) *ReviewRepo {
+	return &ReviewRepo{db: db}
+}
+
+// AddReviewer assigns a reviewer to a blog and reports whether they were
+// newly assigned.
+func (r *ReviewRepo) AddReviewer(blogID, userID, assignedBy int64) (bool, error) {
+	query := `
+		INSERT INTO blog_reviewers (blog_id, user_id, assigned_by)
+		VALUES($1, $2, $3)
+		ON CONFLICT (blog_id, user_id) DO NOTHING`
+	result, err := r.db.Exec(query, blogID, userID, assignedBy)
+	if err != nil {
+		log.Printf("Error assigning user %d to review blog %d: %v", userID, blogID, err)
+		return false, fmt.Errorf("failed to assign reviewer: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check assigned reviewer: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *ReviewRepo) RemoveReviewer(blogID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM blog_reviewers WHERE blog_id = $1 AND user_id = $2`, blogID, userID)
+	if err != nil {
+		log.Printf("Error removing reviewer %d from blog %d: %v", userID, blogID, err)
+		return fmt.Errorf("failed to remove reviewer: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed reviewer: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no reviewer found for blog %d and user %d", blogID, userID)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) IsReviewer(blogID, userID int64) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM blog_reviewers WHERE blog_id = $1 AND user_id = $2)`
+	if err := r.db.Get(&exists, query, blogID, userID); err != nil {
+		log.Printf("Error checking reviewer %d on blog %d: %v", userID, blogID, err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetReviewers lists a blog's reviewers with their latest decision since
+// the blog was last submitted.
+func (r *ReviewRepo) GetReviewers(blogID int64) ([]models.BlogReviewer, error) {
+	reviewers := []models.BlogReviewer{}
+	query := `
+		SELECT br.blog_id, br.user_id, u.username, u.display_name, br.assigned_at,
+			(SELECT e.action FROM review_events e
+			 WHERE e.blog_id = br.blog_id AND e.user_id = br.user_id
+				AND e.action IN ('approved', 'changes_requested')
+				AND e.created_at >= COALESCE((
+					SELECT MAX(s.created_at) FROM review_events s
+					WHERE s.blog_id = br.blog_id AND s.action = 'submitted'
+				), '-infinity')
+			 ORDER BY e.created_at DESC, e.id DESC LIMIT 1) AS decision
+		FROM blog_reviewers br JOIN users u ON u.id = br.user_id
+		WHERE br.blog_id = $1
+		ORDER BY br.assigned_at`
+	err := r.db.Select(&reviewers, query, blogID)
+	if err != nil {
+		log.Printf("Error getting reviewers of blog %d: %v", blogID, err)
+	}
+	return reviewers, err
+}
+
+func (r *ReviewRepo) AddComment(comment *models.ReviewComment) error {
+	query := `
+		INSERT INTO review_comments (blog_id, user_id, body, anchor_start, anchor_end, quoted_text)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+	err := r.db.QueryRow(query, comment.BlogID, comment.UserID, comment.Body,
+		comment.AnchorStart, comment.AnchorEnd, comment.QuotedText).Scan(&comment.ID, &comment.CreatedAt)
+	if err != nil {
+		log.Printf("Error adding review comment on blog %d: %v", comment.BlogID, err)
+		return fmt.Errorf("failed to add review comment: %w", err)
+	}
+	return nil
+}
+
+// GetComment returns a review comment on a blog, or sql.ErrNoRows.
+func (r *ReviewRepo) GetComment(blogID, commentID int64) (*models.ReviewComment, error) {
+	var comment models.ReviewComment
+	query := `
+		SELECT c.id, c.blog_id, c.user_id, COALESCE(u.username, '') AS username, c.body,
+			c.anchor_start, c.anchor_end, c.quoted_text, c.resolved_at, c.created_at
+		FROM review_comments c LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.blog_id = $1 AND c.id = $2`
+	if err := r.db.Get(&comment, query, blogID, commentID); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *ReviewRepo) GetComments(blogID int64) ([]models.ReviewComment, error) {
+	comments := []models.ReviewComment{}
+	query := `
+		SELECT c.id, c.blog_id, c.user_id, COALESCE(u.username, '') AS username, c.body,
+			c.anchor_start, c.anchor_end, c.quoted_text, c.resolved_at, c.created_at
+		FROM review_comments c LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.blog_id = $1
+		ORDER BY c.created_at, c.id`
+	err := r.db.Select(&comments, query, blogID)
+	if err != nil {
+		log.Printf("Error getting review comments of blog %d: %v", blogID, err)
+	}
+	return comments, err
+}
+
+// GetCommentsByUser returns every review comment the user wrote, oldest first.
+func (r *ReviewRepo) GetCommentsByUser(userID int64) ([]models.ReviewComment, error) {
+	comments := []models.ReviewComment{}
+	query := `
+		SELECT c.id, c.blog_id, c.user_id, COALESCE(u.username, '') AS username, c.body,
+			c.anchor_start, c.anchor_end, c.quoted_text, c.resolved_at, c.created_at
+		FROM review_comments c LEFT JOIN users u ON u.id = c.user_id
+		WHERE c.user_id = $1
+		ORDER BY c.created_at, c.id`
+	err := r.db.Select(&comments, query, userID)
+	if err != nil {
+		log.Printf("Error getting review comments by user %d: %v", userID, err)
+	}
+	return comments, err
+}
+
+// SetCommentResolved marks a review comment resolved or reopens it.
+func (r *ReviewRepo) SetCommentResolved(blogID, commentID int64, resolved bool) error {
+	query := `
+		UPDATE review_comments
+		SET resolved_at = CASE WHEN $3 THEN COALESCE(resolved_at, NOW()) END
+		WHERE blog_id = $1 AND id = $2`
+	result, err := r.db.Exec(query, blogID, commentID, resolved)
+	if err != nil {
+		log.Printf("Error resolving review comment %d: %v", commentID, err)
+		return fmt.Errorf("failed to update review comment: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check updated review comment: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no review comment found with ID %d", commentID)
+	}
+	return nil
+}
+
+func (r *ReviewRepo) AddEvent(blogID, userID int64, action, note string) error {
+	query := `INSERT INTO review_events (blog_id, user_id, action, note) VALUES($1, $2, $3, $4)`
+	if _, err := r.db.Exec(query, blogID, userID, action, note); err != nil {
+		log.Printf("Error recording review event %q on blog %d: %v", action, blogID, err)
+		return fmt.Errorf("failed to record review event: %w", err)
+	}
+	return nil
+}
+
+// GetEvents returns a blog's review history, oldest first.
+func (r *ReviewRepo) GetEvents(blogID int64) ([]models.ReviewEvent, error) {
+	events := []models.ReviewEvent{}
+	query := `
+		SELECT e.id, e.blog_id, e.user_id, COALESCE(u.username, '') AS username,
+			e.action, e.note, e.created_at
+		FROM review_events e LEFT JOIN users u ON u.id = e.user_id
+		WHERE e.blog_id = $1
+		ORDER BY e.created_at, e.id`
+	err := r.db.Select(&events, query, blogID)
+	if err != nil {
+		log.Printf("Error getting review history of blog %d: %v", blogID, err)
+	}
+	return events, err
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// seriesColumns selects a series with its post count; queries using it must
+// alias series as s. With publicOnly only the posts readers may find are
+// counted, otherwise every post in the series that isn't in the trash.
+func seriesColumns(publicOnly bool) string {
+	filter := liveBlogFilter
+	if publicOnly {
+		filter = publicBlogFilter
+	}
+	return `
+	s.id, s.user_id, s.title, s.description, s.created_at, s.updated_at,
+	(SELECT COUNT(*) FROM series_posts sp JOIN blogs b ON b.id = sp.blog_id
+	 WHERE sp.series_id = s.id AND ` + filter + `) AS post_count`
+}
+
+type SeriesRepo struct {
+	db *sqlx.DB
+}
+
+func NewSeriesRepo(db *sqlx.DB) *SeriesRepo {
+	return &SeriesRepo{db: db}
+}
+
+func (r *SeriesRepo) CreateSeries(series *models.Series) error {
+	query := `
+		INSERT INTO series (user_id, title, description)
+		VALUES($1, $2, $3)
+		RETURNING id, created_at, updated_at`
+	err := r.db.QueryRow(query, series.UserID, series.Title, series.Description).
+		Scan(&series.ID, &series.CreatedAt, &series.UpdatedAt)
+	if err != nil {
+		log.Printf("Error creating series for user %d: %v", series.UserID, err)
+		return fmt.Errorf("failed to create series: %w", err)
+	}
+	return nil
+}
+
+func (r *SeriesRepo) GetSeriesByID(id int64, publicOnly bool) (*models.Series, error) {
+	var series models.Series
+	query := `SELECT ` + seriesColumns(publicOnly) + ` FROM series s WHERE s.id = $1`
+	if err := r.db.Get(&series, query, id); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (r *SeriesRepo) GetSeriesByUser(userID int64, publicOnly bool) ([]models.Series, error) {
+	series := []models.Series{}
+	query := `SELECT ` + seriesColumns(publicOnly) + ` FROM series s WHERE s.user_id = $1 ORDER BY s.created_at DESC`
+	err := r.db.Select(&series, query, userID)
+	if err != nil {
+		log.Printf("Error getting series for user %d: %v", userID, err)
+	}
+	return series, err
+}
+
+// GetSeriesIDForBlog returns the series a blog belongs to, or
+// sql.ErrNoRows when it is not part of one.
+func (r *SeriesRepo) GetSeriesIDForBlog(blogID int64) (int64, error) {
+	var id int64
+	err := r.db.Get(&id, `SELECT series_id FROM series_posts WHERE blog_id = $1`, blogID)
+	return id, err
+}
+
+func (r *SeriesRepo) UpdateSeries(series *models.Series) error {
+	query := `
+		UPDATE series SET title = $1, description = $2
+		WHERE id = $3 AND user_id = $4
+		RETURNING updated_at`
+	err := r.db.QueryRow(query, series.Title, series.Description, series.ID, series.UserID).Scan(&series.UpdatedAt)
+	if err != nil {
+		log.Printf("Error updating series %d: %v", series.ID, err)
+		return fmt.Errorf("failed to update series: %w", err)
+	}
+	return nil
+}
+
+// DeleteSeries removes a series; its posts are kept.
+func (r *SeriesRepo) DeleteSeries(seriesID, userID int64) error {
+	result, err := r.db.Exec(`DELETE FROM series WHERE id = $1 AND user_id = $2`, seriesID, userID)
+	if err != nil {
+		log.Printf("Error deleting series %d: %v", seriesID, err)
+		return fmt.Errorf("failed to delete series: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check deleted series: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no series found with ID %d for user %d", seriesID, userID)
+	}
+	return nil
+}
+
+// AddPost appends a blog to the end of a series.
+func (r *SeriesRepo) AddPost(seriesID, blogID int64) error {
+	query := `
+		INSERT INTO series_posts (series_id, blog_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1
+		FROM series_posts WHERE series_id = $1`
+	if _, err := r.db.Exec(query, seriesID, blogID); err != nil {
+		log.Printf("Error adding blog %d to series %d: %v", blogID, seriesID, err)
+		return fmt.Errorf("failed to add to series: %w", err)
+	}
+	return nil
+}
+
+func (r *SeriesRepo) RemovePost(seriesID, blogID int64) error {
+	result, err := r.db.Exec(`DELETE FROM series_posts WHERE series_id = $1 AND blog_id = $2`, seriesID, blogID)
+	if err != nil {
+		log.Printf("Error removing blog %d from series %d: %v", blogID, seriesID, err)
+		return fmt.Errorf("failed to remove from series: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check removed series post: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("no series post found for blog %d in series %d", blogID, seriesID)
+	}
+	return nil
+}
+
+// GetEntries returns the table of contents of a series in reading order,
+// only its published posts when publishedOnly is set. Positions are
+// renumbered from 1, so gaps left by removed posts never show.
+func (r *SeriesRepo) GetEntries(seriesID int64, publishedOnly bool) ([]models.SeriesEntry, error) {
+	entries := []models.SeriesEntry{}
+	query := `
+		SELECT sp.blog_id, b.title, ROW_NUMBER() OVER (ORDER BY sp.position) AS position
+		FROM series_posts sp
+		JOIN blogs b ON b.id = sp.blog_id
+		WHERE sp.series_id = $1 AND ` + liveBlogFilter + ` AND (NOT $2 OR ` + publicBlogFilter + `)
+		ORDER BY sp.position`
+	err := r.db.Select(&entries, query, seriesID, publishedOnly)
+	if err != nil {
+		log.Printf("Error getting entries of series %d: %v", seriesID, err)
+	}
+	return entries, err
+}
+
+// ReorderPosts sets each post's position to its index in blogIDs, which
+// must list every post in the series.
+func (r *SeriesRepo) ReorderPosts(seriesID int64, blogIDs []int64) error {
+	query := `
+		UPDATE series_posts sp SET position = o.position
+		FROM unnest($2::BIGINT[]) WITH ORDINALITY AS o(blog_id, position)
+		WHERE sp.series_id = $1 AND sp.blog_id = o.blog_id`
+	if _, err := r.db.Exec(query, seriesID, pq.Array(blogIDs)); err != nil {
+		log.Printf("Error reordering series %d: %v", seriesID, err)
+		return fmt.Errorf("failed to reorder series: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package repo
+
+import (
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestSeriesPostCount(t *testing.T) {
+	db := openTestDB(t)
+	f := newVisibilityFixture(t, db)
+	repo := NewSeriesRepo(db)
+
+	series := &models.Series{UserID: f.author, Title: "Every kind of post"}
+	if err := repo.CreateSeries(series); err != nil {
+		t.Fatal(err)
+	}
+	var blogIDs []int64
+	if err := db.Select(&blogIDs, `SELECT id FROM blogs WHERE user_id = $1 ORDER BY id`, f.author); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range blogIDs {
+		if err := repo.AddPost(series.ID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	public, err := repo.GetEntries(series.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		publicOnly bool
+		want       int
+	}{
+		// Only the public post, matching the published table of contents
+		{"readers", true, len(public)},
+		// Everything but the trashed post
+		{"author", false, len(blogIDs) - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			byID, err := repo.GetSeriesByID(series.ID, tt.publicOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if byID.PostCount != tt.want {
+				t.Errorf("GetSeriesByID post_count = %d, want %d", byID.PostCount, tt.want)
+			}
+			byUser, err := repo.GetSeriesByUser(f.author, tt.publicOnly)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(byUser) != 1 || byUser[0].PostCount != tt.want {
+				t.Errorf("GetSeriesByUser = %+v, want one series with post_count %d", byUser, tt.want)
+			}
+		})
+	}
+	if len(public) != 1 {
+		t.Errorf("%d public entries, want 1", len(public))
+	}
+}
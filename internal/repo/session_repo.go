@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type SessionRepo struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepo(db *sqlx.DB) *SessionRepo {
+	return &SessionRepo{db: db}
+}
+
+func (r *SessionRepo) CreateSession(session *models.Session) error {
+	query := `
+		INSERT INTO sessions (user_id, device, ip)
+		VALUES($1, $2, $3)
+		RETURNING id, created_at, last_seen_at`
+	return r.db.QueryRow(
+		query, session.UserID, session.Device, session.IP,
+	).Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt)
+}
+
+func (r *SessionRepo) GetActiveSession(sessionID, userID int64) (*models.Session, error) {
+	var session models.Session
+	query := `
+		SELECT id, user_id, device, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	if err := r.db.Get(&session, query, sessionID, userID); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetActiveSessionsByUserID returns unrevoked sessions created after since.
+func (r *SessionRepo) GetActiveSessionsByUserID(userID int64, since time.Time) ([]models.Session, error) {
+	sessions := []models.Session{}
+	query := `
+		SELECT id, user_id, device, ip, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND created_at > $2
+		ORDER BY last_seen_at DESC`
+	err := r.db.Select(&sessions, query, userID, since)
+	if err != nil {
+		log.Printf("Error getting sessions for user %d: %v", userID, err)
+	}
+	return sessions, err
+}
+
+func (r *SessionRepo) TouchSession(sessionID int64) error {
+	query := `UPDATE sessions SET last_seen_at = NOW() WHERE id = $1`
+	if _, err := r.db.Exec(query, sessionID); err != nil {
+		log.Printf("Error updating last_seen_at for session %d: %v", sessionID, err)
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepo) RevokeSession(sessionID, userID int64) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, sessionID, userID)
+	if err != nil {
+		log.Printf("Error revoking session %d by user %d: %v", sessionID, userID, err)
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check affected rows after revoke: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no session found with ID %d for user %d", sessionID, userID)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes all of a user's sessions except exceptID
+// (pass 0 to revoke every session).
+func (r *SessionRepo) RevokeUserSessions(userID, exceptID int64) error {
+	query := `
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`
+	if _, err := r.db.Exec(query, userID, exceptID); err != nil {
+		log.Printf("Error revoking sessions for user %d: %v", userID, err)
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return nil
+}
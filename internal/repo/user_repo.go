@@ -4,7 +4,10 @@ import (
 	"database/sql" 
 	"fmt" 
 	"log" 
+	"strings"
+	"time"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
     "github.com/Brownie44l1/blog/internal/models"
 )
 
@@ -17,25 +20,32 @@ func NewUserRepo(db *sqlx.DB) *UserRepo {
 }
 
 func (r *UserRepo) CreateUser(user *models.User) error {
+	if user.Status == "" {
+		user.Status = "active"
+	}
 	query := `
-		INSERT INTO users (username, password)
-		VALUES($1, $2)
-		RETURNING id`
+		INSERT INTO users (username, password, email, status, invited_by, invite_id)
+		VALUES($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
 	return r.db.QueryRow(
-		query, user.Username, user.Password,
-	).Scan(&user.ID)
+		query, user.Username, user.Password, user.Email, user.Status, user.InvitedBy, user.InviteID,
+	).Scan(&user.ID, &user.CreatedAt)
 }
 
 func (r *UserRepo) GetByID(id int64) (*models.User, error) {
     query := `
-        SELECT u.id, u.username, u.password, COALESCE(COUNT(b.id), 0) as blog_count
+        SELECT u.id, u.username, u.password, u.email, u.email_verified_at,
+               u.display_name, u.bio, u.avatar_url, u.website, u.location, u.social_links,
+               u.username_changed_at, u.deletion_requested_at, u.deletion_mode,
+               u.status, u.is_admin, u.invited_by, u.invite_id, u.created_at,
+               COALESCE(COUNT(b.id), 0) as blog_count
         FROM users u
-        LEFT JOIN blogs b ON u.id = b.user_id
+        LEFT JOIN blogs b ON u.id = b.user_id AND b.deleted_at IS NULL
         WHERE u.id = $1
         GROUP BY u.id
     `
     user := &models.User{}
-    err := r.db.QueryRow(query, id).Scan(&user.ID, &user.Username, &user.Password, &user.BlogCount)
+    err := r.db.Get(user, query, id)
     if err != nil {
         return nil, err
     }
@@ -66,7 +76,7 @@ func (r *UserRepo) GetBlogCountByUserID(userID int64) (int, error) {
 	var count int
 	query := `
 		SELECT COUNT(id) FROM blogs
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND deleted_at IS NULL`
 	err := r.db.QueryRow(query, userID).Scan(&count)
 
 	if err != nil {
@@ -76,3 +86,243 @@ func (r *UserRepo) GetBlogCountByUserID(userID int64) (int, error) {
 	
 	return count, nil
 }
+
+func (r *UserRepo) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+
+	query := `
+		SELECT * FROM users
+		WHERE LOWER(email) = LOWER($1)`
+
+	if err := r.db.Get(&user, query, email); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepo) UpdatePassword(userID int64, hashedPassword string) error {
+	query := `UPDATE users SET password = $2 WHERE id = $1`
+	if _, err := r.db.Exec(query, userID, hashedPassword); err != nil {
+		log.Printf("Error updating password for user %d: %v", userID, err)
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	return nil
+}
+
+// SetVerifiedEmail stores email as the user's address and marks it verified.
+func (r *UserRepo) SetVerifiedEmail(userID int64, email string) error {
+	query := `
+		UPDATE users
+		SET email = $2, email_verified_at = NOW()
+		WHERE id = $1`
+	if _, err := r.db.Exec(query, userID, email); err != nil {
+		log.Printf("Error setting verified email for user %d: %v", userID, err)
+		return fmt.Errorf("failed to set email: %w", err)
+	}
+	return nil
+}
+
+func (r *UserRepo) UpdateProfile(user *models.User) error {
+	query := `
+		UPDATE users
+		SET display_name = $2, bio = $3, avatar_url = $4, website = $5, location = $6, social_links = $7
+		WHERE id = $1`
+	_, err := r.db.Exec(query,
+		user.ID, user.DisplayName, user.Bio, user.AvatarURL, user.Website, user.Location, user.SocialLinks,
+	)
+	if err != nil {
+		log.Printf("Error updating profile for user %d: %v", user.ID, err)
+		return fmt.Errorf("failed to update profile: %w", err)
+	}
+	return nil
+}
+
+// GetUserIDByPreviousUsername returns the user that held username before a
+// rename, as long as its redirect has not expired.
+func (r *UserRepo) GetUserIDByPreviousUsername(username string) (int64, error) {
+	var userID int64
+	query := `
+		SELECT user_id FROM username_history
+		WHERE LOWER(username) = LOWER($1) AND expires_at > NOW()
+		ORDER BY changed_at DESC
+		LIMIT 1`
+	if err := r.db.Get(&userID, query, username); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
+
+// ChangeUsername renames a user and records the old name so it keeps
+// redirecting until redirectUntil.
+func (r *UserRepo) ChangeUsername(userID int64, oldUsername, newUsername string, redirectUntil time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(
+		`UPDATE users SET username = $2, username_changed_at = NOW() WHERE id = $1`,
+		userID, newUsername,
+	); err != nil {
+		log.Printf("Error changing username for user %d: %v", userID, err)
+		return fmt.Errorf("failed to change username: %w", err)
+	}
+
+	// Taking back one of your own previous names removes its redirect
+	if _, err := tx.Exec(
+		`DELETE FROM username_history WHERE user_id = $1 AND LOWER(username) = LOWER($2)`,
+		userID, newUsername,
+	); err != nil {
+		return fmt.Errorf("failed to update username history: %w", err)
+	}
+
+	if !strings.EqualFold(oldUsername, newUsername) {
+		// An expired redirect from an earlier holder of the name no
+		// longer reserves it
+		if _, err := tx.Exec(
+			`DELETE FROM username_history WHERE LOWER(username) = LOWER($1) AND expires_at <= NOW()`,
+			oldUsername,
+		); err != nil {
+			return fmt.Errorf("failed to update username history: %w", err)
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO username_history (user_id, username, expires_at) VALUES ($1, $2, $3)`,
+			userID, oldUsername, redirectUntil,
+		); err != nil {
+			return fmt.Errorf("failed to record username history: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ScheduleDeletion marks the account for deletion; mode decides what happens
+// to the user's posts when it is purged. It also removes the user's personal
+// access tokens, so API clients lose access for the grace period.
+func (r *UserRepo) ScheduleDeletion(userID int64, mode string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET deletion_requested_at = NOW(), deletion_mode = $2 WHERE id = $1`
+	if _, err := tx.Exec(query, userID, mode); err != nil {
+		log.Printf("Error scheduling deletion for user %d: %v", userID, err)
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM personal_access_tokens WHERE user_id = $1`, userID); err != nil {
+		log.Printf("Error deleting access tokens of user %d: %v", userID, err)
+		return fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *UserRepo) CancelDeletion(userID int64) error {
+	query := `UPDATE users SET deletion_requested_at = NULL, deletion_mode = NULL WHERE id = $1`
+	if _, err := r.db.Exec(query, userID); err != nil {
+		log.Printf("Error cancelling deletion for user %d: %v", userID, err)
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+	return nil
+}
+
+// GetUsersDueForDeletion returns the IDs of accounts whose deletion was
+// requested before cutoff.
+func (r *UserRepo) GetUsersDueForDeletion(cutoff time.Time) ([]int64, error) {
+	ids := []int64{}
+	query := `SELECT id FROM users WHERE deletion_requested_at < $1`
+	err := r.db.Select(&ids, query, cutoff)
+	return ids, err
+}
+
+// DeleteUser erases an account and everything that cascades from it. With
+// keepPosts the user's posts are first re-attributed to the "deleted"
+// placeholder account. It returns the export archives left on disk so the
+// caller can remove them.
+func (r *UserRepo) DeleteUser(userID int64, keepPosts bool) ([]string, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if keepPosts {
+		var placeholderID int64
+		if err := tx.Get(&placeholderID, `SELECT id FROM users WHERE username = 'deleted'`); err != nil {
+			return nil, fmt.Errorf("failed to find the deleted user placeholder: %w", err)
+		}
+		res, err := tx.Exec(`UPDATE blogs SET user_id = $2 WHERE user_id = $1`, userID, placeholderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to re-attribute posts: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			log.Printf("Re-attributed %d posts of user %d to the deleted user", n, userID)
+		}
+	}
+
+	files := []string{}
+	if err := tx.Select(&files, `SELECT file_path FROM data_exports WHERE user_id = $1 AND file_path <> ''`, userID); err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	res, err := tx.Exec(`DELETE FROM users WHERE id = $1`, userID)
+	if err != nil {
+		log.Printf("Error deleting user %d: %v", userID, err)
+		return nil, fmt.Errorf("failed to delete user: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("no user found with ID %d", userID)
+	}
+
+	return files, tx.Commit()
+}
+
+// GetPendingUsers returns accounts awaiting admin approval, oldest first.
+func (r *UserRepo) GetPendingUsers() ([]models.User, error) {
+	users := []models.User{}
+	query := `
+		SELECT id, username, email, invited_by, created_at
+		FROM users
+		WHERE status = 'pending'
+		ORDER BY created_at`
+	err := r.db.Select(&users, query)
+	if err != nil {
+		log.Printf("Error getting pending users: %v", err)
+	}
+	return users, err
+}
+
+// ApproveUser activates a pending account.
+func (r *UserRepo) ApproveUser(userID int64) error {
+	query := `UPDATE users SET status = 'active' WHERE id = $1 AND status = 'pending'`
+	result, err := r.db.Exec(query, userID)
+	if err != nil {
+		log.Printf("Error approving user %d: %v", userID, err)
+		return fmt.Errorf("failed to approve user: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("no pending user found with ID %d", userID)
+	}
+	return nil
+}
+
+// GetAuthorsByIDs returns the full public profiles of several users in one
+// query. Unknown IDs are left out.
+func (r *UserRepo) GetAuthorsByIDs(ids []int64) ([]models.Author, error) {
+	authors := []models.Author{}
+	if len(ids) == 0 {
+		return authors, nil
+	}
+	query := `
+		SELECT id, username, display_name, avatar_url, bio, website, location,
+			social_links, created_at AS joined_at
+		FROM users
+		WHERE id = ANY($1)`
+	err := r.db.Select(&authors, query, pq.Array(ids))
+	if err != nil {
+		log.Printf("Error getting %d authors: %v", len(ids), err)
+	}
+	return authors, err
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+type UserTokenRepo struct {
+	db *sqlx.DB
+}
+
+func NewUserTokenRepo(db *sqlx.DB) *UserTokenRepo {
+	return &UserTokenRepo{db: db}
+}
+
+func (r *UserTokenRepo) CreateUserToken(token *models.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, email, expires_at)
+		VALUES($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+	return r.db.QueryRow(
+		query, token.UserID, token.Purpose, token.TokenHash, token.Email, token.ExpiresAt,
+	).Scan(&token.ID, &token.CreatedAt)
+}
+
+// GetUserToken returns an unused, unexpired token without redeeming it.
+func (r *UserTokenRepo) GetUserToken(tokenHash, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	query := `
+		SELECT id, user_id, purpose, token_hash, email, expires_at, used_at, created_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()`
+	if err := r.db.Get(&token, query, tokenHash, purpose); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// ConsumeUserToken marks an unused, unexpired token as used and returns it.
+// Doing both in one statement guarantees a token can only be redeemed once.
+func (r *UserTokenRepo) ConsumeUserToken(tokenHash, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	query := `
+		UPDATE user_tokens
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, purpose, token_hash, email, expires_at, used_at, created_at`
+	if err := r.db.Get(&token, query, tokenHash, purpose); err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteUnusedUserTokens removes a user's outstanding tokens for purpose,
+// so only the most recently issued one can be redeemed.
+func (r *UserTokenRepo) DeleteUnusedUserTokens(userID int64, purpose string) error {
+	query := `DELETE FROM user_tokens WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	if _, err := r.db.Exec(query, userID, purpose); err != nil {
+		log.Printf("Error deleting %s tokens for user %d: %v", purpose, userID, err)
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// AccessTokenPrefix marks bearer credentials as personal access tokens
+// rather than JWTs.
+const AccessTokenPrefix = "blog_pat_"
+
+var (
+	ErrTokenNotFound = errors.New("token not found")
+	ErrInvalidToken  = errors.New("invalid or expired token")
+)
+
+// AccessTokenRepository defines the interface for personal access token data operations.
+type AccessTokenRepository interface {
+	CreateToken(token *models.PersonalAccessToken) error
+	GetTokensByUserID(userID int64) ([]models.PersonalAccessToken, error)
+	GetTokenByHash(tokenHash string) (*models.PersonalAccessToken, error)
+	TouchToken(tokenID int64) error
+	DeleteToken(tokenID, userID int64) error
+}
+
+// AccessTokenService manages long-lived personal access tokens for API clients.
+type AccessTokenService interface {
+	Create(userID int64, name string, scopes []string, expiresInDays int) (*models.PersonalAccessToken, string, error)
+	List(userID int64) ([]models.PersonalAccessToken, error)
+	Revoke(userID, tokenID int64) error
+	Verify(token string) (*models.PersonalAccessToken, error)
+}
+
+type accessTokenService struct {
+	repo AccessTokenRepository
+}
+
+func NewAccessTokenService(r AccessTokenRepository) AccessTokenService {
+	return &accessTokenService{repo: r}
+}
+
+// Create issues a new token and returns it together with the plaintext value,
+// which is only ever available at creation time.
+func (s *accessTokenService) Create(userID int64, name string, scopes []string, expiresInDays int) (*models.PersonalAccessToken, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: token name cannot be empty", ErrInvalidInput)
+	}
+	if len(name) > 100 {
+		return nil, "", fmt.Errorf("%w: token name cannot exceed 100 characters", ErrInvalidInput)
+	}
+	if len(scopes) == 0 {
+		return nil, "", fmt.Errorf("%w: at least one scope is required", ErrInvalidInput)
+	}
+	for _, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			return nil, "", fmt.Errorf("%w: unknown scope %q", ErrInvalidInput, scope)
+		}
+	}
+	if expiresInDays < 0 {
+		return nil, "", fmt.Errorf("%w: expiry cannot be negative", ErrInvalidInput)
+	}
+
+	raw, err := auth.GenerateOpaqueToken(AccessTokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: auth.HashToken(raw),
+		Prefix:    raw[:len(AccessTokenPrefix)+4],
+		Scopes:    scopes,
+	}
+	if expiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.CreateToken(token); err != nil {
+		return nil, "", fmt.Errorf("failed to create token: %w", err)
+	}
+	return token, raw, nil
+}
+
+// List returns all tokens belonging to a user.
+func (s *accessTokenService) List(userID int64) ([]models.PersonalAccessToken, error) {
+	tokens, err := s.repo.GetTokensByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving tokens for user %d: %w", userID, err)
+	}
+	return tokens, nil
+}
+
+// Revoke deletes a token owned by the user.
+func (s *accessTokenService) Revoke(userID, tokenID int64) error {
+	if err := s.repo.DeleteToken(tokenID, userID); err != nil {
+		if strings.Contains(err.Error(), "no token found") {
+			return ErrTokenNotFound
+		}
+		return fmt.Errorf("revocation failed: %w", err)
+	}
+	return nil
+}
+
+// Verify resolves a plaintext token, rejecting unknown or expired ones, and
+// records its use.
+func (s *accessTokenService) Verify(raw string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(raw, AccessTokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	token, err := s.repo.GetTokenByHash(auth.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("error looking up token: %w", err)
+	}
+
+	if token.ExpiresAt != nil && time.Now().After(*token.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.repo.TouchToken(token.ID); err != nil {
+		log.Printf("⚠️  Failed to record usage of token %d: %v", token.ID, err)
+	}
+	return token, nil
+}
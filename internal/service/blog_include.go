@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Relations that Include can embed in blogs.
+const (
+	IncludeAuthor = "author"
+	IncludeTags   = "tags"
+	IncludeStats  = "stats"
+)
+
+const maxBlogTags = 5
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// normalizeTags lowercases and de-duplicates tags, dropping a leading '#'.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if !tagPattern.MatchString(tag) {
+			return nil, fmt.Errorf("%w: invalid tag %q; use up to 32 letters, digits and hyphens", ErrInvalidInput, tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > maxBlogTags {
+		return nil, fmt.Errorf("%w: a post can have at most %d tags", ErrInvalidInput, maxBlogTags)
+	}
+	return normalized, nil
+}
+
+// Include embeds the named relations in blogs with one query per relation,
+// however many blogs there are.
+func (s *blogService) Include(blogs []models.Blog, include []string) error {
+	for _, name := range include {
+		if name != IncludeAuthor && name != IncludeTags && name != IncludeStats {
+			return fmt.Errorf("%w: unknown include %q; use %s, %s or %s", ErrInvalidInput, name, IncludeAuthor, IncludeTags, IncludeStats)
+		}
+	}
+	if len(blogs) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(blogs))
+	for i := range blogs {
+		ids[i] = blogs[i].ID
+	}
+
+	for _, name := range include {
+		var err error
+		switch name {
+		case IncludeAuthor:
+			err = s.includeAuthors(blogs)
+		case IncludeTags:
+			err = s.includeTags(blogs, ids)
+		case IncludeStats:
+			err = s.includeStats(blogs, ids)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *blogService) LoadContent(blogs []models.Blog) error {
+	ids := make([]int64, len(blogs))
+	for i := range blogs {
+		ids[i] = blogs[i].ID
+	}
+	contents, err := s.repo.GetBlogContents(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load content: %w", err)
+	}
+	byBlog := make(map[int64]string, len(contents))
+	for _, c := range contents {
+		byBlog[c.BlogID] = c.Content
+	}
+	for i := range blogs {
+		blogs[i].Content = byBlog[blogs[i].ID]
+	}
+	return nil
+}
+
+// includeAuthors replaces each blog's compact author with the full profile.
+func (s *blogService) includeAuthors(blogs []models.Blog) error {
+	seen := map[int64]bool{}
+	var authorIDs []int64
+	for i := range blogs {
+		if id := blogs[i].Author.ID; !seen[id] {
+			seen[id] = true
+			authorIDs = append(authorIDs, id)
+		}
+	}
+	authors, err := s.users.GetAuthorsByIDs(authorIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load authors: %w", err)
+	}
+	byID := make(map[int64]models.Author, len(authors))
+	for _, author := range authors {
+		byID[author.ID] = author
+	}
+	for i := range blogs {
+		if author, ok := byID[blogs[i].Author.ID]; ok {
+			blogs[i].Author = author
+		}
+	}
+	return nil
+}
+
+func (s *blogService) includeTags(blogs []models.Blog, ids []int64) error {
+	tags, err := s.repo.GetBlogTags(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load tags: %w", err)
+	}
+	byBlog := make(map[int64][]string, len(blogs))
+	for _, t := range tags {
+		byBlog[t.BlogID] = append(byBlog[t.BlogID], t.Tag)
+	}
+	for i := range blogs {
+		blogs[i].Tags = byBlog[blogs[i].ID]
+	}
+	return nil
+}
+
+func (s *blogService) includeStats(blogs []models.Blog, ids []int64) error {
+	stats, err := s.repo.GetBlogStats(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load stats: %w", err)
+	}
+	byBlog := make(map[int64]*models.BlogStats, len(stats))
+	for i := range stats {
+		byBlog[stats[i].BlogID] = &stats[i]
+	}
+	for i := range blogs {
+		blogs[i].Stats = byBlog[blogs[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"none", nil, []string{}},
+		{"lowercases", []string{"Go", "WebDev"}, []string{"go", "webdev"}},
+		{"strips hash and spaces", []string{" #go ", "#rust"}, []string{"go", "rust"}},
+		{"de-duplicates in order", []string{"go", "Go", "#go", "sql"}, []string{"go", "sql"}},
+		{"hyphens and digits", []string{"web-dev", "2024"}, []string{"web-dev", "2024"}},
+		{"five tags", []string{"a", "b", "c", "d", "e"}, []string{"a", "b", "c", "d", "e"}},
+		{"duplicates don't count toward the limit", []string{"a", "b", "c", "d", "e", "A"}, []string{"a", "b", "c", "d", "e"}},
+		{"longest tag", []string{strings.Repeat("a", 32)}, []string{strings.Repeat("a", 32)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTags(tt.tags)
+			if err != nil {
+				t.Fatalf("normalizeTags(%q): %v", tt.tags, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("normalizeTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeTagsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+	}{
+		{"empty", []string{""}},
+		{"only a hash", []string{"#"}},
+		{"space inside", []string{"web dev"}},
+		{"leading hyphen", []string{"-go"}},
+		{"punctuation", []string{"c++"}},
+		{"non-ASCII", []string{"café"}},
+		{"too long", []string{strings.Repeat("a", 33)}},
+		{"too many", []string{"a", "b", "c", "d", "e", "f"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := normalizeTags(tt.tags); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("normalizeTags(%q) err = %v, want ErrInvalidInput", tt.tags, err)
+			}
+		})
+	}
+}
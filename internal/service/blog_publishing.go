@@ -0,0 +1,131 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Blog statuses. Personal posts go straight from draft to published
+// unless their author asks for a review; publication writers submit posts
+// that an editor then publishes.
+const (
+	BlogStatusDraft     = "draft"
+	BlogStatusSubmitted = "submitted"
+	BlogStatusPublished = "published"
+)
+
+// initialStatus settles the status of a new blog from the requested one
+// and, for publication posts, the author's role.
+func (s *blogService) initialStatus(blog *models.Blog) error {
+	if blog.Status != BlogStatusDraft {
+		blog.Status = BlogStatusPublished
+	}
+
+	if blog.PublicationID != nil {
+		role, err := publicationRole(s.publications, *blog.PublicationID, blog.UserId)
+		if err != nil {
+			return err
+		}
+		if role == "" {
+			return ErrNotPublicationMember
+		}
+		if blog.Status == BlogStatusPublished && !canPublish(role) {
+			blog.Status = BlogStatusSubmitted
+		}
+	}
+
+	if blog.Status == BlogStatusPublished {
+		now := time.Now()
+		blog.PublishedAt = &now
+	}
+	return nil
+}
+
+// canView reports whether viewerID may read a blog that is not published:
+// its owner and co-authors, its reviewers, and editors of its publication.
+func (s *blogService) canView(blog *models.Blog, viewerID int64) (bool, error) {
+	if viewerID == 0 {
+		return false, nil
+	}
+
+	role, err := blogRole(s.coAuthors, blog, viewerID)
+	if err != nil || role != "" {
+		return role != "", err
+	}
+	return isReviewer(s.reviews, s.publications, blog, viewerID)
+}
+
+func (s *blogService) View(id, viewerID int64, password string) (*models.Blog, error) {
+	blog, err := s.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if blog.Status == BlogStatusPublished {
+		if err := s.checkAccess(blog, viewerID, password); err != nil {
+			return nil, err
+		}
+		return blog, nil
+	}
+	ok, err := s.canView(blog, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrBlogNotFound
+	}
+	return blog, nil
+}
+
+// canChangeStatus reports whether userID may publish or unpublish a blog:
+// the owner of a personal post, or an editor of the post's publication.
+func (s *blogService) canChangeStatus(blog *models.Blog, userID int64) error {
+	if blog.PublicationID == nil {
+		if blog.UserId != userID {
+			return ErrNotBlogOwner
+		}
+		return nil
+	}
+
+	role, err := publicationRole(s.publications, *blog.PublicationID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrNotPublicationMember
+	}
+	if !canPublish(role) {
+		return ErrPublicationRole
+	}
+	return nil
+}
+
+// setStatus moves a blog to status and returns it as readers now see it.
+func (s *blogService) setStatus(blog *models.Blog, status string) (*models.Blog, error) {
+	if err := s.repo.SetBlogStatus(blog.ID, status); err != nil {
+		return nil, err
+	}
+	return s.GetByID(blog.ID)
+}
+
+func (s *blogService) Publish(blogID, userID int64) (*models.Blog, error) {
+	blog, err := s.repo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	if err := s.canChangeStatus(blog, userID); err != nil {
+		return nil, err
+	}
+	return s.setStatus(blog, BlogStatusPublished)
+}
+
+func (s *blogService) Unpublish(blogID, userID int64) (*models.Blog, error) {
+	blog, err := s.repo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	if err := s.canChangeStatus(blog, userID); err != nil {
+		return nil, err
+	}
+	return s.setStatus(blog, BlogStatusDraft)
+}
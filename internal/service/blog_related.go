@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	relatedBlogsLimit = 5
+	// relatedCandidates are scored and cached per post, so suggestions
+	// that stop being public can be skipped without rescoring
+	relatedCandidates = 2 * relatedBlogsLimit
+	// relatedCacheTTL bounds how stale a cached ranking gets from changes
+	// to other posts; edits to the post itself invalidate its entry at once.
+	relatedCacheTTL = time.Hour
+	// relatedCacheMaxEntries triggers a sweep of expired entries
+	relatedCacheMaxEntries = 1000
+)
+
+// relatedCache holds the IDs of each post's related posts, best first, for
+// relatedCacheTTL. Only IDs are kept: the posts themselves are loaded on
+// every read, so ones that were unpublished, hidden or trashed since drop
+// out at once.
+type relatedCache struct {
+	mu      sync.Mutex
+	entries map[int64]relatedEntry
+}
+
+type relatedEntry struct {
+	ids       []int64
+	expiresAt time.Time
+}
+
+func newRelatedCache() *relatedCache {
+	return &relatedCache{entries: make(map[int64]relatedEntry)}
+}
+
+func (c *relatedCache) get(blogID int64) ([]int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[blogID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.ids, true
+}
+
+func (c *relatedCache) put(blogID int64, ids []int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if len(c.entries) >= relatedCacheMaxEntries {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[blogID] = relatedEntry{
+		ids:       ids,
+		expiresAt: now.Add(relatedCacheTTL),
+	}
+}
+
+func (c *relatedCache) invalidate(blogID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, blogID)
+}
+
+// Related returns public posts related to a blog the viewer may read, by
+// shared tags, similar text and the same author.
+func (s *blogService) Related(blogID, viewerID int64, password string) ([]models.Blog, error) {
+	if _, err := s.View(blogID, viewerID, password); err != nil {
+		return nil, err
+	}
+	ids, ok := s.related.get(blogID)
+	if !ok {
+		var err error
+		if ids, err = s.repo.GetRelatedBlogIDs(blogID, relatedCandidates); err != nil {
+			return nil, fmt.Errorf("failed to find related blogs: %w", err)
+		}
+		s.related.put(blogID, ids)
+	}
+
+	blogs, err := s.repo.GetPublicBlogsByIDs(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load related blogs: %w", err)
+	}
+	if len(blogs) > relatedBlogsLimit {
+		blogs = blogs[:relatedBlogsLimit]
+	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
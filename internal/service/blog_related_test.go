@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// relatedBlogRepo suggests every other post as related, leaving it to
+// GetPublicBlogsByIDs to drop those that aren't public, as the SQL does.
+type relatedBlogRepo struct {
+	*fakeBlogRepo
+	scored int
+}
+
+func (r *relatedBlogRepo) GetRelatedBlogIDs(blogID int64, limit int) ([]int64, error) {
+	r.scored++
+	ids := []int64{}
+	for id := int64(1); id <= int64(len(r.blogs)) && len(ids) < limit; id++ {
+		if id != blogID {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (r *relatedBlogRepo) GetPublicBlogsByIDs(ids []int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	for _, id := range ids {
+		b, ok := r.blogs[id]
+		if ok && b.DeletedAt == nil && b.Status == BlogStatusPublished && b.Visibility == BlogVisibilityPublic {
+			blogs = append(blogs, *b)
+		}
+	}
+	return blogs, nil
+}
+
+func newRelatedTest() (*blogService, *relatedBlogRepo, blogTestDeps) {
+	_, deps := newReadabilityFixture()
+	// A second public post for the others to be related to
+	deps.blogs.add(models.Blog{ID: 7, UserId: fixtureAuthor, Title: "public too"})
+	repo := &relatedBlogRepo{fakeBlogRepo: deps.blogs}
+	s := NewBlogService(repo, deps.reactions, fakeSeriesRepo{}, deps.coAuthors, deps.publications, deps.reviews, deps.follows, nil).(*blogService)
+	return s, repo, deps
+}
+
+func relatedIDs(blogs []models.Blog) []int64 {
+	ids := make([]int64, len(blogs))
+	for i, b := range blogs {
+		ids[i] = b.ID
+	}
+	return ids
+}
+
+func TestRelatedOnlySuggestsPublicPosts(t *testing.T) {
+	tests := []struct {
+		name   string
+		blogID int64
+		viewer int64
+		want   []int64
+		err    error
+	}{
+		{"public post", publicPost, 0, []int64{7}, nil},
+		{"unlisted post", unlistedPost, 0, []int64{publicPost, 7}, nil},
+		// Even readers who may see the followers-only and private posts
+		// only get public suggestions
+		{"followers post, follower", followersPost, fixtureFollower, []int64{publicPost, 7}, nil},
+		{"private post, author", privatePost, fixtureAuthor, []int64{publicPost, 7}, nil},
+		{"draft, author", draftPost, fixtureAuthor, []int64{publicPost, 7}, nil},
+		// Suggestions for posts the viewer can't read would leak them
+		{"followers post, stranger", followersPost, fixtureStranger, nil, ErrFollowersOnly},
+		{"private post, stranger", privatePost, fixtureStranger, nil, ErrBlogNotFound},
+		{"draft, stranger", draftPost, fixtureStranger, nil, ErrBlogNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _, _ := newRelatedTest()
+			related, err := s.Related(tt.blogID, tt.viewer, "")
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Related err = %v, want %v", err, tt.err)
+			}
+			if got := relatedIDs(related); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("related = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelatedDropsPostsHiddenSinceCached(t *testing.T) {
+	s, repo, deps := newRelatedTest()
+	if _, err := s.Related(unlistedPost, 0, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	deps.blogs.blogs[7].Visibility = BlogVisibilityPrivate
+	related, err := s.Related(unlistedPost, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relatedIDs(related); len(got) != 1 || got[0] != publicPost {
+		t.Errorf("after hiding post 7: related = %v, want [%d]", got, publicPost)
+	}
+	if repo.scored != 1 {
+		t.Errorf("related posts scored %d times, want once", repo.scored)
+	}
+
+	if err := s.Delete(publicPost, fixtureAuthor); err != nil {
+		t.Fatal(err)
+	}
+	related, err = s.Related(unlistedPost, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(related) != 0 {
+		t.Errorf("after trashing: related = %v, want none", relatedIDs(related))
+	}
+}
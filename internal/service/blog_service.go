@@ -4,73 +4,184 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/Brownie44l1/blog/internal/models"
 )
 
 // BlogRepository defines the interface for blog data operations.
 type BlogRepository interface {
-	CreateBlog(blog *models.Blog) error
+	CreateBlog(blog *models.Blog, accessPasswordHash *string) error
 	GetBlogByID(id int64) (*models.Blog, error)
 	GetBlogByUserID(userID int64) ([]models.Blog, error)
+	GetBlogsByAuthor(userID, viewerID int64, publishedOnly bool) ([]models.Blog, error)
 	DeleteBlog(blogID, userID int64) error
 	UpdateBlog(blog *models.Blog) error 
-	GetAllBlogs(limit, offset int64) ([]models.Blog, error)
-	SearchBlogs(searchQuery string) ([]models.Blog, error)
+	GetAllBlogs(viewerID, limit, offset int64) ([]models.Blog, error)
+	SearchBlogs(searchQuery string, viewerID int64) ([]models.Blog, error)
+	GetBlogsReactedByUser(userID int64, reaction string, limit, offset int64) ([]models.Blog, error)
+	GetBookmarkedBlogs(userID, limit, offset int64) ([]models.Blog, error)
+	GetReadingListBlogs(listID, viewerID int64) ([]models.Blog, error)
+	SetBlogStatus(blogID int64, status string) error
+	GetPublicationBlogs(publicationID int64, status string, limit, offset int64) ([]models.Blog, error)
+	GetBlogsAwaitingReview(userID int64) ([]models.Blog, error)
+	SetBlogVisibility(blogID int64, visibility string, accessPasswordHash *string) error
+	GetBlogAccessPasswordHash(blogID int64) (string, error)
+	GetTrashedBlogs(userID int64) ([]models.Blog, error)
+	RestoreBlog(blogID, userID int64) error
+	PurgeBlog(blogID, userID int64) error
+	PurgeTrashedBlogs(cutoff time.Time) (int64, error)
+	SetBlogTags(blogID int64, tags []string) error
+	GetBlogTags(blogIDs []int64) ([]models.BlogTag, error)
+	GetBlogStats(blogIDs []int64) ([]models.BlogStats, error)
+	GetBlogContents(blogIDs []int64) ([]models.BlogContent, error)
+	GetRelatedBlogIDs(blogID int64, limit int) ([]int64, error)
+	GetPublicBlogsByIDs(ids []int64) ([]models.Blog, error)
 }
 
 // BlogService defines the interface for blog business logic
 type BlogService interface {
-	Create(blog *models.Blog) error
+	// Create stores a new blog. blog.Status is the requested status (draft
+	// or published) and may be lowered to submitted for publication writers.
+	// accessPassword is required when blog.Visibility is password.
+	Create(blog *models.Blog, accessPassword string) error
 	GetByID(id int64) (*models.Blog, error)
-	GetByUserID(userID int64) ([]models.Blog, error)
+	// View is GetByID for a reader; unpublished posts are only visible to
+	// their authors, reviewers and publication editors, and published ones
+	// according to their visibility. viewerID is 0 when anonymous; password
+	// is what was supplied for password posts.
+	View(id, viewerID int64, password string) (*models.Blog, error)
+	// GetByUserID lists a user's published posts that viewerID may find,
+	// co-authored ones included.
+	GetByUserID(userID, viewerID int64) ([]models.Blog, error)
+	// GetMine is GetByUserID including drafts and submitted posts.
+	GetMine(userID int64) ([]models.Blog, error)
+	Publish(blogID, userID int64) (*models.Blog, error)
+	Unpublish(blogID, userID int64) (*models.Blog, error)
+	// SetVisibility changes who may read a post; password is required for
+	// password posts.
+	SetVisibility(blogID, userID int64, visibility, password string) (*models.Blog, error)
 	Update(blog *models.Blog) error
+	// Delete moves a post to its owner's trash.
 	Delete(blogID, userID int64) error
-	ListAll(limit, offset int64) ([]models.Blog, error)
-	Search(query string) ([]models.Blog, error)
+	Trash(userID int64) ([]models.Blog, error)
+	Restore(blogID, userID int64) (*models.Blog, error)
+	// Purge permanently deletes a post from the trash.
+	Purge(blogID, userID int64) error
+	// PurgeTrash permanently deletes posts that have been in the trash
+	// longer than retention.
+	PurgeTrash(retention time.Duration) error
+	ListAll(viewerID, limit, offset int64) ([]models.Blog, error)
+	Search(query string, viewerID int64) ([]models.Blog, error)
+	LikedBy(userID, limit, offset int64) ([]models.Blog, error)
+	// Include embeds related resources (IncludeAuthor, IncludeTags,
+	// IncludeStats) in blogs, batching the lookups for the whole slice.
+	Include(blogs []models.Blog, include []string) error
+	// LoadContent fills in Content on blogs from a list, which leave it
+	// out, with one query.
+	LoadContent(blogs []models.Blog) error
+	// Related suggests public posts to read after a blog the viewer may
+	// read (see View), cached per blog until it is edited.
+	Related(blogID, viewerID int64, password string) ([]models.Blog, error)
 }
 
 // blogService is the concrete implementation
 type blogService struct {
-	repo BlogRepository
+	repo         BlogRepository
+	reactions    ReactionRepository
+	series       SeriesRepository
+	coAuthors    CoAuthorRepository
+	publications PublicationRepository
+	reviews      ReviewRepository
+	follows      FollowRepository
+	users        UserRepository
+	related      *relatedCache
+	// passwordAttempts throttles guessing of post access passwords
+	passwordAttempts *passwordAttempts
 }
 
 // NewBlogService creates a new BlogService instance.
-func NewBlogService(r BlogRepository) BlogService {
-	return &blogService{repo: r}
+func NewBlogService(r BlogRepository, reactions ReactionRepository, series SeriesRepository, coAuthors CoAuthorRepository, publications PublicationRepository, reviews ReviewRepository, follows FollowRepository, users UserRepository) BlogService {
+	return &blogService{repo: r, reactions: reactions, series: series, coAuthors: coAuthors, publications: publications, reviews: reviews, follows: follows, users: users, related: newRelatedCache(), passwordAttempts: newPasswordAttempts()}
 }
 
 // Create validates and creates a new blog post.
-func (s *blogService) Create(blog *models.Blog) error {
+func (s *blogService) Create(blog *models.Blog, accessPassword string) error {
 	if strings.TrimSpace(blog.Title) == "" {
 		return fmt.Errorf("blog title cannot be empty")
 	}
 	if strings.TrimSpace(blog.Content) == "" {
 		return fmt.Errorf("blog content cannot be empty")
 	}
+	if err := setReadingStats(blog); err != nil {
+		return err
+	}
+	if err := s.initialStatus(blog); err != nil {
+		return err
+	}
+	hash, err := accessPasswordHash(&blog.Visibility, accessPassword)
+	if err != nil {
+		return err
+	}
+	if blog.Tags, err = normalizeTags(blog.Tags); err != nil {
+		return err
+	}
 
-	if err := s.repo.CreateBlog(blog); err != nil {
+	if err := s.repo.CreateBlog(blog, hash); err != nil {
 		log.Printf("Service error creating blog: %v", err)
 		return fmt.Errorf("failed to create blog post: %w", err)
 	}
+	if len(blog.Tags) > 0 {
+		if err := s.repo.SetBlogTags(blog.ID, blog.Tags); err != nil {
+			return err
+		}
+	}
+	// A writer's post goes straight to the publication's editors; start
+	// its review history here.
+	if blog.Status == BlogStatusSubmitted {
+		if err := s.reviews.AddEvent(blog.ID, blog.UserId, reviewActionSubmitted, ""); err != nil {
+			return err
+		}
+	}
+	blog.Reactions = map[string]int{}
 	return nil
 }
 
-// GetByID retrieves a single blog post by ID.
+// GetByID retrieves a single blog post by ID, with its place in a series
+// when it is part of one.
 func (s *blogService) GetByID(id int64) (*models.Blog, error) {
 	blog, err := s.repo.GetBlogByID(id)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving blog ID %d: %w", id, err)
 	}
-	return blog, nil
+
+	blogs := []models.Blog{*blog}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	if blogs[0].Series, err = seriesInfo(s.series, id); err != nil {
+		return nil, err
+	}
+	return &blogs[0], nil
+}
+
+// GetByUserID retrieves the published blogs a user owns or co-authors.
+func (s *blogService) GetByUserID(userID, viewerID int64) ([]models.Blog, error) {
+	return s.byAuthor(userID, viewerID, true)
+}
+
+func (s *blogService) GetMine(userID int64) ([]models.Blog, error) {
+	return s.byAuthor(userID, userID, false)
 }
 
-// GetByUserID retrieves all blogs for a specific user.
-func (s *blogService) GetByUserID(userID int64) ([]models.Blog, error) {
-	blogs, err := s.repo.GetBlogByUserID(userID)
+func (s *blogService) byAuthor(userID, viewerID int64, publishedOnly bool) ([]models.Blog, error) {
+	blogs, err := s.repo.GetBlogsByAuthor(userID, viewerID, publishedOnly)
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving blogs for user %d: %w", userID, err)
 	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
 	return blogs, nil
 }
 
@@ -80,15 +191,41 @@ func (s *blogService) Update(blog *models.Blog) error {
 		return fmt.Errorf("error retrieving blog ID %d: %w", blog.ID, err)
 	}
 
-	if existingBlog.UserId != blog.UserId {
-		return fmt.Errorf("unauthorized: you can only update your own blogs")
+	// blog.UserId is the editor; owners and editor co-authors may update
+	canEdit, err := canEditBlog(s.coAuthors, existingBlog, blog.UserId)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return fmt.Errorf("unauthorized: you can only update your own or co-authored blogs")
 	}
 
+	blog.UserId = existingBlog.UserId
 	blog.CreatedAt = existingBlog.CreatedAt
+	if err := setReadingStats(blog); err != nil {
+		return err
+	}
+	// nil Tags leaves the post's tags as they are
+	if blog.Tags != nil {
+		if blog.Tags, err = normalizeTags(blog.Tags); err != nil {
+			return err
+		}
+	}
 
 	if err := s.repo.UpdateBlog(blog); err != nil {
 		return fmt.Errorf("failed to update blog: %w", err)
 	}
+	if blog.Tags != nil {
+		if err := s.repo.SetBlogTags(blog.ID, blog.Tags); err != nil {
+			return err
+		}
+	}
+	s.related.invalidate(blog.ID)
+	counts, err := reactionCounts(s.reactions, []int64{blog.ID})
+	if err != nil {
+		return err
+	}
+	blog.Reactions = counts[blog.ID]
 	return nil
 }
 
@@ -101,8 +238,47 @@ func (s *blogService) Delete(blogID, userID int64) error {
 	return nil
 }
 
+func (s *blogService) Trash(userID int64) ([]models.Blog, error) {
+	blogs, err := s.repo.GetTrashedBlogs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving trash for user %d: %w", userID, err)
+	}
+	return blogs, nil
+}
+
+func (s *blogService) Restore(blogID, userID int64) (*models.Blog, error) {
+	if err := s.repo.RestoreBlog(blogID, userID); err != nil {
+		if strings.Contains(err.Error(), "no trashed blog found") {
+			return nil, ErrBlogNotFound
+		}
+		return nil, fmt.Errorf("restore failed: %w", err)
+	}
+	return s.GetByID(blogID)
+}
+
+func (s *blogService) Purge(blogID, userID int64) error {
+	if err := s.repo.PurgeBlog(blogID, userID); err != nil {
+		if strings.Contains(err.Error(), "no trashed blog found") {
+			return ErrBlogNotFound
+		}
+		return fmt.Errorf("purge failed: %w", err)
+	}
+	return nil
+}
+
+func (s *blogService) PurgeTrash(retention time.Duration) error {
+	n, err := s.repo.PurgeTrashedBlogs(time.Now().Add(-retention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("✅ Purged %d trashed blogs", n)
+	}
+	return nil
+}
+
 // ListAll retrieves all blogs with pagination.
-func (s *blogService) ListAll(limit, offset int64) ([]models.Blog, error) {
+func (s *blogService) ListAll(viewerID, limit, offset int64) ([]models.Blog, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -110,22 +286,47 @@ func (s *blogService) ListAll(limit, offset int64) ([]models.Blog, error) {
 		offset = 0
 	}
 
-	blogs, err := s.repo.GetAllBlogs(limit, offset)
+	blogs, err := s.repo.GetAllBlogs(viewerID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("error listing all blogs: %w", err)
 	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
 	return blogs, nil
 }
 
 // Search queries blogs based on a search term.
-func (s *blogService) Search(query string) ([]models.Blog, error) {
+func (s *blogService) Search(query string, viewerID int64) ([]models.Blog, error) {
 	if strings.TrimSpace(query) == "" {
 		return nil, fmt.Errorf("search query cannot be empty")
 	}
 
-	blogs, err := s.repo.SearchBlogs(query)
+	blogs, err := s.repo.SearchBlogs(query, viewerID)
 	if err != nil {
 		return nil, fmt.Errorf("error during blog search: %w", err)
 	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+// LikedBy lists the blogs a user liked, most recently liked first.
+func (s *blogService) LikedBy(userID, limit, offset int64) ([]models.Blog, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	blogs, err := s.repo.GetBlogsReactedByUser(userID, ReactionLike, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing liked blogs for user %d: %w", userID, err)
+	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
 	return blogs, nil
 }
\ No newline at end of file
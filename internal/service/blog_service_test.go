@@ -0,0 +1,124 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestBlogTrashHidesPost(t *testing.T) {
+	s, deps := newReadabilityFixture()
+	if err := s.Delete(publicPost, fixtureStranger); err == nil {
+		t.Fatal("a stranger moved the post to the trash")
+	}
+	if err := s.Delete(publicPost, fixtureAuthor); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(publicPost, fixtureAuthor); err == nil {
+		t.Error("trashing a post twice succeeded")
+	}
+
+	trash, err := s.Trash(fixtureAuthor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 2 || trash[0].ID != publicPost || trash[1].ID != trashedPost {
+		t.Errorf("trash = %+v, want the public and trashed posts", trash)
+	}
+
+	// Even its author can't read, edit or publish it any more
+	if _, err := s.View(publicPost, fixtureAuthor, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("View err = %v, want sql.ErrNoRows", err)
+	}
+	if _, err := s.View(publicPost, 0, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("anonymous View err = %v, want sql.ErrNoRows", err)
+	}
+	if err := s.Update(&models.Blog{ID: publicPost, UserId: fixtureAuthor, Title: "Edited", Content: "Edited"}); err == nil {
+		t.Error("Update of a trashed post succeeded")
+	}
+	if _, err := s.Unpublish(publicPost, fixtureAuthor); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("Unpublish err = %v, want ErrBlogNotFound", err)
+	}
+	if deps.blogs.blogs[publicPost].Title != "public" || deps.blogs.blogs[publicPost].Status != BlogStatusPublished {
+		t.Errorf("trashed post changed: %+v", deps.blogs.blogs[publicPost])
+	}
+}
+
+func TestBlogRestore(t *testing.T) {
+	s, _ := newReadabilityFixture()
+
+	if _, err := s.Restore(trashedPost, fixtureStranger); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("stranger restoring: err = %v, want ErrBlogNotFound", err)
+	}
+	if _, err := s.Restore(publicPost, fixtureAuthor); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("restoring a live post: err = %v, want ErrBlogNotFound", err)
+	}
+
+	restored, err := s.Restore(trashedPost, fixtureAuthor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.ID != trashedPost || restored.DeletedAt != nil {
+		t.Errorf("restored = %+v, want post %d out of the trash", restored, trashedPost)
+	}
+	if _, err := s.View(trashedPost, fixtureStranger, ""); err != nil {
+		t.Errorf("View after restoring: %v", err)
+	}
+	trash, err := s.Trash(fixtureAuthor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 0 {
+		t.Errorf("trash = %+v, want empty", trash)
+	}
+}
+
+func TestBlogPurge(t *testing.T) {
+	s, deps := newReadabilityFixture()
+
+	if err := s.Purge(trashedPost, fixtureStranger); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("stranger purging: err = %v, want ErrBlogNotFound", err)
+	}
+	// Only posts in the trash can be purged
+	if err := s.Purge(publicPost, fixtureAuthor); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("purging a live post: err = %v, want ErrBlogNotFound", err)
+	}
+	if _, ok := deps.blogs.blogs[publicPost]; !ok {
+		t.Fatal("live post was purged")
+	}
+
+	if err := s.Purge(trashedPost, fixtureAuthor); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := deps.blogs.blogs[trashedPost]; ok {
+		t.Error("purged post still stored")
+	}
+	if _, err := s.Restore(trashedPost, fixtureAuthor); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("restoring a purged post: err = %v, want ErrBlogNotFound", err)
+	}
+}
+
+func TestBlogPurgeTrash(t *testing.T) {
+	week := 7 * 24 * time.Hour
+	old, recent := time.Now().Add(-2*week), time.Now().Add(-time.Hour)
+	s, deps := newTestBlogService(
+		models.Blog{ID: 1, UserId: 1, Title: "old", DeletedAt: &old},
+		models.Blog{ID: 2, UserId: 1, Title: "recent", DeletedAt: &recent},
+		models.Blog{ID: 3, UserId: 1, Title: "live"},
+	)
+
+	if err := s.PurgeTrash(week); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := deps.blogs.blogs[1]; ok {
+		t.Error("post trashed two weeks ago survived a week's retention")
+	}
+	for _, id := range []int64{2, 3} {
+		if _, ok := deps.blogs.blogs[id]; !ok {
+			t.Errorf("post %d was purged", id)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	maxBlogSummaryRunes = 500
+	blogExcerptRunes    = 280
+	wordsPerMinute      = 200
+)
+
+// setReadingStats validates the author's summary and fills in the excerpt,
+// word count and reading time stored alongside the content.
+func setReadingStats(blog *models.Blog) error {
+	blog.Summary = strings.Join(strings.Fields(blog.Summary), " ")
+	if utf8.RuneCountInString(blog.Summary) > maxBlogSummaryRunes {
+		return fmt.Errorf("%w: summary must be at most %d characters", ErrInvalidInput, maxBlogSummaryRunes)
+	}
+
+	words := strings.Fields(blog.Content)
+	blog.WordCount = len(words)
+	blog.ReadingTime = (blog.WordCount + wordsPerMinute - 1) / wordsPerMinute
+
+	if blog.Summary != "" {
+		blog.Excerpt = blog.Summary
+	} else {
+		blog.Excerpt = truncateRunes(strings.Join(words, " "), blogExcerptRunes)
+	}
+	return nil
+}
+
+// truncateRunes shortens s to at most n runes, preferring to cut between
+// words, and marks the cut with "…".
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut := string([]rune(s)[:n])
+	if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimSpace(cut) + "…"
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestSetReadingStats(t *testing.T) {
+	tests := []struct {
+		name        string
+		words       int
+		wantMinutes int
+	}{
+		{"empty", 0, 0},
+		{"one word", 1, 1},
+		{"exactly one minute", 200, 1},
+		{"just over one minute", 201, 2},
+		{"several minutes", 1000, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blog := &models.Blog{Content: strings.TrimSpace(strings.Repeat("word ", tt.words))}
+			if err := setReadingStats(blog); err != nil {
+				t.Fatalf("setReadingStats: %v", err)
+			}
+			if blog.WordCount != tt.words {
+				t.Errorf("WordCount = %d, want %d", blog.WordCount, tt.words)
+			}
+			if blog.ReadingTime != tt.wantMinutes {
+				t.Errorf("ReadingTime = %d, want %d", blog.ReadingTime, tt.wantMinutes)
+			}
+		})
+	}
+}
+
+func TestSetReadingStatsExcerpt(t *testing.T) {
+	blog := &models.Blog{Content: "First   line\n\nsecond\tline"}
+	if err := setReadingStats(blog); err != nil {
+		t.Fatal(err)
+	}
+	if blog.Excerpt != "First line second line" {
+		t.Errorf("Excerpt = %q, want the content with whitespace collapsed", blog.Excerpt)
+	}
+
+	blog = &models.Blog{Content: "The body.", Summary: "  An   author's\nsummary. "}
+	if err := setReadingStats(blog); err != nil {
+		t.Fatal(err)
+	}
+	if blog.Summary != "An author's summary." {
+		t.Errorf("Summary = %q, want whitespace collapsed", blog.Summary)
+	}
+	if blog.Excerpt != blog.Summary {
+		t.Errorf("Excerpt = %q, want the summary", blog.Excerpt)
+	}
+}
+
+func TestSetReadingStatsSummaryTooLong(t *testing.T) {
+	blog := &models.Blog{Summary: strings.Repeat("é", maxBlogSummaryRunes)}
+	if err := setReadingStats(blog); err != nil {
+		t.Errorf("a %d-rune summary was rejected: %v", maxBlogSummaryRunes, err)
+	}
+
+	blog = &models.Blog{Summary: strings.Repeat("é", maxBlogSummaryRunes+1)}
+	if err := setReadingStats(blog); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "hello world", 20, "hello world"},
+		{"exact", "hello", 5, "hello"},
+		{"trims", "  hello  ", 5, "hello"},
+		{"cuts between words", "the quick brown fox", 12, "the quick…"},
+		{"cuts a long word", "supercalifragilistic", 5, "super…"},
+		{"cuts inside a word when the last space is early", "a bcdefghijklmnop", 10, "a bcdefghi…"},
+		{"counts runes", "ééééé ééééé", 8, "ééééé…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncateRunes(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("truncateRunes(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncateRunes(%q, %d) returned invalid UTF-8", tt.s, tt.n)
+			}
+		})
+	}
+}
+
+func TestExcerptLength(t *testing.T) {
+	blog := &models.Blog{Content: strings.Repeat("lorem ipsum ", 100)}
+	if err := setReadingStats(blog); err != nil {
+		t.Fatal(err)
+	}
+	if n := utf8.RuneCountInString(blog.Excerpt); n > blogExcerptRunes+1 {
+		t.Errorf("excerpt is %d runes, want at most %d plus the ellipsis", n, blogExcerptRunes)
+	}
+	if !strings.HasSuffix(blog.Excerpt, "…") {
+		t.Errorf("excerpt %q is not marked as cut", blog.Excerpt)
+	}
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Blog visibilities decide who may read a published post. Only public
+// posts appear in listings, search and feeds; followers-only posts also
+// appear in listings for the owner's followers.
+const (
+	BlogVisibilityPublic    = "public"
+	BlogVisibilityUnlisted  = "unlisted"
+	BlogVisibilityFollowers = "followers"
+	BlogVisibilityPrivate   = "private"
+	BlogVisibilityPassword  = "password"
+)
+
+const (
+	minBlogPasswordRunes = 4
+	maxBlogPasswordRunes = 128
+
+	// maxBlogPasswordFailures wrong passwords for a post are allowed freely;
+	// each one after that locks the post for twice as long as the last,
+	// starting at blogPasswordBackoff and capped at blogPasswordMaxLockout.
+	// The count is forgotten once the post has gone blogPasswordMaxLockout
+	// without a wrong password.
+	maxBlogPasswordFailures = 5
+	blogPasswordBackoff     = time.Second
+	blogPasswordMaxLockout  = 15 * time.Minute
+)
+
+var (
+	ErrBlogPasswordRequired = errors.New("this post is password-protected")
+	ErrFollowersOnly        = errors.New("only followers of the author can read this post")
+	ErrTooManyPasswordTries = errors.New("too many wrong passwords for this post, try again later")
+)
+
+// passwordAttempts counts wrong passwords per post, so guessing is
+// throttled before the costly hash comparison runs. Counting per post
+// rather than per client means guesses from many addresses add up, and
+// readers behind a shared proxy aren't locked out by one another for long.
+type passwordAttempts struct {
+	mu       sync.Mutex
+	failures map[int64]passwordFailures
+}
+
+type passwordFailures struct {
+	count       int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func newPasswordAttempts() *passwordAttempts {
+	return &passwordAttempts{failures: make(map[int64]passwordFailures)}
+}
+
+// allowed reports whether another password may be tried for the post.
+func (a *passwordAttempts) allowed(blogID int64) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return !time.Now().Before(a.failures[blogID].lockedUntil)
+}
+
+func (a *passwordAttempts) fail(blogID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	f, ok := a.failures[blogID]
+	if !ok || now.Sub(f.lastFailure) > blogPasswordMaxLockout {
+		// Drop stale entries now and then so the map stays small
+		if len(a.failures) > 10000 {
+			for id, v := range a.failures {
+				if now.Sub(v.lastFailure) > blogPasswordMaxLockout {
+					delete(a.failures, id)
+				}
+			}
+		}
+		f = passwordFailures{}
+	}
+	f.count++
+	f.lastFailure = now
+	if over := f.count - maxBlogPasswordFailures; over > 0 {
+		lockout := blogPasswordMaxLockout
+		if over <= 20 {
+			lockout = min(blogPasswordBackoff<<(over-1), blogPasswordMaxLockout)
+		}
+		f.lockedUntil = now.Add(lockout)
+	}
+	a.failures[blogID] = f
+}
+
+func (a *passwordAttempts) reset(blogID int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.failures, blogID)
+}
+
+// canSave reports whether userID may bookmark a blog or put it on a reading
+// list: it must be one that shows up there afterwards, which
+// linkedBlogFilter in the blog repository limits to published public,
+// unlisted, and followers-only posts by authors userID follows.
+func canSave(follows FollowRepository, blog *models.Blog, userID int64) (bool, error) {
+	if blog.Status != BlogStatusPublished || blog.DeletedAt != nil {
+		return false, nil
+	}
+	switch blog.Visibility {
+	case BlogVisibilityPublic, BlogVisibilityUnlisted:
+		return true, nil
+	case BlogVisibilityFollowers:
+		following, err := follows.IsFollowing(userID, blog.UserId)
+		if err != nil {
+			return false, fmt.Errorf("error checking followers of user %d: %w", blog.UserId, err)
+		}
+		return following, nil
+	default:
+		return false, nil
+	}
+}
+
+// accessPasswordHash normalises visibility and, for password posts,
+// validates and hashes password. It returns nil for other visibilities.
+func accessPasswordHash(visibility *string, password string) (*string, error) {
+	v := strings.ToLower(strings.TrimSpace(*visibility))
+	if v == "" {
+		v = BlogVisibilityPublic
+	}
+	switch v {
+	case BlogVisibilityPublic, BlogVisibilityUnlisted, BlogVisibilityFollowers, BlogVisibilityPrivate:
+		*visibility = v
+		return nil, nil
+	case BlogVisibilityPassword:
+	default:
+		return nil, fmt.Errorf("%w: visibility must be one of %s, %s, %s, %s or %s", ErrInvalidInput,
+			BlogVisibilityPublic, BlogVisibilityUnlisted, BlogVisibilityFollowers, BlogVisibilityPrivate, BlogVisibilityPassword)
+	}
+
+	if n := utf8.RuneCountInString(password); n < minBlogPasswordRunes || n > maxBlogPasswordRunes {
+		return nil, fmt.Errorf("%w: password-protected posts need a password of %d to %d characters",
+			ErrInvalidInput, minBlogPasswordRunes, maxBlogPasswordRunes)
+	}
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash post password: %w", err)
+	}
+	*visibility = v
+	return &hash, nil
+}
+
+// checkAccess decides whether viewerID (0 when anonymous) may read a
+// published blog, given the access password they supplied, if any.
+// Authors can always read their posts.
+func (s *blogService) checkAccess(blog *models.Blog, viewerID int64, password string) error {
+	if blog.Visibility == BlogVisibilityPublic || blog.Visibility == BlogVisibilityUnlisted {
+		return nil
+	}
+	if viewerID != 0 {
+		role, err := blogRole(s.coAuthors, blog, viewerID)
+		if err != nil {
+			return err
+		}
+		if role != "" {
+			return nil
+		}
+	}
+
+	switch blog.Visibility {
+	case BlogVisibilityFollowers:
+		if viewerID == 0 {
+			return ErrFollowersOnly
+		}
+		following, err := s.follows.IsFollowing(viewerID, blog.UserId)
+		if err != nil {
+			return fmt.Errorf("error checking followers of user %d: %w", blog.UserId, err)
+		}
+		if !following {
+			return ErrFollowersOnly
+		}
+		return nil
+	case BlogVisibilityPassword:
+		if password == "" {
+			return ErrBlogPasswordRequired
+		}
+		if !s.passwordAttempts.allowed(blog.ID) {
+			return ErrTooManyPasswordTries
+		}
+		hash, err := s.repo.GetBlogAccessPasswordHash(blog.ID)
+		if err != nil {
+			return fmt.Errorf("error retrieving password of blog %d: %w", blog.ID, err)
+		}
+		if hash == "" || !auth.VerifyPassword(hash, password) {
+			s.passwordAttempts.fail(blog.ID)
+			return ErrBlogPasswordRequired
+		}
+		s.passwordAttempts.reset(blog.ID)
+		return nil
+	default:
+		return ErrBlogNotFound
+	}
+}
+
+func (s *blogService) SetVisibility(blogID, userID int64, visibility, password string) (*models.Blog, error) {
+	blog, err := s.repo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	if err := s.canChangeStatus(blog, userID); err != nil {
+		return nil, err
+	}
+
+	hash, err := accessPasswordHash(&visibility, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetBlogVisibility(blogID, visibility, hash); err != nil {
+		return nil, err
+	}
+	return s.GetByID(blogID)
+}
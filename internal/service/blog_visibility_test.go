@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func newPasswordPost(t *testing.T) (*blogService, int64) {
+	t.Helper()
+	s, deps := newTestBlogService(models.Blog{ID: 1, UserId: 9, Visibility: BlogVisibilityPassword})
+	hash, err := auth.HashPassword("open sesame")
+	if err != nil {
+		t.Fatal(err)
+	}
+	deps.blogs.passwords[1] = hash
+	return s, 1
+}
+
+func TestViewPasswordPost(t *testing.T) {
+	s, id := newPasswordPost(t)
+
+	if _, err := s.View(id, 0, ""); !errors.Is(err, ErrBlogPasswordRequired) {
+		t.Errorf("no password: err = %v, want ErrBlogPasswordRequired", err)
+	}
+	if _, err := s.View(id, 0, "wrong"); !errors.Is(err, ErrBlogPasswordRequired) {
+		t.Errorf("wrong password: err = %v, want ErrBlogPasswordRequired", err)
+	}
+	if _, err := s.View(id, 0, "open sesame"); err != nil {
+		t.Errorf("right password: %v", err)
+	}
+	if _, err := s.View(id, 9, ""); err != nil {
+		t.Errorf("the author needs no password: %v", err)
+	}
+}
+
+func TestViewPasswordPostLockout(t *testing.T) {
+	s, id := newPasswordPost(t)
+
+	for i := 0; i < maxBlogPasswordFailures; i++ {
+		if _, err := s.View(id, 0, "wrong"); !errors.Is(err, ErrBlogPasswordRequired) {
+			t.Fatalf("guess %d: err = %v, want ErrBlogPasswordRequired", i+1, err)
+		}
+	}
+	// The free guesses are used up: the next wrong one locks the post,
+	// for everyone and even for the right password
+	if _, err := s.View(id, 0, "wrong"); !errors.Is(err, ErrBlogPasswordRequired) {
+		t.Fatalf("err = %v, want ErrBlogPasswordRequired", err)
+	}
+	if _, err := s.View(id, 0, "open sesame"); !errors.Is(err, ErrTooManyPasswordTries) {
+		t.Errorf("while locked: err = %v, want ErrTooManyPasswordTries", err)
+	}
+
+	// Once the lock runs out the right password works and clears the count
+	s.passwordAttempts.mu.Lock()
+	f := s.passwordAttempts.failures[id]
+	f.lockedUntil = time.Now().Add(-time.Second)
+	s.passwordAttempts.failures[id] = f
+	s.passwordAttempts.mu.Unlock()
+	if _, err := s.View(id, 0, "open sesame"); err != nil {
+		t.Fatalf("after the lock: %v", err)
+	}
+	if _, ok := s.passwordAttempts.failures[id]; ok {
+		t.Error("the right password did not clear the failure count")
+	}
+}
+
+func TestPasswordAttemptsBackoff(t *testing.T) {
+	a := newPasswordAttempts()
+	for i := 0; i < maxBlogPasswordFailures; i++ {
+		a.fail(1)
+		if !a.allowed(1) {
+			t.Fatalf("locked after %d failures", i+1)
+		}
+	}
+
+	var last time.Duration
+	for i := 1; i <= 12; i++ {
+		// Pretend the previous lock ran out
+		f := a.failures[1]
+		f.lockedUntil = time.Time{}
+		a.failures[1] = f
+
+		a.fail(1)
+		if a.allowed(1) {
+			t.Fatalf("not locked after %d extra failures", i)
+		}
+		lockout := time.Until(a.failures[1].lockedUntil).Round(time.Second)
+		if lockout > blogPasswordMaxLockout {
+			t.Fatalf("lockout %v exceeds the cap", lockout)
+		}
+		if lockout < last {
+			t.Fatalf("lockout shrank from %v to %v", last, lockout)
+		}
+		last = lockout
+	}
+	if last != blogPasswordMaxLockout {
+		t.Errorf("lockout reached %v, want the cap %v", last, blogPasswordMaxLockout)
+	}
+
+	// Other posts are unaffected
+	if !a.allowed(2) {
+		t.Error("another post is locked")
+	}
+
+	// The count is forgotten after a quiet period
+	f := a.failures[1]
+	f.lastFailure = time.Now().Add(-blogPasswordMaxLockout - time.Minute)
+	f.lockedUntil = time.Time{}
+	a.failures[1] = f
+	a.fail(1)
+	if !a.allowed(1) || a.failures[1].count != 1 {
+		t.Errorf("count = %d after a quiet period, want 1 and unlocked", a.failures[1].count)
+	}
+}
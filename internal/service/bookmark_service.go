@@ -0,0 +1,113 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+var ErrBookmarkNotFound = errors.New("bookmark not found")
+
+// BookmarkRepository defines the interface for bookmark data operations
+type BookmarkRepository interface {
+	AddBookmark(userID, blogID int64) (bool, error)
+	RemoveBookmark(userID, blogID int64) error
+	GetBookmarkedIDs(userID int64, blogIDs []int64) ([]int64, error)
+}
+
+// BookmarkService manages private "read later" bookmarks.
+type BookmarkService interface {
+	Add(userID, blogID int64) error
+	Remove(userID, blogID int64) error
+	List(userID, limit, offset int64) ([]models.Blog, error)
+	// MarkBookmarked sets Bookmarked on each blog for the given viewer.
+	MarkBookmarked(userID int64, blogs []models.Blog) error
+}
+
+type bookmarkService struct {
+	repo      BookmarkRepository
+	blogRepo  BlogRepository
+	follows   FollowRepository
+	reactions ReactionRepository
+}
+
+func NewBookmarkService(r BookmarkRepository, blogRepo BlogRepository, follows FollowRepository, reactions ReactionRepository) BookmarkService {
+	return &bookmarkService{repo: r, blogRepo: blogRepo, follows: follows, reactions: reactions}
+}
+
+// Add bookmarks a blog; bookmarking it again is not an error. Blogs the
+// user could not find in their bookmarks afterwards are reported as
+// ErrBlogNotFound.
+func (s *bookmarkService) Add(userID, blogID int64) error {
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	ok, err := canSave(s.follows, blog, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBlogNotFound
+	}
+	if _, err := s.repo.AddBookmark(userID, blogID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *bookmarkService) Remove(userID, blogID int64) error {
+	if err := s.repo.RemoveBookmark(userID, blogID); err != nil {
+		if strings.Contains(err.Error(), "no bookmark found") {
+			return ErrBookmarkNotFound
+		}
+		return fmt.Errorf("removing bookmark failed: %w", err)
+	}
+	return nil
+}
+
+// List returns the user's bookmarks, most recently saved first.
+func (s *bookmarkService) List(userID, limit, offset int64) ([]models.Blog, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	blogs, err := s.blogRepo.GetBookmarkedBlogs(userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing bookmarks for user %d: %w", userID, err)
+	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	bookmarked := true
+	for i := range blogs {
+		blogs[i].Bookmarked = &bookmarked
+	}
+	return blogs, nil
+}
+
+func (s *bookmarkService) MarkBookmarked(userID int64, blogs []models.Blog) error {
+	ids := make([]int64, len(blogs))
+	for i := range blogs {
+		ids[i] = blogs[i].ID
+	}
+	saved, err := s.repo.GetBookmarkedIDs(userID, ids)
+	if err != nil {
+		return fmt.Errorf("error checking bookmarks: %w", err)
+	}
+
+	set := make(map[int64]bool, len(saved))
+	for _, id := range saved {
+		set[id] = true
+	}
+	for i := range blogs {
+		bookmarked := set[blogs[i].ID]
+		blogs[i].Bookmarked = &bookmarked
+	}
+	return nil
+}
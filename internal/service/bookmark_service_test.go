@@ -0,0 +1,108 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// fakeBookmarkRepo keeps bookmarks as user -> blogs.
+type fakeBookmarkRepo struct {
+	BookmarkRepository
+	saved map[int64]map[int64]bool
+}
+
+func (r *fakeBookmarkRepo) AddBookmark(userID, blogID int64) (bool, error) {
+	if r.saved == nil {
+		r.saved = map[int64]map[int64]bool{}
+	}
+	if r.saved[userID] == nil {
+		r.saved[userID] = map[int64]bool{}
+	}
+	added := !r.saved[userID][blogID]
+	r.saved[userID][blogID] = true
+	return added, nil
+}
+
+func (r *fakeBookmarkRepo) RemoveBookmark(userID, blogID int64) error {
+	if !r.saved[userID][blogID] {
+		return fmt.Errorf("no bookmark found for blog %d", blogID)
+	}
+	delete(r.saved[userID], blogID)
+	return nil
+}
+
+func (r *fakeBookmarkRepo) GetBookmarkedIDs(userID int64, blogIDs []int64) ([]int64, error) {
+	var ids []int64
+	for _, id := range blogIDs {
+		if r.saved[userID][id] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// saveTests are the posts of the readability fixture a user may save:
+// those their bookmarks and reading lists go on to show.
+var saveTests = []struct {
+	name   string
+	blogID int64
+	userID int64
+	want   error
+}{
+	{"public", publicPost, fixtureStranger, nil},
+	{"unlisted", unlistedPost, fixtureStranger, nil},
+	{"followers by a follower", followersPost, fixtureFollower, nil},
+	{"followers by a stranger", followersPost, fixtureStranger, ErrBlogNotFound},
+	{"private", privatePost, fixtureStranger, ErrBlogNotFound},
+	{"draft", draftPost, fixtureStranger, ErrBlogNotFound},
+	{"draft by its author", draftPost, fixtureAuthor, ErrBlogNotFound},
+	{"trashed", trashedPost, fixtureStranger, ErrBlogNotFound},
+	{"missing", 99, fixtureStranger, ErrBlogNotFound},
+}
+
+func TestBookmarkAdd(t *testing.T) {
+	for _, tt := range saveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, deps := newReadabilityFixture()
+			bookmarks := &fakeBookmarkRepo{}
+			s := NewBookmarkService(bookmarks, deps.blogs, deps.follows, deps.reactions)
+
+			if err := s.Add(tt.userID, tt.blogID); !errors.Is(err, tt.want) {
+				t.Fatalf("Add err = %v, want %v", err, tt.want)
+			}
+			if saved := bookmarks.saved[tt.userID][tt.blogID]; saved != (tt.want == nil) {
+				t.Errorf("bookmark stored = %v, want %v", saved, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestBookmarkRemoveAndMark(t *testing.T) {
+	_, deps := newReadabilityFixture()
+	s := NewBookmarkService(&fakeBookmarkRepo{}, deps.blogs, deps.follows, deps.reactions)
+
+	// Saving twice is not an error
+	for i := 0; i < 2; i++ {
+		if err := s.Add(fixtureStranger, publicPost); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	blogs := []models.Blog{{ID: publicPost}, {ID: unlistedPost}}
+	if err := s.MarkBookmarked(fixtureStranger, blogs); err != nil {
+		t.Fatal(err)
+	}
+	if !*blogs[0].Bookmarked || *blogs[1].Bookmarked {
+		t.Errorf("bookmarked = %v, %v, want true, false", *blogs[0].Bookmarked, *blogs[1].Bookmarked)
+	}
+
+	if err := s.Remove(fixtureStranger, publicPost); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if err := s.Remove(fixtureStranger, publicPost); !errors.Is(err, ErrBookmarkNotFound) {
+		t.Errorf("removing again: err = %v, want ErrBookmarkNotFound", err)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Co-author roles. Editors may change a post; viewers may only read it.
+// Deleting a post and managing its co-authors stay with the owner.
+const (
+	CoAuthorRoleEditor = "editor"
+	CoAuthorRoleViewer = "viewer"
+)
+
+var (
+	ErrCoAuthorNotFound = errors.New("co-author not found")
+	ErrNotBlogOwner     = errors.New("only the owner of the post can do that")
+)
+
+// CoAuthorRepository defines the interface for co-author data operations
+type CoAuthorRepository interface {
+	InviteCoAuthor(blogID, userID int64, role string, invitedBy int64) error
+	GetCoAuthor(blogID, userID int64) (*models.BlogCoAuthor, error)
+	GetCoAuthorsByBlog(blogID int64) ([]models.BlogCoAuthor, error)
+	GetPendingInvites(userID int64) ([]models.CoAuthorInvite, error)
+	AcceptInvite(blogID, userID int64) error
+	RemoveCoAuthor(blogID, userID int64) error
+}
+
+// CoAuthorService manages who besides the owner can work on a post.
+type CoAuthorService interface {
+	// Invite asks a user to co-author the post, or changes the role of an
+	// existing co-author.
+	Invite(ownerID, blogID int64, username, role string) (*models.BlogCoAuthor, error)
+	// List returns the post's co-authors. Pending invites are only shown to
+	// the owner and co-authors.
+	List(blogID, viewerID int64) ([]models.BlogCoAuthor, error)
+	// Remove takes a co-author off a post; owners may remove anyone, others
+	// only themselves.
+	Remove(actorID, blogID, userID int64) error
+	Accept(userID, blogID int64) error
+	PendingInvites(userID int64) ([]models.CoAuthorInvite, error)
+}
+
+type coAuthorService struct {
+	repo     CoAuthorRepository
+	blogRepo BlogRepository
+	userRepo UserRepository
+}
+
+func NewCoAuthorService(r CoAuthorRepository, blogRepo BlogRepository, userRepo UserRepository) CoAuthorService {
+	return &coAuthorService{repo: r, blogRepo: blogRepo, userRepo: userRepo}
+}
+
+// blogRole returns userID's role on a blog: "owner", an accepted co-author
+// role, or "" when they have none.
+func blogRole(repo CoAuthorRepository, blog *models.Blog, userID int64) (string, error) {
+	if blog.UserId == userID {
+		return "owner", nil
+	}
+	coAuthor, err := repo.GetCoAuthor(blog.ID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error checking co-authors of blog %d: %w", blog.ID, err)
+	}
+	if coAuthor.AcceptedAt == nil {
+		return "", nil
+	}
+	return coAuthor.Role, nil
+}
+
+// canEditBlog reports whether userID may change a blog's content.
+func canEditBlog(repo CoAuthorRepository, blog *models.Blog, userID int64) (bool, error) {
+	role, err := blogRole(repo, blog, userID)
+	return role == "owner" || role == CoAuthorRoleEditor, err
+}
+
+func (s *coAuthorService) Invite(ownerID, blogID int64, username, role string) (*models.BlogCoAuthor, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = CoAuthorRoleEditor
+	}
+	if role != CoAuthorRoleEditor && role != CoAuthorRoleViewer {
+		return nil, fmt.Errorf("%w: role must be %q or %q", ErrInvalidInput, CoAuthorRoleEditor, CoAuthorRoleViewer)
+	}
+
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	if blog.UserId != ownerID {
+		return nil, ErrNotBlogOwner
+	}
+
+	user, err := s.userRepo.GetUserByUsername(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+	if err != nil || user.Status != UserStatusActive {
+		return nil, ErrUserNotFound
+	}
+	if user.ID == ownerID {
+		return nil, fmt.Errorf("%w: you already own this post", ErrInvalidInput)
+	}
+
+	if err := s.repo.InviteCoAuthor(blogID, user.ID, role, ownerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetCoAuthor(blogID, user.ID)
+}
+
+func (s *coAuthorService) List(blogID, viewerID int64) ([]models.BlogCoAuthor, error) {
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+
+	coAuthors, err := s.repo.GetCoAuthorsByBlog(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving co-authors of blog %d: %w", blogID, err)
+	}
+
+	role, err := blogRole(s.repo, blog, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "" {
+		return coAuthors, nil
+	}
+
+	accepted := []models.BlogCoAuthor{}
+	for _, c := range coAuthors {
+		if c.AcceptedAt != nil {
+			accepted = append(accepted, c)
+		}
+	}
+	return accepted, nil
+}
+
+func (s *coAuthorService) Remove(actorID, blogID, userID int64) error {
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	if actorID != userID && blog.UserId != actorID {
+		return ErrNotBlogOwner
+	}
+
+	if err := s.repo.RemoveCoAuthor(blogID, userID); err != nil {
+		if strings.Contains(err.Error(), "no co-author found") {
+			return ErrCoAuthorNotFound
+		}
+		return fmt.Errorf("removing co-author failed: %w", err)
+	}
+	return nil
+}
+
+func (s *coAuthorService) Accept(userID, blogID int64) error {
+	if err := s.repo.AcceptInvite(blogID, userID); err != nil {
+		if strings.Contains(err.Error(), "no co-author invite found") {
+			return ErrCoAuthorNotFound
+		}
+		return fmt.Errorf("accepting invite failed: %w", err)
+	}
+	return nil
+}
+
+func (s *coAuthorService) PendingInvites(userID int64) ([]models.CoAuthorInvite, error) {
+	invites, err := s.repo.GetPendingInvites(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving co-author invites for user %d: %w", userID, err)
+	}
+	return invites, nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// coAuthorTest is a private post by owner with an accepted editor, an
+// accepted viewer and a pending editor invite.
+type coAuthorTest struct {
+	blogs                          *blogService
+	coAuthors                      CoAuthorService
+	blogRepo                       *fakeBlogRepo
+	owner, editor, viewer, invited *models.User
+	stranger                       *models.User
+}
+
+const coAuthoredPost = 1
+
+func newCoAuthorTest(t *testing.T) coAuthorTest {
+	t.Helper()
+	users := &fakeUserRepo{}
+	var c coAuthorTest
+	for _, u := range []struct {
+		name string
+		user **models.User
+	}{{"owner", &c.owner}, {"editor", &c.editor}, {"viewer", &c.viewer}, {"invited", &c.invited}, {"stranger", &c.stranger}} {
+		*u.user = users.add(models.User{Username: u.name, Status: UserStatusActive})
+	}
+
+	var deps blogTestDeps
+	c.blogs, deps = newTestBlogService(models.Blog{ID: coAuthoredPost, UserId: c.owner.ID, Title: "Joint work",
+		Content: "First draft", Visibility: BlogVisibilityPrivate})
+	c.blogRepo = deps.blogs
+	c.coAuthors = NewCoAuthorService(deps.coAuthors, deps.blogs, users)
+
+	for _, invite := range []struct {
+		user   *models.User
+		role   string
+		accept bool
+	}{{c.editor, CoAuthorRoleEditor, true}, {c.viewer, CoAuthorRoleViewer, true}, {c.invited, CoAuthorRoleEditor, false}} {
+		if _, err := c.coAuthors.Invite(c.owner.ID, coAuthoredPost, "@"+invite.user.Username, invite.role); err != nil {
+			t.Fatalf("Invite %s: %v", invite.user.Username, err)
+		}
+		if invite.accept {
+			if err := c.coAuthors.Accept(invite.user.ID, coAuthoredPost); err != nil {
+				t.Fatalf("Accept %s: %v", invite.user.Username, err)
+			}
+		}
+	}
+	return c
+}
+
+func TestCoAuthorRights(t *testing.T) {
+	c := newCoAuthorTest(t)
+	tests := []struct {
+		name               string
+		user               *models.User
+		canRead, canUpdate bool
+	}{
+		{"owner", c.owner, true, true},
+		{"editor", c.editor, true, true},
+		{"viewer", c.viewer, true, false},
+		{"pending invite", c.invited, false, false},
+		{"stranger", c.stranger, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.blogs.View(coAuthoredPost, tt.user.ID, "")
+			if (err == nil) != tt.canRead {
+				t.Errorf("View err = %v, want readable %v", err, tt.canRead)
+			}
+
+			update := &models.Blog{ID: coAuthoredPost, UserId: tt.user.ID, Title: "Joint work", Content: "Edited by " + tt.user.Username}
+			err = c.blogs.Update(update)
+			if (err == nil) != tt.canUpdate {
+				t.Errorf("Update err = %v, want allowed %v", err, tt.canUpdate)
+			}
+			// Edits are saved as the owner's post
+			if err == nil && (update.UserId != c.owner.ID || c.blogRepo.blogs[coAuthoredPost].Content != update.Content) {
+				t.Errorf("update not stored on the owner's post: %+v", c.blogRepo.blogs[coAuthoredPost])
+			}
+		})
+	}
+}
+
+func TestCoAuthorDeleteIsOwnerOnly(t *testing.T) {
+	c := newCoAuthorTest(t)
+	for _, u := range []*models.User{c.editor, c.viewer} {
+		if err := c.blogs.Delete(coAuthoredPost, u.ID); err == nil {
+			t.Errorf("%s deleted the post", u.Username)
+		}
+	}
+	if c.blogRepo.blogs[coAuthoredPost].DeletedAt != nil {
+		t.Fatal("the post was trashed by a co-author")
+	}
+	if err := c.blogs.Delete(coAuthoredPost, c.owner.ID); err != nil {
+		t.Fatalf("owner Delete: %v", err)
+	}
+	if c.blogRepo.blogs[coAuthoredPost].DeletedAt == nil {
+		t.Error("the owner's delete did not trash the post")
+	}
+}
+
+func TestCoAuthorManagementIsOwnerOnly(t *testing.T) {
+	c := newCoAuthorTest(t)
+
+	if _, err := c.coAuthors.Invite(c.editor.ID, coAuthoredPost, c.stranger.Username, CoAuthorRoleViewer); !errors.Is(err, ErrNotBlogOwner) {
+		t.Errorf("editor Invite: err = %v, want ErrNotBlogOwner", err)
+	}
+	if _, err := c.coAuthors.Invite(c.owner.ID, coAuthoredPost, c.stranger.Username, "admin"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown role: err = %v, want ErrInvalidInput", err)
+	}
+	if err := c.coAuthors.Remove(c.editor.ID, coAuthoredPost, c.viewer.ID); !errors.Is(err, ErrNotBlogOwner) {
+		t.Errorf("editor removing the viewer: err = %v, want ErrNotBlogOwner", err)
+	}
+
+	// Co-authors may step down themselves
+	if err := c.coAuthors.Remove(c.viewer.ID, coAuthoredPost, c.viewer.ID); err != nil {
+		t.Errorf("viewer leaving: %v", err)
+	}
+	if err := c.coAuthors.Remove(c.owner.ID, coAuthoredPost, c.viewer.ID); !errors.Is(err, ErrCoAuthorNotFound) {
+		t.Errorf("removing again: err = %v, want ErrCoAuthorNotFound", err)
+	}
+	if err := c.coAuthors.Remove(c.owner.ID, coAuthoredPost, c.editor.ID); err != nil {
+		t.Errorf("owner removing the editor: %v", err)
+	}
+	if err := c.blogs.Update(&models.Blog{ID: coAuthoredPost, UserId: c.editor.ID, Title: "t", Content: "c"}); err == nil {
+		t.Error("a removed editor could still update the post")
+	}
+}
+
+func TestCoAuthorListHidesPendingInvites(t *testing.T) {
+	c := newCoAuthorTest(t)
+	tests := []struct {
+		name   string
+		viewer int64
+		want   int
+	}{
+		{"owner", c.owner.ID, 3},
+		{"co-author", c.viewer.ID, 3},
+		{"stranger", c.stranger.ID, 2},
+		{"anonymous", 0, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coAuthors, err := c.coAuthors.List(coAuthoredPost, tt.viewer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(coAuthors) != tt.want {
+				t.Errorf("got %d co-authors, want %d", len(coAuthors), tt.want)
+			}
+		})
+	}
+}
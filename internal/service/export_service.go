@@ -0,0 +1,542 @@
+package service
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+	ExportStatusExpired = "expired"
+
+	// exportTTL is how long a finished export can be downloaded.
+	exportTTL = 48 * time.Hour
+	// exportBuildTimeout marks exports that never finished as failed.
+	exportBuildTimeout = time.Hour
+	// exportPageSize is how many bookmarked or reacted posts are read at a time.
+	exportPageSize = 100
+)
+
+var (
+	ErrExportNotFound   = errors.New("export not found")
+	ErrExportInProgress = errors.New("an export is already being prepared")
+)
+
+var slugInvalidChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// ExportRepository defines the interface for data export operations
+type ExportRepository interface {
+	CreateExport(export *models.DataExport) error
+	GetLatestExport(userID int64) (*models.DataExport, error)
+	GetExportByTokenHash(tokenHash string) (*models.DataExport, error)
+	MarkExportReady(exportID int64, filePath string, size int64, tokenHash string, expiresAt time.Time) error
+	MarkExportFailed(exportID int64) error
+	FailStaleExports(maxAge time.Duration) error
+	GetExpiredExports() ([]models.DataExport, error)
+	MarkExportExpired(exportID int64) error
+}
+
+// ExportService builds downloadable archives of a user's data.
+type ExportService interface {
+	// Request starts building a new export in the background.
+	Request(userID int64) (*models.DataExport, error)
+	Latest(userID int64) (*models.DataExport, error)
+	// Open returns the archive of the user's latest ready export.
+	Open(userID int64) (*os.File, *models.DataExport, error)
+	// OpenByToken returns the archive for an emailed download token.
+	OpenByToken(token string) (*os.File, *models.DataExport, error)
+	// PurgeExpired deletes archives whose download link has expired.
+	PurgeExpired() error
+}
+
+type ExportOptions struct {
+	// Dir is where archives are written.
+	Dir     string
+	BaseURL string
+}
+
+type exportService struct {
+	repo            ExportRepository
+	userService     UserService
+	blogRepo        BlogRepository
+	seriesRepo      SeriesRepository
+	reviewRepo      ReviewRepository
+	readingListRepo ReadingListRepository
+	mailer          mail.Mailer
+	opts            ExportOptions
+}
+
+func NewExportService(r ExportRepository, userService UserService, blogRepo BlogRepository, seriesRepo SeriesRepository, reviewRepo ReviewRepository, readingListRepo ReadingListRepository, mailer mail.Mailer, opts ExportOptions) ExportService {
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		log.Printf("⚠️  Failed to create export directory %s: %v", opts.Dir, err)
+	}
+	return &exportService{
+		repo:            r,
+		userService:     userService,
+		blogRepo:        blogRepo,
+		seriesRepo:      seriesRepo,
+		reviewRepo:      reviewRepo,
+		readingListRepo: readingListRepo,
+		mailer:          mailer,
+		opts:            opts,
+	}
+}
+
+// exportedPost refers to someone's post in the archive.
+type exportedPost struct {
+	BlogID int64  `json:"blog_id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+type exportedReaction struct {
+	Reaction string `json:"reaction"`
+	exportedPost
+}
+
+type exportedReadingList struct {
+	models.ReadingList
+	Posts []exportedPost `json:"posts"`
+}
+
+type exportedReviewComment struct {
+	BlogID int64 `json:"blog_id"`
+	models.ReviewComment
+}
+
+func toExportedPosts(blogs []models.Blog) []exportedPost {
+	posts := make([]exportedPost, len(blogs))
+	for i, b := range blogs {
+		posts[i] = exportedPost{BlogID: b.ID, Title: b.Title, Author: b.Author.Username}
+	}
+	return posts
+}
+
+// allPages reads every page of a paginated blog listing.
+func allPages(list func(limit, offset int64) ([]models.Blog, error)) ([]models.Blog, error) {
+	var all []models.Blog
+	for offset := int64(0); ; offset += exportPageSize {
+		page, err := list(exportPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if len(page) < exportPageSize {
+			return all, nil
+		}
+	}
+}
+
+func (s *exportService) Request(userID int64) (*models.DataExport, error) {
+	latest, err := s.repo.GetLatestExport(userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error checking exports: %w", err)
+	}
+	if latest != nil && latest.Status == ExportStatusPending && time.Since(latest.CreatedAt) < exportBuildTimeout {
+		return nil, ErrExportInProgress
+	}
+
+	export := &models.DataExport{UserID: userID, Status: ExportStatusPending}
+	if err := s.repo.CreateExport(export); err != nil {
+		return nil, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	go s.build(export)
+	return export, nil
+}
+
+// build writes the archive, marks the export ready and emails the link.
+func (s *exportService) build(export *models.DataExport) {
+	path := filepath.Join(s.opts.Dir, fmt.Sprintf("export-%d-%d.zip", export.UserID, export.ID))
+
+	fail := func(err error) {
+		log.Printf("❌ Failed to build export %d for user %d: %v", export.ID, export.UserID, err)
+		os.Remove(path)
+		if err := s.repo.MarkExportFailed(export.ID); err != nil {
+			log.Printf("❌ %v", err)
+		}
+	}
+
+	profile, size, err := s.writeArchive(export.UserID, path)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	raw, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		fail(err)
+		return
+	}
+	if err := s.repo.MarkExportReady(export.ID, path, size, auth.HashToken(raw), time.Now().Add(exportTTL)); err != nil {
+		fail(err)
+		return
+	}
+	log.Printf("✅ Export %d ready for user %d (%d bytes)", export.ID, export.UserID, size)
+
+	if profile.Email == nil {
+		return
+	}
+	msg := mail.Message{
+		To:      *profile.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe export of your account data you requested is ready. Download it within the next 48 hours:\n\n"+
+				"%s/exports/download?token=%s\n\n"+
+				"If you did not request this export, please change your password.\n",
+			profile.Username, s.opts.BaseURL, raw,
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("⚠️  Failed to send export email to user %d: %v", export.UserID, err)
+		return
+	}
+	log.Printf("📧 Export link sent to user %d", export.UserID)
+}
+
+// writeArchive creates the ZIP at path: profile.json, one Markdown file with
+// front matter per post (drafts and trashed posts included), and JSON files
+// with the user's reactions, bookmarks, reading lists and review comments.
+// Saved posts are listed only while the user may still read them.
+func (s *exportService) writeArchive(userID int64, path string) (*UserProfile, int64, error) {
+	profile, err := s.userService.GetMyProfile(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	posts, err := s.exportPosts(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	reactions, err := s.exportReactions(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	bookmarks, err := allPages(func(limit, offset int64) ([]models.Blog, error) {
+		return s.blogRepo.GetBookmarkedBlogs(userID, limit, offset)
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving bookmarks: %w", err)
+	}
+	lists, err := s.exportReadingLists(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	comments, err := s.reviewRepo.GetCommentsByUser(userID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error retrieving review comments: %w", err)
+	}
+	exportedComments := make([]exportedReviewComment, len(comments))
+	for i, c := range comments {
+		exportedComments[i] = exportedReviewComment{BlogID: c.BlogID, ReviewComment: c}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"profile.json", profile},
+		{"reactions.json", reactions},
+		{"bookmarks.json", toExportedPosts(bookmarks)},
+		{"reading_lists.json", lists},
+		{"review_comments.json", exportedComments},
+	}
+	for _, file := range files {
+		if err := writeJSONEntry(zw, file.name, file.v); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	for _, post := range posts {
+		w, err := zw.Create(fmt.Sprintf("posts/%d-%s.md", post.blog.ID, slugify(post.blog.Title)))
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := writePostMarkdown(w, &post.blog, post.series); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	return profile, info.Size(), nil
+}
+
+func writeJSONEntry(zw *zip.Writer, name string, v any) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// postExport is one of the user's posts and the title of its series, if any.
+type postExport struct {
+	blog   models.Blog
+	series string
+}
+
+// exportPosts returns all the user's own posts, trashed ones included, with
+// their content and tags.
+func (s *exportService) exportPosts(userID int64) ([]postExport, error) {
+	blogs, err := s.blogRepo.GetBlogByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving posts: %w", err)
+	}
+	trashed, err := s.blogRepo.GetTrashedBlogs(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving trashed posts: %w", err)
+	}
+
+	// Trashed posts are listed without their content
+	ids := make([]int64, len(trashed))
+	for i := range trashed {
+		ids[i] = trashed[i].ID
+	}
+	contents, err := s.blogRepo.GetBlogContents(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving trashed posts: %w", err)
+	}
+	byBlog := make(map[int64]string, len(contents))
+	for _, c := range contents {
+		byBlog[c.BlogID] = c.Content
+	}
+	for i := range trashed {
+		trashed[i].Content = byBlog[trashed[i].ID]
+	}
+	blogs = append(blogs, trashed...)
+
+	ids = make([]int64, len(blogs))
+	for i := range blogs {
+		ids[i] = blogs[i].ID
+	}
+	tags, err := s.blogRepo.GetBlogTags(ids)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving tags: %w", err)
+	}
+	tagsByBlog := make(map[int64][]string, len(blogs))
+	for _, t := range tags {
+		tagsByBlog[t.BlogID] = append(tagsByBlog[t.BlogID], t.Tag)
+	}
+
+	series, err := s.seriesRepo.GetSeriesByUser(userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving series: %w", err)
+	}
+	seriesTitles := make(map[int64]string, len(series))
+	for _, sr := range series {
+		seriesTitles[sr.ID] = sr.Title
+	}
+
+	posts := make([]postExport, len(blogs))
+	for i, blog := range blogs {
+		blog.Tags = tagsByBlog[blog.ID]
+		posts[i] = postExport{blog: blog}
+		if len(series) == 0 {
+			continue
+		}
+		seriesID, err := s.seriesRepo.GetSeriesIDForBlog(blog.ID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, fmt.Errorf("error retrieving series of post %d: %w", blog.ID, err)
+		}
+		posts[i].series = seriesTitles[seriesID]
+	}
+	return posts, nil
+}
+
+// exportReactions lists the user's reactions, by reaction type.
+func (s *exportService) exportReactions(userID int64) ([]exportedReaction, error) {
+	reactions := make([]string, 0, len(ReactionTypes))
+	for reaction := range ReactionTypes {
+		reactions = append(reactions, reaction)
+	}
+	sort.Strings(reactions)
+
+	exported := []exportedReaction{}
+	for _, reaction := range reactions {
+		blogs, err := allPages(func(limit, offset int64) ([]models.Blog, error) {
+			return s.blogRepo.GetBlogsReactedByUser(userID, reaction, limit, offset)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving reactions: %w", err)
+		}
+		for _, post := range toExportedPosts(blogs) {
+			exported = append(exported, exportedReaction{Reaction: reaction, exportedPost: post})
+		}
+	}
+	return exported, nil
+}
+
+// exportReadingLists returns the user's reading lists, public and private,
+// with their posts in reading order.
+func (s *exportService) exportReadingLists(userID int64) ([]exportedReadingList, error) {
+	lists, err := s.readingListRepo.GetListsByUser(userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving reading lists: %w", err)
+	}
+	exported := make([]exportedReadingList, len(lists))
+	for i, list := range lists {
+		blogs, err := s.blogRepo.GetReadingListBlogs(list.ID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving reading list %d: %w", list.ID, err)
+		}
+		exported[i] = exportedReadingList{ReadingList: list, Posts: toExportedPosts(blogs)}
+	}
+	return exported, nil
+}
+
+// writePostMarkdown writes a post as Markdown with YAML front matter.
+func writePostMarkdown(w io.Writer, blog *models.Blog, series string) error {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "id: %d\n", blog.ID)
+	fmt.Fprintf(&b, "title: %s\n", strconv.Quote(blog.Title))
+	fmt.Fprintf(&b, "status: %s\n", blog.Status)
+	fmt.Fprintf(&b, "visibility: %s\n", blog.Visibility)
+	if len(blog.Tags) > 0 {
+		quoted := make([]string, len(blog.Tags))
+		for i, tag := range blog.Tags {
+			quoted[i] = strconv.Quote(tag)
+		}
+		fmt.Fprintf(&b, "tags: [%s]\n", strings.Join(quoted, ", "))
+	}
+	if series != "" {
+		fmt.Fprintf(&b, "series: %s\n", strconv.Quote(series))
+	}
+	if blog.Excerpt != "" {
+		fmt.Fprintf(&b, "excerpt: %s\n", strconv.Quote(blog.Excerpt))
+	}
+	fmt.Fprintf(&b, "created_at: %s\n", blog.CreatedAt.Format(time.RFC3339))
+	if blog.UpdatedAt != nil {
+		fmt.Fprintf(&b, "updated_at: %s\n", blog.UpdatedAt.Format(time.RFC3339))
+	}
+	if blog.PublishedAt != nil {
+		fmt.Fprintf(&b, "published_at: %s\n", blog.PublishedAt.Format(time.RFC3339))
+	}
+	if blog.DeletedAt != nil {
+		fmt.Fprintf(&b, "deleted_at: %s\n", blog.DeletedAt.Format(time.RFC3339))
+	}
+	fmt.Fprintf(&b, "view_count: %d\n", blog.ViewCount)
+	b.WriteString("---\n\n")
+	b.WriteString(blog.Content)
+	if !strings.HasSuffix(blog.Content, "\n") {
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// slugify turns a title into a short, file-name-safe string.
+func slugify(title string) string {
+	slug := strings.Trim(slugInvalidChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(slug) > 50 {
+		slug = strings.TrimRight(slug[:50], "-")
+	}
+	if slug == "" {
+		slug = "post"
+	}
+	return slug
+}
+
+func (s *exportService) Latest(userID int64) (*models.DataExport, error) {
+	export, err := s.repo.GetLatestExport(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("error retrieving export: %w", err)
+	}
+	return export, nil
+}
+
+func (s *exportService) Open(userID int64) (*os.File, *models.DataExport, error) {
+	export, err := s.Latest(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if export.Status != ExportStatusReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, nil, ErrExportNotFound
+	}
+	return s.open(export)
+}
+
+func (s *exportService) OpenByToken(token string) (*os.File, *models.DataExport, error) {
+	if token == "" {
+		return nil, nil, ErrExportNotFound
+	}
+	export, err := s.repo.GetExportByTokenHash(auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, ErrExportNotFound
+		}
+		return nil, nil, fmt.Errorf("error retrieving export: %w", err)
+	}
+	return s.open(export)
+}
+
+func (s *exportService) open(export *models.DataExport) (*os.File, *models.DataExport, error) {
+	f, err := os.Open(export.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil, ErrExportNotFound
+		}
+		return nil, nil, err
+	}
+	return f, export, nil
+}
+
+func (s *exportService) PurgeExpired() error {
+	if err := s.repo.FailStaleExports(exportBuildTimeout); err != nil {
+		return err
+	}
+
+	exports, err := s.repo.GetExpiredExports()
+	if err != nil {
+		return fmt.Errorf("error retrieving expired exports: %w", err)
+	}
+	for _, export := range exports {
+		if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("⚠️  Failed to remove export file %s: %v", export.FilePath, err)
+			continue
+		}
+		if err := s.repo.MarkExportExpired(export.ID); err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}
+	return nil
+}
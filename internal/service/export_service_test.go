@@ -0,0 +1,246 @@
+package service
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+type fakeProfileService struct {
+	UserService
+	profile UserProfile
+}
+
+func (s *fakeProfileService) GetMyProfile(int64) (*UserProfile, error) {
+	profile := s.profile
+	return &profile, nil
+}
+
+// exportBlogRepo adds the user's saved posts to fakeBlogRepo.
+type exportBlogRepo struct {
+	*fakeBlogRepo
+	reacted    map[string][]models.Blog
+	bookmarked []models.Blog
+	listBlogs  map[int64][]models.Blog
+}
+
+func page(blogs []models.Blog, limit, offset int64) []models.Blog {
+	if offset >= int64(len(blogs)) {
+		return nil
+	}
+	return blogs[offset:min(offset+limit, int64(len(blogs)))]
+}
+
+func (r *exportBlogRepo) GetBlogsReactedByUser(userID int64, reaction string, limit, offset int64) ([]models.Blog, error) {
+	return page(r.reacted[reaction], limit, offset), nil
+}
+
+func (r *exportBlogRepo) GetBookmarkedBlogs(userID, limit, offset int64) ([]models.Blog, error) {
+	return page(r.bookmarked, limit, offset), nil
+}
+
+func (r *exportBlogRepo) GetReadingListBlogs(listID, viewerID int64) ([]models.Blog, error) {
+	return r.listBlogs[listID], nil
+}
+
+type exportSeriesRepo struct {
+	SeriesRepository
+	series     []models.Series
+	blogSeries map[int64]int64
+}
+
+func (r *exportSeriesRepo) GetSeriesByUser(int64, bool) ([]models.Series, error) {
+	return r.series, nil
+}
+
+func (r *exportSeriesRepo) GetSeriesIDForBlog(blogID int64) (int64, error) {
+	id, ok := r.blogSeries[blogID]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return id, nil
+}
+
+type exportReviewRepo struct {
+	ReviewRepository
+	comments []models.ReviewComment
+}
+
+func (r *exportReviewRepo) GetCommentsByUser(int64) ([]models.ReviewComment, error) {
+	return r.comments, nil
+}
+
+type exportReadingListRepo struct {
+	ReadingListRepository
+	lists []models.ReadingList
+}
+
+func (r *exportReadingListRepo) GetListsByUser(int64, bool) ([]models.ReadingList, error) {
+	return r.lists, nil
+}
+
+// readArchive returns the contents of every file in the ZIP at path.
+func readArchive(t *testing.T, path string) map[string]string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open archive: %v", err)
+	}
+	defer zr.Close()
+
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(data)
+	}
+	return files
+}
+
+func TestExportArchive(t *testing.T) {
+	const alice, bob = 1, 2
+	published := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	trashedAt := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	blogs := newFakeBlogRepo(
+		models.Blog{ID: 1, UserId: alice, Title: "Hello, World", Content: "First post", Excerpt: "First post",
+			Tags: []string{"go", "intro"}, PublishedAt: &published},
+		models.Blog{ID: 2, UserId: alice, Title: "Work in progress", Content: "Draft body", Status: BlogStatusDraft,
+			Visibility: BlogVisibilityPrivate},
+		models.Blog{ID: 3, UserId: alice, Title: "Regrets", Content: "Trashed body", DeletedAt: &trashedAt},
+		models.Blog{ID: 4, UserId: bob, Title: "Bob's post", Content: "Not alice's"},
+	)
+	bobsPost := models.Blog{ID: 4, Title: "Bob's post", Author: models.Author{Username: "bob"}}
+	repo := &exportBlogRepo{
+		fakeBlogRepo: blogs,
+		reacted:      map[string][]models.Blog{ReactionLike: {bobsPost}, "fire": {bobsPost}},
+		bookmarked:   []models.Blog{bobsPost},
+		listBlogs:    map[int64][]models.Blog{9: {bobsPost}},
+	}
+	series := &exportSeriesRepo{
+		series:     []models.Series{{ID: 5, UserID: alice, Title: "Getting started"}},
+		blogSeries: map[int64]int64{1: 5},
+	}
+	reviews := &exportReviewRepo{comments: []models.ReviewComment{{ID: 8, BlogID: 4, Body: "Typo in the title"}}}
+	lists := &exportReadingListRepo{lists: []models.ReadingList{{ID: 9, UserID: alice, Name: "Later"}}}
+	users := &fakeProfileService{profile: UserProfile{ID: alice, Username: "alice"}}
+
+	dir := t.TempDir()
+	s := NewExportService(nil, users, repo, series, reviews, lists, nil, ExportOptions{Dir: dir}).(*exportService)
+	path := filepath.Join(dir, "export.zip")
+	if _, _, err := s.writeArchive(alice, path); err != nil {
+		t.Fatalf("writeArchive: %v", err)
+	}
+	files := readArchive(t, path)
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	want := []string{
+		"bookmarks.json",
+		"posts/1-hello-world.md",
+		"posts/2-work-in-progress.md",
+		"posts/3-regrets.md",
+		"profile.json",
+		"reactions.json",
+		"reading_lists.json",
+		"review_comments.json",
+	}
+	if strings.Join(names, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("archive entries:\n%s\nwant:\n%s", strings.Join(names, "\n"), strings.Join(want, "\n"))
+	}
+
+	postTests := []struct {
+		file string
+		want []string
+	}{
+		{"posts/1-hello-world.md", []string{
+			"status: published\n", "visibility: public\n", `tags: ["go", "intro"]` + "\n",
+			`series: "Getting started"` + "\n", `excerpt: "First post"` + "\n",
+			"published_at: 2026-03-01T09:00:00Z\n", "---\n\nFirst post\n",
+		}},
+		{"posts/2-work-in-progress.md", []string{"status: draft\n", "visibility: private\n", "Draft body\n"}},
+		{"posts/3-regrets.md", []string{"deleted_at: 2026-04-01T09:00:00Z\n", "Trashed body\n"}},
+	}
+	for _, tt := range postTests {
+		for _, want := range tt.want {
+			if !strings.Contains(files[tt.file], want) {
+				t.Errorf("%s lacks %q:\n%s", tt.file, want, files[tt.file])
+			}
+		}
+	}
+	if strings.Contains(files["posts/2-work-in-progress.md"], "series:") {
+		t.Error("a post outside any series has a series in its front matter")
+	}
+
+	var reactions []exportedReaction
+	if err := json.Unmarshal([]byte(files["reactions.json"]), &reactions); err != nil {
+		t.Fatalf("reactions.json: %v", err)
+	}
+	if len(reactions) != 2 || reactions[0].Reaction != "fire" || reactions[1].Reaction != ReactionLike || reactions[0].BlogID != 4 {
+		t.Errorf("reactions.json = %+v", reactions)
+	}
+
+	var bookmarks []exportedPost
+	if err := json.Unmarshal([]byte(files["bookmarks.json"]), &bookmarks); err != nil {
+		t.Fatalf("bookmarks.json: %v", err)
+	}
+	if len(bookmarks) != 1 || bookmarks[0] != (exportedPost{BlogID: 4, Title: "Bob's post", Author: "bob"}) {
+		t.Errorf("bookmarks.json = %+v", bookmarks)
+	}
+
+	var readingLists []struct {
+		Name  string         `json:"name"`
+		Posts []exportedPost `json:"posts"`
+	}
+	if err := json.Unmarshal([]byte(files["reading_lists.json"]), &readingLists); err != nil {
+		t.Fatalf("reading_lists.json: %v", err)
+	}
+	if len(readingLists) != 1 || readingLists[0].Name != "Later" || len(readingLists[0].Posts) != 1 {
+		t.Errorf("reading_lists.json = %+v", readingLists)
+	}
+
+	var comments []struct {
+		BlogID int64  `json:"blog_id"`
+		Body   string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(files["review_comments.json"]), &comments); err != nil {
+		t.Fatalf("review_comments.json: %v", err)
+	}
+	if len(comments) != 1 || comments[0].BlogID != 4 || comments[0].Body != "Typo in the title" {
+		t.Errorf("review_comments.json = %+v", comments)
+	}
+}
+
+func TestAllPages(t *testing.T) {
+	blogs := make([]models.Blog, 2*exportPageSize+3)
+	for i := range blogs {
+		blogs[i].ID = int64(i + 1)
+	}
+	got, err := allPages(func(limit, offset int64) ([]models.Blog, error) {
+		return page(blogs, limit, offset), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(blogs) || got[len(got)-1].ID != int64(len(blogs)) {
+		t.Errorf("got %d blogs, want %d", len(got), len(blogs))
+	}
+}
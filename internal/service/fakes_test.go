@@ -0,0 +1,910 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// In-memory repositories for service tests. Each embeds its interface, so
+// a test that reaches a method the fake doesn't implement panics instead of
+// passing by accident.
+
+// fakeInviteRepo applies the same rules to UseInvite and ReleaseInvite as
+// the SQL in repo.InviteRepo.
+type fakeInviteRepo struct {
+	InviteRepository
+	invites map[string]*models.Invite
+}
+
+func newFakeInviteRepo(invites ...*models.Invite) *fakeInviteRepo {
+	r := &fakeInviteRepo{invites: map[string]*models.Invite{}}
+	for _, invite := range invites {
+		r.invites[invite.CodeHash] = invite
+	}
+	return r
+}
+
+func (r *fakeInviteRepo) UseInvite(codeHash string) (*models.Invite, error) {
+	invite, ok := r.invites[codeHash]
+	if !ok || invite.RevokedAt != nil || invite.UseCount >= invite.MaxUses ||
+		(invite.ExpiresAt != nil && !invite.ExpiresAt.After(time.Now())) {
+		return nil, sql.ErrNoRows
+	}
+	invite.UseCount++
+	used := *invite
+	return &used, nil
+}
+
+func (r *fakeInviteRepo) ReleaseInvite(inviteID int64) error {
+	for _, invite := range r.invites {
+		if invite.ID == inviteID && invite.UseCount > 0 {
+			invite.UseCount--
+		}
+	}
+	return nil
+}
+
+// fakeUserRepo stores the users created through it, or fails to create any
+// when createErr is set.
+type fakeUserRepo struct {
+	UserRepository
+	created   []*models.User
+	createErr error
+	history   []usernameRedirect
+}
+
+func (r *fakeUserRepo) CreateUser(user *models.User) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	user.ID = int64(len(r.created) + 1)
+	user.CreatedAt = time.Now()
+	stored := *user
+	r.created = append(r.created, &stored)
+	return nil
+}
+
+// add stores a user directly, for test setup.
+func (r *fakeUserRepo) add(user models.User) *models.User {
+	if err := r.CreateUser(&user); err != nil {
+		panic(err)
+	}
+	return r.created[len(r.created)-1]
+}
+
+func (r *fakeUserRepo) GetByID(id int64) (*models.User, error) {
+	for _, u := range r.created {
+		if u.ID == id {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) GetUserByUsername(username string) (*models.User, error) {
+	for _, u := range r.created {
+		if strings.EqualFold(u.Username, username) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) GetUserByEmail(email string) (*models.User, error) {
+	for _, u := range r.created {
+		if u.Email != nil && strings.EqualFold(*u.Email, email) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// usernameRedirect is a row of username_history.
+type usernameRedirect struct {
+	userID    int64
+	username  string
+	expiresAt time.Time
+}
+
+// GetUserIDByPreviousUsername and ChangeUsername keep the redirects of
+// renamed users the way the SQL does, newest last.
+func (r *fakeUserRepo) GetUserIDByPreviousUsername(username string) (int64, error) {
+	for i := len(r.history) - 1; i >= 0; i-- {
+		h := r.history[i]
+		if strings.EqualFold(h.username, username) && h.expiresAt.After(time.Now()) {
+			return h.userID, nil
+		}
+	}
+	return 0, sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) ChangeUsername(userID int64, oldUsername, newUsername string, redirectUntil time.Time) error {
+	for _, u := range r.created {
+		if u.ID != userID && strings.EqualFold(u.Username, newUsername) {
+			return fmt.Errorf("pq: duplicate key value violates unique constraint")
+		}
+	}
+	for _, u := range r.created {
+		if u.ID != userID {
+			continue
+		}
+		now := time.Now()
+		u.Username, u.UsernameChangedAt = newUsername, &now
+
+		kept := r.history[:0]
+		for _, h := range r.history {
+			ownName := h.userID == userID && strings.EqualFold(h.username, newUsername)
+			expired := strings.EqualFold(h.username, oldUsername) && !h.expiresAt.After(now)
+			if !ownName && !(expired && !strings.EqualFold(oldUsername, newUsername)) {
+				kept = append(kept, h)
+			}
+		}
+		r.history = kept
+		if !strings.EqualFold(oldUsername, newUsername) {
+			r.history = append(r.history, usernameRedirect{userID, oldUsername, redirectUntil})
+		}
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) SetVerifiedEmail(userID int64, email string) error {
+	for _, u := range r.created {
+		if u.ID == userID {
+			now := time.Now()
+			u.Email = &email
+			u.EmailVerifiedAt = &now
+			return nil
+		}
+	}
+	return fmt.Errorf("no user found with ID %d", userID)
+}
+
+func (r *fakeUserRepo) GetBlogCountByUserID(int64) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeUserRepo) UpdateProfile(user *models.User) error {
+	for _, u := range r.created {
+		if u.ID == user.ID {
+			u.DisplayName, u.Bio, u.AvatarURL = user.DisplayName, user.Bio, user.AvatarURL
+			u.Website, u.Location, u.SocialLinks = user.Website, user.Location, user.SocialLinks
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (r *fakeUserRepo) UpdatePassword(userID int64, hashedPassword string) error {
+	for _, u := range r.created {
+		if u.ID == userID {
+			u.Password = hashedPassword
+			return nil
+		}
+	}
+	return fmt.Errorf("no user found with ID %d", userID)
+}
+
+// fakeIdentityRepo keeps linked identities in a slice.
+type fakeIdentityRepo struct {
+	IdentityRepository
+	identities []models.UserIdentity
+}
+
+func (r *fakeIdentityRepo) CreateIdentity(identity *models.UserIdentity) error {
+	identity.ID = int64(len(r.identities) + 1)
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+func (r *fakeIdentityRepo) GetIdentity(provider, subject string) (*models.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			found := identity
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeIdentityRepo) GetIdentitiesByUserID(userID int64) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+// fakeSessionRepo keeps sessions in a slice, applying the same rules as the
+// SQL in repo.SessionRepo, and records which sessions were revoked.
+type fakeSessionRepo struct {
+	SessionRepository
+	sessions []*models.Session
+	// revokedAllBut maps a user to the session kept by RevokeUserSessions
+	revokedAllBut map[int64]int64
+}
+
+func (r *fakeSessionRepo) CreateSession(session *models.Session) error {
+	session.ID = int64(len(r.sessions) + 1)
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+	stored := *session
+	r.sessions = append(r.sessions, &stored)
+	return nil
+}
+
+func (r *fakeSessionRepo) active(sessionID, userID int64) *models.Session {
+	for _, session := range r.sessions {
+		if session.ID == sessionID && session.UserID == userID && session.RevokedAt == nil {
+			return session
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) GetActiveSession(sessionID, userID int64) (*models.Session, error) {
+	session := r.active(sessionID, userID)
+	if session == nil {
+		return nil, sql.ErrNoRows
+	}
+	found := *session
+	return &found, nil
+}
+
+func (r *fakeSessionRepo) GetActiveSessionsByUserID(userID int64, since time.Time) ([]models.Session, error) {
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.CreatedAt.After(since) {
+			sessions = append(sessions, *session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt) })
+	return sessions, nil
+}
+
+func (r *fakeSessionRepo) TouchSession(sessionID int64) error {
+	for _, session := range r.sessions {
+		if session.ID == sessionID {
+			session.LastSeenAt = time.Now()
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeSession(sessionID, userID int64) error {
+	session := r.active(sessionID, userID)
+	if session == nil {
+		return fmt.Errorf("no session found with ID %d for user %d", sessionID, userID)
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeUserSessions(userID, exceptID int64) error {
+	if r.revokedAllBut == nil {
+		r.revokedAllBut = map[int64]int64{}
+	}
+	r.revokedAllBut[userID] = exceptID
+	now := time.Now()
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.ID != exceptID && session.RevokedAt == nil {
+			session.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+// fakeUserTokenRepo keeps user tokens in a slice and applies the same rules
+// as the SQL in repo.UserTokenRepo: only unused, unexpired tokens are found.
+type fakeUserTokenRepo struct {
+	UserTokenRepository
+	tokens []*models.UserToken
+}
+
+func (r *fakeUserTokenRepo) CreateUserToken(token *models.UserToken) error {
+	token.ID = int64(len(r.tokens) + 1)
+	token.CreatedAt = time.Now()
+	stored := *token
+	r.tokens = append(r.tokens, &stored)
+	return nil
+}
+
+func (r *fakeUserTokenRepo) find(tokenHash, purpose string) *models.UserToken {
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash && token.Purpose == purpose &&
+			token.UsedAt == nil && token.ExpiresAt.After(time.Now()) {
+			return token
+		}
+	}
+	return nil
+}
+
+func (r *fakeUserTokenRepo) GetUserToken(tokenHash, purpose string) (*models.UserToken, error) {
+	token := r.find(tokenHash, purpose)
+	if token == nil {
+		return nil, sql.ErrNoRows
+	}
+	found := *token
+	return &found, nil
+}
+
+func (r *fakeUserTokenRepo) ConsumeUserToken(tokenHash, purpose string) (*models.UserToken, error) {
+	token := r.find(tokenHash, purpose)
+	if token == nil {
+		return nil, sql.ErrNoRows
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	found := *token
+	return &found, nil
+}
+
+func (r *fakeUserTokenRepo) DeleteUnusedUserTokens(userID int64, purpose string) error {
+	kept := r.tokens[:0]
+	for _, token := range r.tokens {
+		if token.UserID != userID || token.Purpose != purpose || token.UsedAt != nil {
+			kept = append(kept, token)
+		}
+	}
+	r.tokens = kept
+	return nil
+}
+
+// expireAll moves every token's expiry into the past.
+func (r *fakeUserTokenRepo) expireAll() {
+	for _, token := range r.tokens {
+		token.ExpiresAt = time.Now().Add(-time.Minute)
+	}
+}
+
+// fakeBlogRepo keeps blogs by ID. Trashed blogs (DeletedAt set) are hidden
+// from reads, as liveBlogFilter does.
+type fakeBlogRepo struct {
+	BlogRepository
+	blogs     map[int64]*models.Blog
+	passwords map[int64]string
+}
+
+func newFakeBlogRepo(blogs ...models.Blog) *fakeBlogRepo {
+	r := &fakeBlogRepo{blogs: map[int64]*models.Blog{}, passwords: map[int64]string{}}
+	for _, b := range blogs {
+		r.add(b)
+	}
+	return r
+}
+
+func (r *fakeBlogRepo) add(blog models.Blog) *models.Blog {
+	if blog.ID == 0 {
+		blog.ID = int64(len(r.blogs) + 1)
+	}
+	if blog.Status == "" {
+		blog.Status = BlogStatusPublished
+	}
+	if blog.Visibility == "" {
+		blog.Visibility = BlogVisibilityPublic
+	}
+	r.blogs[blog.ID] = &blog
+	return &blog
+}
+
+func (r *fakeBlogRepo) GetBlogByID(id int64) (*models.Blog, error) {
+	b, ok := r.blogs[id]
+	if !ok || b.DeletedAt != nil {
+		return nil, sql.ErrNoRows
+	}
+	found := *b
+	return &found, nil
+}
+
+func (r *fakeBlogRepo) GetBlogAccessPasswordHash(blogID int64) (string, error) {
+	return r.passwords[blogID], nil
+}
+
+func (r *fakeBlogRepo) CreateBlog(blog *models.Blog, accessPasswordHash *string) error {
+	blog.ID = int64(len(r.blogs) + 1)
+	blog.CreatedAt = time.Now()
+	stored := *blog
+	r.blogs[blog.ID] = &stored
+	if accessPasswordHash != nil {
+		r.passwords[blog.ID] = *accessPasswordHash
+	}
+	return nil
+}
+
+// SetBlogStatus stamps published_at the first time a blog is published.
+func (r *fakeBlogRepo) SetBlogStatus(blogID int64, status string) error {
+	b, ok := r.blogs[blogID]
+	if !ok || b.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	b.Status = status
+	if status == BlogStatusPublished && b.PublishedAt == nil {
+		now := time.Now()
+		b.PublishedAt = &now
+	}
+	return nil
+}
+
+// GetPublicationBlogs lists a publication's live posts with status, by ID.
+func (r *fakeBlogRepo) GetPublicationBlogs(publicationID int64, status string, limit, offset int64) ([]models.Blog, error) {
+	blogs := []models.Blog{}
+	for _, b := range r.blogs {
+		if b.PublicationID != nil && *b.PublicationID == publicationID && b.Status == status && b.DeletedAt == nil {
+			blogs = append(blogs, *b)
+		}
+	}
+	sort.Slice(blogs, func(i, j int) bool { return blogs[i].ID < blogs[j].ID })
+	return page(blogs, limit, offset), nil
+}
+
+// UpdateBlog and DeleteBlog, like the SQL, only touch live blogs of their
+// owner, blog.UserId and userID.
+func (r *fakeBlogRepo) UpdateBlog(blog *models.Blog) error {
+	b, ok := r.blogs[blog.ID]
+	if !ok || b.UserId != blog.UserId || b.DeletedAt != nil {
+		return sql.ErrNoRows
+	}
+	now := time.Now()
+	b.Title, b.Content, b.Summary, b.Excerpt = blog.Title, blog.Content, blog.Summary, blog.Excerpt
+	b.UpdatedAt = &now
+	return nil
+}
+
+func (r *fakeBlogRepo) DeleteBlog(blogID, userID int64) error {
+	b, ok := r.blogs[blogID]
+	if !ok || b.UserId != userID || b.DeletedAt != nil {
+		return fmt.Errorf("no blog found with ID %d for user %d, or user is not the owner", blogID, userID)
+	}
+	now := time.Now()
+	b.DeletedAt = &now
+	return nil
+}
+
+// RestoreBlog and PurgeBlog, like the SQL, only touch trashed blogs of
+// their owner.
+func (r *fakeBlogRepo) RestoreBlog(blogID, userID int64) error {
+	b, ok := r.blogs[blogID]
+	if !ok || b.UserId != userID || b.DeletedAt == nil {
+		return fmt.Errorf("no trashed blog found with ID %d for user %d", blogID, userID)
+	}
+	b.DeletedAt = nil
+	return nil
+}
+
+func (r *fakeBlogRepo) PurgeBlog(blogID, userID int64) error {
+	b, ok := r.blogs[blogID]
+	if !ok || b.UserId != userID || b.DeletedAt == nil {
+		return fmt.Errorf("no trashed blog found with ID %d for user %d", blogID, userID)
+	}
+	delete(r.blogs, blogID)
+	return nil
+}
+
+func (r *fakeBlogRepo) PurgeTrashedBlogs(cutoff time.Time) (int64, error) {
+	var n int64
+	for id, b := range r.blogs {
+		if b.DeletedAt != nil && b.DeletedAt.Before(cutoff) {
+			delete(r.blogs, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeBlogRepo) SetBlogTags(blogID int64, tags []string) error {
+	r.blogs[blogID].Tags = tags
+	return nil
+}
+
+// owned returns the user's blogs, trashed or not as asked, by ID.
+func (r *fakeBlogRepo) owned(userID int64, trashed bool) []models.Blog {
+	blogs := []models.Blog{}
+	for _, b := range r.blogs {
+		if b.UserId == userID && (b.DeletedAt != nil) == trashed {
+			blogs = append(blogs, *b)
+		}
+	}
+	sort.Slice(blogs, func(i, j int) bool { return blogs[i].ID < blogs[j].ID })
+	return blogs
+}
+
+func (r *fakeBlogRepo) GetBlogByUserID(userID int64) ([]models.Blog, error) {
+	return r.owned(userID, false), nil
+}
+
+// GetTrashedBlogs leaves out the content, like the summary columns it lists.
+func (r *fakeBlogRepo) GetTrashedBlogs(userID int64) ([]models.Blog, error) {
+	blogs := r.owned(userID, true)
+	for i := range blogs {
+		blogs[i].Content = ""
+	}
+	return blogs, nil
+}
+
+func (r *fakeBlogRepo) GetBlogContents(blogIDs []int64) ([]models.BlogContent, error) {
+	contents := []models.BlogContent{}
+	for _, id := range blogIDs {
+		if b, ok := r.blogs[id]; ok {
+			contents = append(contents, models.BlogContent{BlogID: id, Content: b.Content})
+		}
+	}
+	return contents, nil
+}
+
+// GetBlogTags returns the Tags the blogs were added with.
+func (r *fakeBlogRepo) GetBlogTags(blogIDs []int64) ([]models.BlogTag, error) {
+	tags := []models.BlogTag{}
+	for _, id := range blogIDs {
+		if b, ok := r.blogs[id]; ok {
+			for _, tag := range b.Tags {
+				tags = append(tags, models.BlogTag{BlogID: id, Tag: tag})
+			}
+		}
+	}
+	return tags, nil
+}
+
+// fakeReactionRepo stores reactions as blog -> user -> reactions.
+type fakeReactionRepo struct {
+	ReactionRepository
+	reactions map[int64]map[int64][]string
+}
+
+func (r *fakeReactionRepo) AddReaction(blogID, userID int64, reaction string) (bool, error) {
+	if r.reactions == nil {
+		r.reactions = map[int64]map[int64][]string{}
+	}
+	if r.reactions[blogID] == nil {
+		r.reactions[blogID] = map[int64][]string{}
+	}
+	for _, existing := range r.reactions[blogID][userID] {
+		if existing == reaction {
+			return false, nil
+		}
+	}
+	r.reactions[blogID][userID] = append(r.reactions[blogID][userID], reaction)
+	return true, nil
+}
+
+func (r *fakeReactionRepo) RemoveReaction(blogID, userID int64, reaction string) (bool, error) {
+	mine := r.reactions[blogID][userID]
+	for i, existing := range mine {
+		if existing == reaction {
+			r.reactions[blogID][userID] = append(mine[:i:i], mine[i+1:]...)
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeReactionRepo) GetReactionCounts(blogIDs []int64) ([]models.ReactionCount, error) {
+	var counts []models.ReactionCount
+	for _, id := range blogIDs {
+		byReaction := map[string]int{}
+		for _, mine := range r.reactions[id] {
+			for _, reaction := range mine {
+				byReaction[reaction]++
+			}
+		}
+		for reaction, n := range byReaction {
+			counts = append(counts, models.ReactionCount{BlogID: id, Reaction: reaction, Count: n})
+		}
+	}
+	return counts, nil
+}
+
+func (r *fakeReactionRepo) GetUserReactions(blogID, userID int64) ([]string, error) {
+	return append([]string{}, r.reactions[blogID][userID]...), nil
+}
+
+// fakeSeriesRepo has no series.
+type fakeSeriesRepo struct {
+	SeriesRepository
+}
+
+func (fakeSeriesRepo) GetSeriesIDForBlog(int64) (int64, error) {
+	return 0, sql.ErrNoRows
+}
+
+// fakeCoAuthorRepo keeps co-authors by blog and user.
+type fakeCoAuthorRepo struct {
+	CoAuthorRepository
+	coAuthors map[int64]map[int64]*models.BlogCoAuthor
+}
+
+func (r *fakeCoAuthorRepo) InviteCoAuthor(blogID, userID int64, role string, invitedBy int64) error {
+	if r.coAuthors == nil {
+		r.coAuthors = map[int64]map[int64]*models.BlogCoAuthor{}
+	}
+	if r.coAuthors[blogID] == nil {
+		r.coAuthors[blogID] = map[int64]*models.BlogCoAuthor{}
+	}
+	if existing, ok := r.coAuthors[blogID][userID]; ok {
+		existing.Role = role
+		return nil
+	}
+	r.coAuthors[blogID][userID] = &models.BlogCoAuthor{BlogID: blogID, UserID: userID, Role: role, InvitedBy: &invitedBy, CreatedAt: time.Now()}
+	return nil
+}
+
+func (r *fakeCoAuthorRepo) GetCoAuthor(blogID, userID int64) (*models.BlogCoAuthor, error) {
+	c, ok := r.coAuthors[blogID][userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *c
+	return &found, nil
+}
+
+func (r *fakeCoAuthorRepo) GetCoAuthorsByBlog(blogID int64) ([]models.BlogCoAuthor, error) {
+	coAuthors := []models.BlogCoAuthor{}
+	for _, c := range r.coAuthors[blogID] {
+		coAuthors = append(coAuthors, *c)
+	}
+	sort.Slice(coAuthors, func(i, j int) bool { return coAuthors[i].UserID < coAuthors[j].UserID })
+	return coAuthors, nil
+}
+
+func (r *fakeCoAuthorRepo) AcceptInvite(blogID, userID int64) error {
+	c, ok := r.coAuthors[blogID][userID]
+	if !ok || c.AcceptedAt != nil {
+		return fmt.Errorf("no co-author invite found for blog %d and user %d", blogID, userID)
+	}
+	now := time.Now()
+	c.AcceptedAt = &now
+	return nil
+}
+
+func (r *fakeCoAuthorRepo) RemoveCoAuthor(blogID, userID int64) error {
+	if _, ok := r.coAuthors[blogID][userID]; !ok {
+		return fmt.Errorf("no co-author found for blog %d and user %d", blogID, userID)
+	}
+	delete(r.coAuthors[blogID], userID)
+	return nil
+}
+
+// fakeFollowRepo keeps follows as follower -> followee.
+type fakeFollowRepo struct {
+	FollowRepository
+	follows map[int64]map[int64]bool
+}
+
+func (r *fakeFollowRepo) Follow(followerID, followeeID int64) error {
+	if r.follows == nil {
+		r.follows = map[int64]map[int64]bool{}
+	}
+	if r.follows[followerID] == nil {
+		r.follows[followerID] = map[int64]bool{}
+	}
+	r.follows[followerID][followeeID] = true
+	return nil
+}
+
+func (r *fakeFollowRepo) IsFollowing(followerID, followeeID int64) (bool, error) {
+	return r.follows[followerID][followeeID], nil
+}
+
+// fakeReviewRepo keeps reviewers by blog, review comments and the review
+// history.
+type fakeReviewRepo struct {
+	ReviewRepository
+	reviewers map[int64]map[int64]bool
+	comments  []*models.ReviewComment
+	events    []models.ReviewEvent
+}
+
+func (r *fakeReviewRepo) RemoveReviewer(blogID, userID int64) error {
+	if !r.reviewers[blogID][userID] {
+		return fmt.Errorf("no reviewer found for blog %d and user %d", blogID, userID)
+	}
+	delete(r.reviewers[blogID], userID)
+	return nil
+}
+
+func (r *fakeReviewRepo) GetReviewers(blogID int64) ([]models.BlogReviewer, error) {
+	reviewers := []models.BlogReviewer{}
+	for userID := range r.reviewers[blogID] {
+		reviewers = append(reviewers, models.BlogReviewer{BlogID: blogID, UserID: userID})
+	}
+	sort.Slice(reviewers, func(i, j int) bool { return reviewers[i].UserID < reviewers[j].UserID })
+	return reviewers, nil
+}
+
+func (r *fakeReviewRepo) AddComment(comment *models.ReviewComment) error {
+	comment.ID = int64(len(r.comments) + 1)
+	comment.CreatedAt = time.Now()
+	stored := *comment
+	r.comments = append(r.comments, &stored)
+	return nil
+}
+
+func (r *fakeReviewRepo) GetComment(blogID, commentID int64) (*models.ReviewComment, error) {
+	for _, c := range r.comments {
+		if c.ID == commentID && c.BlogID == blogID {
+			found := *c
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakeReviewRepo) GetComments(blogID int64) ([]models.ReviewComment, error) {
+	comments := []models.ReviewComment{}
+	for _, c := range r.comments {
+		if c.BlogID == blogID {
+			comments = append(comments, *c)
+		}
+	}
+	return comments, nil
+}
+
+func (r *fakeReviewRepo) SetCommentResolved(blogID, commentID int64, resolved bool) error {
+	for _, c := range r.comments {
+		if c.ID == commentID && c.BlogID == blogID {
+			c.ResolvedAt = nil
+			if resolved {
+				now := time.Now()
+				c.ResolvedAt = &now
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no review comment found with ID %d", commentID)
+}
+
+func (r *fakeReviewRepo) GetEvents(blogID int64) ([]models.ReviewEvent, error) {
+	events := []models.ReviewEvent{}
+	for _, e := range r.events {
+		if e.BlogID == blogID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (r *fakeReviewRepo) AddEvent(blogID, userID int64, action, note string) error {
+	r.events = append(r.events, models.ReviewEvent{
+		ID: int64(len(r.events) + 1), BlogID: blogID, UserID: &userID, Action: action, Note: note, CreatedAt: time.Now(),
+	})
+	return nil
+}
+
+func (r *fakeReviewRepo) AddReviewer(blogID, userID, assignedBy int64) (bool, error) {
+	if r.reviewers == nil {
+		r.reviewers = map[int64]map[int64]bool{}
+	}
+	if r.reviewers[blogID] == nil {
+		r.reviewers[blogID] = map[int64]bool{}
+	}
+	if r.reviewers[blogID][userID] {
+		return false, nil
+	}
+	r.reviewers[blogID][userID] = true
+	return true, nil
+}
+
+func (r *fakeReviewRepo) IsReviewer(blogID, userID int64) (bool, error) {
+	return r.reviewers[blogID][userID], nil
+}
+
+// fakePublicationRepo keeps publications by ID and their members' roles.
+type fakePublicationRepo struct {
+	PublicationRepository
+	publications map[int64]*models.Publication
+	members      map[int64]map[int64]string
+}
+
+func newFakePublicationRepo() *fakePublicationRepo {
+	return &fakePublicationRepo{publications: map[int64]*models.Publication{}, members: map[int64]map[int64]string{}}
+}
+
+func (r *fakePublicationRepo) CreatePublication(pub *models.Publication, ownerID int64) error {
+	pub.ID = int64(len(r.publications) + 1)
+	pub.CreatedBy = &ownerID
+	stored := *pub
+	r.publications[pub.ID] = &stored
+	r.members[pub.ID] = map[int64]string{ownerID: PublicationRoleOwner}
+	return nil
+}
+
+func (r *fakePublicationRepo) GetPublicationBySlug(slug string) (*models.Publication, error) {
+	for _, pub := range r.publications {
+		if pub.Slug == slug {
+			found := *pub
+			found.MemberCount = len(r.members[pub.ID])
+			return &found, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakePublicationRepo) SlugExists(slug string) (bool, error) {
+	_, err := r.GetPublicationBySlug(slug)
+	return err == nil, nil
+}
+
+func (r *fakePublicationRepo) UpdatePublication(pub *models.Publication) error {
+	stored := *pub
+	r.publications[pub.ID] = &stored
+	return nil
+}
+
+func (r *fakePublicationRepo) DeletePublication(id int64) error {
+	delete(r.publications, id)
+	delete(r.members, id)
+	return nil
+}
+
+func (r *fakePublicationRepo) GetMemberRole(publicationID, userID int64) (string, error) {
+	role, ok := r.members[publicationID][userID]
+	if !ok {
+		return "", sql.ErrNoRows
+	}
+	return role, nil
+}
+
+func (r *fakePublicationRepo) GetMembers(publicationID int64) ([]models.PublicationMember, error) {
+	members := []models.PublicationMember{}
+	for userID, role := range r.members[publicationID] {
+		members = append(members, models.PublicationMember{PublicationID: publicationID, UserID: userID, Role: role})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].UserID < members[j].UserID })
+	return members, nil
+}
+
+func (r *fakePublicationRepo) CountOwners(publicationID int64) (int, error) {
+	n := 0
+	for _, role := range r.members[publicationID] {
+		if role == PublicationRoleOwner {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakePublicationRepo) SetMember(publicationID, userID int64, role string) error {
+	r.members[publicationID][userID] = role
+	return nil
+}
+
+func (r *fakePublicationRepo) RemoveMember(publicationID, userID int64) error {
+	if _, ok := r.members[publicationID][userID]; !ok {
+		return fmt.Errorf("no member found with ID %d in publication %d", userID, publicationID)
+	}
+	delete(r.members[publicationID], userID)
+	return nil
+}
+
+// blogTestDeps are the fakes behind a blogService under test.
+type blogTestDeps struct {
+	blogs        *fakeBlogRepo
+	reactions    *fakeReactionRepo
+	coAuthors    *fakeCoAuthorRepo
+	reviews      *fakeReviewRepo
+	follows      *fakeFollowRepo
+	publications *fakePublicationRepo
+}
+
+func newTestBlogService(blogs ...models.Blog) (*blogService, blogTestDeps) {
+	deps := blogTestDeps{
+		blogs:        newFakeBlogRepo(blogs...),
+		reactions:    &fakeReactionRepo{},
+		coAuthors:    &fakeCoAuthorRepo{},
+		reviews:      &fakeReviewRepo{},
+		follows:      &fakeFollowRepo{},
+		publications: newFakePublicationRepo(),
+	}
+	s := NewBlogService(deps.blogs, deps.reactions, fakeSeriesRepo{}, deps.coAuthors, deps.publications, deps.reviews, deps.follows, nil).(*blogService)
+	return s, deps
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+var ErrNotFollowing = errors.New("you are not following this user")
+
+// FollowRepository defines the interface for follow data operations
+type FollowRepository interface {
+	Follow(followerID, followeeID int64) error
+	Unfollow(followerID, followeeID int64) error
+	IsFollowing(followerID, followeeID int64) (bool, error)
+	GetFollowing(userID int64) ([]models.Author, error)
+}
+
+// FollowService lets users follow authors, which opens their
+// followers-only posts to them.
+type FollowService interface {
+	Follow(followerID, followeeID int64) error
+	Unfollow(followerID, followeeID int64) error
+	Following(userID int64) ([]models.Author, error)
+}
+
+type followService struct {
+	repo     FollowRepository
+	userRepo UserRepository
+}
+
+func NewFollowService(r FollowRepository, userRepo UserRepository) FollowService {
+	return &followService{repo: r, userRepo: userRepo}
+}
+
+func (s *followService) Follow(followerID, followeeID int64) error {
+	if followerID == followeeID {
+		return fmt.Errorf("%w: you cannot follow yourself", ErrInvalidInput)
+	}
+	user, err := s.userRepo.GetByID(followeeID)
+	if err != nil || user.Status != UserStatusActive {
+		return ErrUserNotFound
+	}
+	return s.repo.Follow(followerID, followeeID)
+}
+
+func (s *followService) Unfollow(followerID, followeeID int64) error {
+	if err := s.repo.Unfollow(followerID, followeeID); err != nil {
+		if strings.Contains(err.Error(), "no follow found") {
+			return ErrNotFollowing
+		}
+		return fmt.Errorf("unfollowing failed: %w", err)
+	}
+	return nil
+}
+
+func (s *followService) Following(userID int64) ([]models.Author, error) {
+	users, err := s.repo.GetFollowing(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving users followed by user %d: %w", userID, err)
+	}
+	return users, nil
+}
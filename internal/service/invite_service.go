@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// InviteCodePrefix marks invite codes so they are recognisable when shared.
+const InviteCodePrefix = "inv_"
+
+// Limits for invites created by regular users; admins are not limited.
+const (
+	maxActiveInvitesPerUser = 5
+	maxUserInviteUses       = 5
+	maxUserInviteDays       = 30
+	defaultInviteDays       = 7
+)
+
+var ErrInviteNotFound = errors.New("invite not found")
+
+// InviteRepository defines the interface for invite data operations
+type InviteRepository interface {
+	CreateInvite(invite *models.Invite) error
+	GetInvitesByCreator(userID int64) ([]models.Invite, error)
+	CountActiveInvites(userID int64) (int, error)
+	UseInvite(codeHash string) (*models.Invite, error)
+	ReleaseInvite(inviteID int64) error
+	RevokeInvite(inviteID, userID int64) error
+}
+
+// InviteService manages registration invite codes.
+type InviteService interface {
+	Create(userID int64, maxUses, expiresInDays int) (*models.Invite, string, error)
+	List(userID int64) ([]models.Invite, error)
+	Revoke(userID, inviteID int64) error
+}
+
+type inviteService struct {
+	repo     InviteRepository
+	userRepo UserRepository
+}
+
+func NewInviteService(r InviteRepository, userRepo UserRepository) InviteService {
+	return &inviteService{repo: r, userRepo: userRepo}
+}
+
+// Create issues an invite and returns it with the plaintext code, which is
+// only available at creation time. expiresInDays of 0 uses the default;
+// admins may pass -1 for an invite that never expires.
+func (s *inviteService) Create(userID int64, maxUses, expiresInDays int) (*models.Invite, string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	if maxUses == 0 {
+		maxUses = 1
+	}
+	if maxUses < 0 {
+		return nil, "", fmt.Errorf("%w: max_uses must be positive", ErrInvalidInput)
+	}
+	if expiresInDays == 0 {
+		expiresInDays = defaultInviteDays
+	}
+	if expiresInDays < -1 {
+		return nil, "", fmt.Errorf("%w: expires_in_days must be positive", ErrInvalidInput)
+	}
+
+	if !user.IsAdmin {
+		if maxUses > maxUserInviteUses {
+			return nil, "", fmt.Errorf("%w: max_uses cannot exceed %d", ErrInvalidInput, maxUserInviteUses)
+		}
+		if expiresInDays < 0 || expiresInDays > maxUserInviteDays {
+			return nil, "", fmt.Errorf("%w: expires_in_days must be between 1 and %d", ErrInvalidInput, maxUserInviteDays)
+		}
+		active, err := s.repo.CountActiveInvites(userID)
+		if err != nil {
+			return nil, "", fmt.Errorf("error counting invites: %w", err)
+		}
+		if active >= maxActiveInvitesPerUser {
+			return nil, "", fmt.Errorf("%w: you already have %d active invites", ErrInvalidInput, active)
+		}
+	}
+
+	raw, err := auth.GenerateOpaqueToken(InviteCodePrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invite := &models.Invite{
+		CreatedBy:  userID,
+		CodeHash:   auth.HashToken(raw),
+		CodePrefix: raw[:len(InviteCodePrefix)+4],
+		MaxUses:    maxUses,
+		Invitees:   []string{},
+	}
+	if expiresInDays > 0 {
+		expiresAt := time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour)
+		invite.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.CreateInvite(invite); err != nil {
+		return nil, "", fmt.Errorf("failed to create invite: %w", err)
+	}
+	return invite, raw, nil
+}
+
+// List returns the user's invites and who registered with each.
+func (s *inviteService) List(userID int64) ([]models.Invite, error) {
+	invites, err := s.repo.GetInvitesByCreator(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving invites for user %d: %w", userID, err)
+	}
+	return invites, nil
+}
+
+// Revoke stops an invite from being used again.
+func (s *inviteService) Revoke(userID, inviteID int64) error {
+	if err := s.repo.RevokeInvite(inviteID, userID); err != nil {
+		if strings.Contains(err.Error(), "no invite found") {
+			return ErrInviteNotFound
+		}
+		return fmt.Errorf("revocation failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+)
+
+// TestMain hashes passwords cheaply; the service tests don't depend on cost.
+func TestMain(m *testing.M) {
+	auth.SetArgon2Params(auth.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	os.Exit(m.Run())
+}
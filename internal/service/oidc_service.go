@@ -0,0 +1,331 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/oidc"
+)
+
+// OIDCStateTTL is how long a sign-in started with BeginLogin may take.
+const OIDCStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider  = errors.New("unknown identity provider")
+	ErrInvalidState     = errors.New("invalid or expired login state")
+	ErrIdentityNotFound = errors.New("identity not found")
+	ErrIdentityLinked   = errors.New("identity already linked to another account")
+	ErrLastLoginMethod  = errors.New("cannot remove the only way to sign in")
+)
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// OIDCProvider is the relying-party client for one identity provider.
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(state, nonce, codeVerifier string) (string, error)
+	Exchange(code, codeVerifier, nonce string) (*oidc.IDTokenClaims, error)
+}
+
+// IdentityRepository defines the interface for external identity data operations
+type IdentityRepository interface {
+	CreateIdentity(identity *models.UserIdentity) error
+	GetIdentity(provider, subject string) (*models.UserIdentity, error)
+	GetIdentitiesByUserID(userID int64) ([]models.UserIdentity, error)
+	DeleteIdentity(identityID, userID int64) error
+}
+
+// OIDCService signs users in through external OpenID Connect providers.
+type OIDCService interface {
+	Providers() []string
+	// BeginLogin returns the provider URL to redirect to and the state it
+	// carries, which the caller must bind to the browser starting the flow.
+	// When linkUserID is non-zero the resulting identity is linked to that
+	// user instead.
+	BeginLogin(provider string, linkUserID int64) (authURL, state string, err error)
+	CompleteLogin(provider, state, code string) (*models.User, error)
+	ListIdentities(userID int64) ([]models.UserIdentity, error)
+	Unlink(userID, identityID int64) error
+}
+
+// loginState is what we remember between redirecting to the provider and
+// handling its callback.
+type loginState struct {
+	provider     string
+	nonce        string
+	codeVerifier string
+	linkUserID   int64
+	expiresAt    time.Time
+}
+
+type oidcService struct {
+	providers    map[string]OIDCProvider
+	identityRepo IdentityRepository
+	userRepo     UserRepository
+	// registrationMode decides whether sign-ins may create new accounts
+	registrationMode string
+
+	mu     sync.Mutex
+	states map[string]loginState
+}
+
+func NewOIDCService(providers []OIDCProvider, identityRepo IdentityRepository, userRepo UserRepository, registrationMode string) OIDCService {
+	byName := make(map[string]OIDCProvider, len(providers))
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+	return &oidcService{
+		providers:        byName,
+		identityRepo:     identityRepo,
+		userRepo:         userRepo,
+		registrationMode: registrationMode,
+		states:           make(map[string]loginState),
+	}
+}
+
+func (s *oidcService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (s *oidcService) BeginLogin(provider string, linkUserID int64) (string, string, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := p.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to build authorization URL: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, st := range s.states {
+		if now.After(st.expiresAt) {
+			delete(s.states, k)
+		}
+	}
+	s.states[state] = loginState{
+		provider:     provider,
+		nonce:        nonce,
+		codeVerifier: verifier,
+		linkUserID:   linkUserID,
+		expiresAt:    now.Add(OIDCStateTTL),
+	}
+
+	return authURL, state, nil
+}
+
+// takeState removes and returns the login state, so each state is used once.
+func (s *oidcService) takeState(state string) (loginState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[state]
+	delete(s.states, state)
+	if !ok || time.Now().After(st.expiresAt) {
+		return loginState{}, false
+	}
+	return st, true
+}
+
+// CompleteLogin handles the provider callback and returns the local user the
+// identity belongs to: an already linked user, the user who started a link,
+// an existing user with the same verified email, or a newly created account.
+func (s *oidcService) CompleteLogin(provider, state, code string) (*models.User, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	st, ok := s.takeState(state)
+	if !ok || st.provider != provider {
+		return nil, ErrInvalidState
+	}
+
+	claims, err := p.Exchange(code, st.codeVerifier, st.nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete sign-in with %s: %w", provider, err)
+	}
+
+	identity, err := s.identityRepo.GetIdentity(provider, claims.Subject)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("error looking up identity: %w", err)
+	}
+
+	var userID int64
+	switch {
+	case identity != nil:
+		if st.linkUserID != 0 && identity.UserID != st.linkUserID {
+			return nil, ErrIdentityLinked
+		}
+		userID = identity.UserID
+	case st.linkUserID != 0:
+		userID = st.linkUserID
+	default:
+		userID, err = s.findOrCreateUser(claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if identity == nil {
+		var email *string
+		if claims.Email != "" {
+			e := normalizeEmail(claims.Email)
+			email = &e
+		}
+		identity = &models.UserIdentity{
+			UserID:   userID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    email,
+		}
+		if err := s.identityRepo.CreateIdentity(identity); err != nil {
+			return nil, fmt.Errorf("failed to link identity: %w", err)
+		}
+		log.Printf("✅ Linked %s identity to user %d", provider, userID)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user.Status == UserStatusPending {
+		return nil, ErrAccountPending
+	}
+	user.Password = ""
+	return user, nil
+}
+
+func (s *oidcService) findOrCreateUser(claims *oidc.IDTokenClaims) (int64, error) {
+	email := normalizeEmail(claims.Email)
+
+	if claims.EmailVerified && email != "" {
+		existing, err := s.userRepo.GetUserByEmail(email)
+		if err == nil {
+			// Only trust the match if both sides have verified the address
+			if existing.EmailVerifiedAt != nil {
+				return existing.ID, nil
+			}
+			email = ""
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("error checking email: %w", err)
+		}
+	} else {
+		email = ""
+	}
+
+	// Invite-only sites cannot create accounts here, as there is no code
+	status := UserStatusActive
+	switch s.registrationMode {
+	case RegistrationClosed, RegistrationInvite:
+		return 0, ErrRegistrationClosed
+	case RegistrationApproval:
+		status = UserStatusPending
+	}
+
+	// Accounts created through a provider have no usable password
+	user := &models.User{
+		Username: s.uniqueUsername(claims),
+		Password: "",
+		Status:   status,
+	}
+	if err := s.userRepo.CreateUser(user); err != nil {
+		return 0, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if email != "" {
+		if err := s.userRepo.SetVerifiedEmail(user.ID, email); err != nil {
+			log.Printf("⚠️  Failed to store email for new user %d: %v", user.ID, err)
+		}
+	}
+	return user.ID, nil
+}
+
+// uniqueUsername derives an unused username from the provider's claims.
+func (s *oidcService) uniqueUsername(claims *oidc.IDTokenClaims) string {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	base = usernameInvalidChars.ReplaceAllString(strings.ToLower(base), "_")
+	base = strings.Trim(base, "_")
+	if base == "" {
+		base = "user"
+	}
+	if len(base) > 14 {
+		base = base[:14]
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		if validateUsername(candidate) == nil {
+			if ok, err := usernameAvailable(s.userRepo, candidate, 0); err == nil && ok {
+				return candidate
+			}
+		}
+		candidate = fmt.Sprintf("%s_%d", base, rand.IntN(100000))
+	}
+	return candidate
+}
+
+func (s *oidcService) ListIdentities(userID int64) ([]models.UserIdentity, error) {
+	identities, err := s.identityRepo.GetIdentitiesByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving identities for user %d: %w", userID, err)
+	}
+	return identities, nil
+}
+
+// Unlink removes an identity, refusing to remove the last one from an account
+// that has no password.
+func (s *oidcService) Unlink(userID, identityID int64) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	if user.Password == "" {
+		identities, err := s.identityRepo.GetIdentitiesByUserID(userID)
+		if err != nil {
+			return fmt.Errorf("error retrieving identities: %w", err)
+		}
+		if len(identities) <= 1 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	if err := s.identityRepo.DeleteIdentity(identityID, userID); err != nil {
+		if strings.Contains(err.Error(), "no identity found") {
+			return ErrIdentityNotFound
+		}
+		return fmt.Errorf("unlink failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+	"github.com/Brownie44l1/blog/internal/oidc"
+	"github.com/Brownie44l1/blog/internal/oidc/oidctest"
+)
+
+const testRedirectURL = "http://blog.test/auth/oidc/mock/callback"
+
+// newMockOIDC starts the mock provider and returns a service that signs in
+// through it.
+func newMockOIDC(t *testing.T, users *fakeUserRepo, identities *fakeIdentityRepo) OIDCService {
+	t.Helper()
+	srv := httptest.NewUnstartedServer(nil)
+	issuer := "http://" + srv.Listener.Addr().String()
+	mock, err := oidctest.NewProvider(issuer, "blog", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Config.Handler = mock
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	provider := oidc.NewProvider(oidc.Config{
+		Name:         "mock",
+		Issuer:       issuer,
+		ClientID:     "blog",
+		ClientSecret: "secret",
+		RedirectURL:  testRedirectURL,
+	})
+	return NewOIDCService([]OIDCProvider{provider}, identities, users, RegistrationOpen)
+}
+
+// authorize follows authURL as a user signing in at the provider as sub,
+// after applying change to the request, and returns the state and code the
+// provider sends back.
+func authorize(t *testing.T, authURL, sub string, change func(q url.Values)) (state, code string) {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("sub", sub)
+	q.Set("preferred_username", sub)
+	q.Set("email", sub+"@example.com")
+	if change != nil {
+		change(q)
+	}
+	u.RawQuery = q.Encode()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned status %d", resp.StatusCode)
+	}
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return callback.Query().Get("state"), callback.Query().Get("code")
+}
+
+func TestOIDCLoginCreatesAccount(t *testing.T) {
+	users, identities := &fakeUserRepo{}, &fakeIdentityRepo{}
+	s := newMockOIDC(t, users, identities)
+
+	authURL, state, err := s.BeginLogin("mock", 0)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	gotState, code := authorize(t, authURL, "alice", nil)
+	if gotState != state {
+		t.Fatalf("provider returned state %q, want %q", gotState, state)
+	}
+
+	user, err := s.CompleteLogin("mock", state, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.Username != "alice" {
+		t.Errorf("Username = %q, want alice", user.Username)
+	}
+	if len(identities.identities) != 1 || identities.identities[0].UserID != user.ID {
+		t.Fatalf("identities = %+v, want one linked to user %d", identities.identities, user.ID)
+	}
+
+	// Signing in again finds the same account
+	authURL, state, _ = s.BeginLogin("mock", 0)
+	_, code = authorize(t, authURL, "alice", nil)
+	again, err := s.CompleteLogin("mock", state, code)
+	if err != nil {
+		t.Fatalf("second CompleteLogin: %v", err)
+	}
+	if again.ID != user.ID || len(users.created) != 1 {
+		t.Errorf("second sign-in gave user %d of %d, want the existing user %d", again.ID, len(users.created), user.ID)
+	}
+}
+
+func TestOIDCLink(t *testing.T) {
+	users, identities := &fakeUserRepo{}, &fakeIdentityRepo{}
+	s := newMockOIDC(t, users, identities)
+	bob := users.add(models.User{Username: "bob", Status: UserStatusActive})
+	carol := users.add(models.User{Username: "carol", Status: UserStatusActive})
+
+	authURL, state, err := s.BeginLogin("mock", bob.ID)
+	if err != nil {
+		t.Fatalf("BeginLogin: %v", err)
+	}
+	_, code := authorize(t, authURL, "bob-at-idp", nil)
+	user, err := s.CompleteLogin("mock", state, code)
+	if err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if user.ID != bob.ID {
+		t.Errorf("linked to user %d, want %d", user.ID, bob.ID)
+	}
+	if len(users.created) != 2 {
+		t.Error("linking created an account")
+	}
+
+	// The identity can't be linked to a second account
+	authURL, state, _ = s.BeginLogin("mock", carol.ID)
+	_, code = authorize(t, authURL, "bob-at-idp", nil)
+	if _, err := s.CompleteLogin("mock", state, code); !errors.Is(err, ErrIdentityLinked) {
+		t.Errorf("err = %v, want ErrIdentityLinked", err)
+	}
+	if len(identities.identities) != 1 {
+		t.Errorf("%d identities, want 1", len(identities.identities))
+	}
+}
+
+func TestOIDCReplayedState(t *testing.T) {
+	users, identities := &fakeUserRepo{}, &fakeIdentityRepo{}
+	s := newMockOIDC(t, users, identities)
+
+	authURL, state, _ := s.BeginLogin("mock", 0)
+	_, code := authorize(t, authURL, "alice", nil)
+	if _, err := s.CompleteLogin("mock", state, code); err != nil {
+		t.Fatalf("CompleteLogin: %v", err)
+	}
+	if _, err := s.CompleteLogin("mock", state, code); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("replayed state: err = %v, want ErrInvalidState", err)
+	}
+	if _, err := s.CompleteLogin("mock", "made-up", code); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("unknown state: err = %v, want ErrInvalidState", err)
+	}
+}
+
+func TestOIDCBadNonce(t *testing.T) {
+	users, identities := &fakeUserRepo{}, &fakeIdentityRepo{}
+	s := newMockOIDC(t, users, identities)
+
+	authURL, state, _ := s.BeginLogin("mock", 0)
+	_, code := authorize(t, authURL, "alice", func(q url.Values) { q.Set("nonce", "forged") })
+	_, err := s.CompleteLogin("mock", state, code)
+	if err == nil {
+		t.Fatal("CompleteLogin accepted an ID token with the wrong nonce")
+	}
+	if len(users.created) != 0 || len(identities.identities) != 0 {
+		t.Error("a rejected sign-in created an account or identity")
+	}
+}
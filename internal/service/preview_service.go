@@ -0,0 +1,158 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// PreviewTokenPrefix marks preview tokens so they are recognisable when shared.
+const PreviewTokenPrefix = "pvw_"
+
+const (
+	defaultPreviewDays           = 7
+	maxPreviewDays               = 30
+	maxActivePreviewLinksPerBlog = 10
+)
+
+var ErrPreviewLinkNotFound = errors.New("preview link not found")
+
+// PreviewRepository defines the interface for preview link data operations
+type PreviewRepository interface {
+	CreatePreviewLink(link *models.PreviewLink) error
+	GetPreviewLinksByBlog(blogID int64) ([]models.PreviewLink, error)
+	CountActivePreviewLinks(blogID int64) (int, error)
+	UsePreviewLink(tokenHash string) (*models.PreviewLink, error)
+	RevokePreviewLink(blogID, linkID int64) error
+}
+
+// PreviewService manages secret links that let anyone holding them read a
+// single post, typically a draft, without an account.
+type PreviewService interface {
+	// Create issues a link and returns it with its URL, which holds the
+	// plaintext token and is only available at creation time.
+	Create(userID, blogID int64, expiresInDays int) (*models.PreviewLink, string, error)
+	// List returns a post's links with their access counts.
+	List(userID, blogID int64) ([]models.PreviewLink, error)
+	Revoke(userID, blogID, linkID int64) error
+	// Open returns the post a token grants access to, counting the access.
+	Open(token string) (*models.Blog, error)
+}
+
+type previewService struct {
+	repo      PreviewRepository
+	blogRepo  BlogRepository
+	coAuthors CoAuthorRepository
+	reactions ReactionRepository
+	baseURL   string
+}
+
+func NewPreviewService(r PreviewRepository, blogRepo BlogRepository, coAuthors CoAuthorRepository, reactions ReactionRepository, baseURL string) PreviewService {
+	return &previewService{repo: r, blogRepo: blogRepo, coAuthors: coAuthors, reactions: reactions, baseURL: baseURL}
+}
+
+// authorize checks that userID may manage the preview links of a blog:
+// its owner and editor co-authors.
+func (s *previewService) authorize(userID, blogID int64) error {
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	canEdit, err := canEditBlog(s.coAuthors, blog, userID)
+	if err != nil {
+		return err
+	}
+	if !canEdit {
+		return ErrNotBlogOwner
+	}
+	return nil
+}
+
+func (s *previewService) Create(userID, blogID int64, expiresInDays int) (*models.PreviewLink, string, error) {
+	if expiresInDays == 0 {
+		expiresInDays = defaultPreviewDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxPreviewDays {
+		return nil, "", fmt.Errorf("%w: expires_in_days must be between 1 and %d", ErrInvalidInput, maxPreviewDays)
+	}
+	if err := s.authorize(userID, blogID); err != nil {
+		return nil, "", err
+	}
+
+	active, err := s.repo.CountActivePreviewLinks(blogID)
+	if err != nil {
+		return nil, "", fmt.Errorf("error counting preview links: %w", err)
+	}
+	if active >= maxActivePreviewLinksPerBlog {
+		return nil, "", fmt.Errorf("%w: this post already has %d active preview links", ErrInvalidInput, active)
+	}
+
+	raw, err := auth.GenerateOpaqueToken(PreviewTokenPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+
+	link := &models.PreviewLink{
+		BlogID:      blogID,
+		CreatedBy:   userID,
+		TokenHash:   auth.HashToken(raw),
+		TokenPrefix: raw[:len(PreviewTokenPrefix)+4],
+		ExpiresAt:   time.Now().Add(time.Duration(expiresInDays) * 24 * time.Hour),
+	}
+	if err := s.repo.CreatePreviewLink(link); err != nil {
+		return nil, "", fmt.Errorf("failed to create preview link: %w", err)
+	}
+	return link, fmt.Sprintf("%s/preview/%s", s.baseURL, raw), nil
+}
+
+func (s *previewService) List(userID, blogID int64) ([]models.PreviewLink, error) {
+	if err := s.authorize(userID, blogID); err != nil {
+		return nil, err
+	}
+	links, err := s.repo.GetPreviewLinksByBlog(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving preview links of blog %d: %w", blogID, err)
+	}
+	return links, nil
+}
+
+func (s *previewService) Revoke(userID, blogID, linkID int64) error {
+	if err := s.authorize(userID, blogID); err != nil {
+		return err
+	}
+	if err := s.repo.RevokePreviewLink(blogID, linkID); err != nil {
+		if strings.Contains(err.Error(), "no preview link found") {
+			return ErrPreviewLinkNotFound
+		}
+		return fmt.Errorf("revoking preview link failed: %w", err)
+	}
+	return nil
+}
+
+func (s *previewService) Open(token string) (*models.Blog, error) {
+	if !strings.HasPrefix(token, PreviewTokenPrefix) {
+		return nil, ErrPreviewLinkNotFound
+	}
+	link, err := s.repo.UsePreviewLink(auth.HashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPreviewLinkNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening preview link: %w", err)
+	}
+
+	blog, err := s.blogRepo.GetBlogByID(link.BlogID)
+	if err != nil {
+		return nil, ErrPreviewLinkNotFound
+	}
+	blogs := []models.Blog{*blog}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	return &blogs[0], nil
+}
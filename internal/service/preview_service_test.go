@@ -0,0 +1,187 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// fakePreviewRepo opens links the way the SQL does: only unrevoked,
+// unexpired links to live posts, counting each open.
+type fakePreviewRepo struct {
+	PreviewRepository
+	blogs *fakeBlogRepo
+	links []*models.PreviewLink
+}
+
+func (r *fakePreviewRepo) CreatePreviewLink(link *models.PreviewLink) error {
+	link.ID = int64(len(r.links) + 1)
+	link.CreatedAt = time.Now()
+	stored := *link
+	r.links = append(r.links, &stored)
+	return nil
+}
+
+func (r *fakePreviewRepo) CountActivePreviewLinks(blogID int64) (int, error) {
+	n := 0
+	for _, link := range r.links {
+		if link.BlogID == blogID && link.RevokedAt == nil && link.ExpiresAt.After(time.Now()) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakePreviewRepo) UsePreviewLink(tokenHash string) (*models.PreviewLink, error) {
+	for _, link := range r.links {
+		if link.TokenHash != tokenHash || link.RevokedAt != nil || !link.ExpiresAt.After(time.Now()) {
+			continue
+		}
+		if b, ok := r.blogs.blogs[link.BlogID]; !ok || b.DeletedAt != nil {
+			continue
+		}
+		now := time.Now()
+		link.AccessCount++
+		link.LastAccessedAt = &now
+		found := *link
+		return &found, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+func (r *fakePreviewRepo) RevokePreviewLink(blogID, linkID int64) error {
+	for _, link := range r.links {
+		if link.ID == linkID && link.BlogID == blogID && link.RevokedAt == nil {
+			now := time.Now()
+			link.RevokedAt = &now
+			return nil
+		}
+	}
+	return fmt.Errorf("no preview link found with ID %d for blog %d", linkID, blogID)
+}
+
+// newPreviewTest issues a link to the draft post of the readability
+// fixture and returns it with its token.
+func newPreviewTest(t *testing.T) (PreviewService, *fakePreviewRepo, blogTestDeps, *models.PreviewLink, string) {
+	t.Helper()
+	_, deps := newReadabilityFixture()
+	previews := &fakePreviewRepo{blogs: deps.blogs}
+	s := NewPreviewService(previews, deps.blogs, deps.coAuthors, deps.reactions, "https://blog.example")
+
+	link, url, err := s.Create(fixtureAuthor, draftPost, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := strings.TrimPrefix(url, "https://blog.example/preview/")
+	if !strings.HasPrefix(token, PreviewTokenPrefix) || !strings.HasPrefix(token, link.TokenPrefix) {
+		t.Fatalf("url %q holds no preview token", url)
+	}
+	return s, previews, deps, link, token
+}
+
+func TestPreviewOpen(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(s PreviewService, previews *fakePreviewRepo, deps blogTestDeps, link *models.PreviewLink)
+		want  error
+	}{
+		{"valid", func(PreviewService, *fakePreviewRepo, blogTestDeps, *models.PreviewLink) {}, nil},
+		{"revoked", func(s PreviewService, _ *fakePreviewRepo, _ blogTestDeps, link *models.PreviewLink) {
+			if err := s.Revoke(fixtureAuthor, draftPost, link.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, ErrPreviewLinkNotFound},
+		{"expired", func(_ PreviewService, previews *fakePreviewRepo, _ blogTestDeps, _ *models.PreviewLink) {
+			previews.links[0].ExpiresAt = time.Now().Add(-time.Second)
+		}, ErrPreviewLinkNotFound},
+		{"post trashed", func(_ PreviewService, _ *fakePreviewRepo, deps blogTestDeps, _ *models.PreviewLink) {
+			now := time.Now()
+			deps.blogs.blogs[draftPost].DeletedAt = &now
+		}, ErrPreviewLinkNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, previews, deps, link, token := newPreviewTest(t)
+			tt.setup(s, previews, deps, link)
+
+			blog, err := s.Open(token)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Open err = %v, want %v", err, tt.want)
+			}
+			wantCount := 1
+			if tt.want != nil {
+				wantCount = 0
+			} else if blog.ID != draftPost {
+				t.Errorf("opened blog %d, want %d", blog.ID, draftPost)
+			}
+			if got := previews.links[0].AccessCount; got != wantCount {
+				t.Errorf("access count = %d, want %d", got, wantCount)
+			}
+		})
+	}
+}
+
+func TestPreviewOpenUnknownToken(t *testing.T) {
+	s, _, _, _, token := newPreviewTest(t)
+	for _, bad := range []string{"", "not-a-token", PreviewTokenPrefix + "unknown", strings.TrimPrefix(token, PreviewTokenPrefix)} {
+		if _, err := s.Open(bad); !errors.Is(err, ErrPreviewLinkNotFound) {
+			t.Errorf("Open(%q) err = %v, want ErrPreviewLinkNotFound", bad, err)
+		}
+	}
+}
+
+func TestPreviewRevoke(t *testing.T) {
+	s, previews, _, link, _ := newPreviewTest(t)
+
+	if err := s.Revoke(fixtureStranger, draftPost, link.ID); !errors.Is(err, ErrNotBlogOwner) {
+		t.Errorf("stranger revoking: err = %v, want ErrNotBlogOwner", err)
+	}
+	if previews.links[0].RevokedAt != nil {
+		t.Fatal("link revoked by a stranger")
+	}
+	if err := s.Revoke(fixtureAuthor, draftPost, link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Revoke(fixtureAuthor, draftPost, link.ID); !errors.Is(err, ErrPreviewLinkNotFound) {
+		t.Errorf("revoking twice: err = %v, want ErrPreviewLinkNotFound", err)
+	}
+	// Revoked and expired links no longer count towards the limit
+	active, err := previews.CountActivePreviewLinks(draftPost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active != 0 {
+		t.Errorf("active links = %d, want 0", active)
+	}
+}
+
+func TestPreviewCreate(t *testing.T) {
+	s, previews, _, _, _ := newPreviewTest(t)
+
+	for _, days := range []int{-1, maxPreviewDays + 1} {
+		if _, _, err := s.Create(fixtureAuthor, draftPost, days); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Create(%d days) err = %v, want ErrInvalidInput", days, err)
+		}
+	}
+	if _, _, err := s.Create(fixtureStranger, draftPost, 1); !errors.Is(err, ErrNotBlogOwner) {
+		t.Errorf("stranger creating: err = %v, want ErrNotBlogOwner", err)
+	}
+
+	for len(previews.links) < maxActivePreviewLinksPerBlog {
+		if _, _, err := s.Create(fixtureAuthor, draftPost, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := s.Create(fixtureAuthor, draftPost, 1); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("over the limit: err = %v, want ErrInvalidInput", err)
+	}
+	previews.links[0].ExpiresAt = time.Now().Add(-time.Second)
+	if _, _, err := s.Create(fixtureAuthor, draftPost, 1); err != nil {
+		t.Errorf("after one link expired: %v", err)
+	}
+}
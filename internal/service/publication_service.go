@@ -0,0 +1,428 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Publication roles. Owners manage the publication and its members,
+// editors publish submitted posts, writers submit posts for review.
+const (
+	PublicationRoleOwner  = "owner"
+	PublicationRoleEditor = "editor"
+	PublicationRoleWriter = "writer"
+)
+
+const (
+	maxPublicationNameRunes        = 100
+	maxPublicationDescriptionRunes = 1000
+	feedItemCount                  = 20
+)
+
+var (
+	ErrPublicationNotFound  = errors.New("publication not found")
+	ErrSlugTaken            = errors.New("publication slug already taken")
+	ErrNotPublicationMember = errors.New("you are not a member of this publication")
+	ErrPublicationRole      = errors.New("your role in this publication does not allow that")
+	ErrLastOwner            = errors.New("a publication must keep at least one owner")
+)
+
+var publicationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,48}[a-z0-9]$`)
+
+// PublicationRepository defines the interface for publication data operations
+type PublicationRepository interface {
+	CreatePublication(pub *models.Publication, ownerID int64) error
+	GetPublicationBySlug(slug string) (*models.Publication, error)
+	GetPublicationByID(id int64) (*models.Publication, error)
+	SlugExists(slug string) (bool, error)
+	GetPublicationsByMember(userID int64) ([]models.Publication, error)
+	UpdatePublication(pub *models.Publication) error
+	DeletePublication(id int64) error
+	GetMemberRole(publicationID, userID int64) (string, error)
+	GetMembers(publicationID int64) ([]models.PublicationMember, error)
+	CountOwners(publicationID int64) (int, error)
+	SetMember(publicationID, userID int64, role string) error
+	RemoveMember(publicationID, userID int64) error
+}
+
+// PublicationDetail is a publication with its members.
+type PublicationDetail struct {
+	*models.Publication
+	Members []models.PublicationMember `json:"members"`
+}
+
+// PublicationUpdate holds the fields to change; nil fields are left as is.
+type PublicationUpdate struct {
+	Name        *string
+	Description *string
+}
+
+// PublicationService manages team blogs, their members and their posts.
+type PublicationService interface {
+	Create(userID int64, name, slug, description string) (*models.Publication, error)
+	Get(slug string) (*PublicationDetail, error)
+	ListMine(userID int64) ([]models.Publication, error)
+	Update(userID int64, slug string, update PublicationUpdate) (*models.Publication, error)
+	Delete(userID int64, slug string) error
+	// SetMember adds a member or changes their role. Owners may grant any
+	// role; editors may only add writers.
+	SetMember(actorID int64, slug, username, role string) (*PublicationDetail, error)
+	// RemoveMember removes a member; anyone may leave, owners may remove
+	// anyone and editors may remove writers.
+	RemoveMember(actorID int64, slug string, userID int64) error
+	Posts(slug string, limit, offset int64) ([]models.Blog, error)
+	// Submissions lists posts awaiting review; editors and owners only.
+	Submissions(actorID int64, slug string, limit, offset int64) ([]models.Blog, error)
+	// Feed renders the latest published posts as RSS 2.0.
+	Feed(slug string) ([]byte, error)
+}
+
+type publicationService struct {
+	repo      PublicationRepository
+	blogRepo  BlogRepository
+	userRepo  UserRepository
+	reactions ReactionRepository
+	baseURL   string
+}
+
+func NewPublicationService(r PublicationRepository, blogRepo BlogRepository, userRepo UserRepository, reactions ReactionRepository, baseURL string) PublicationService {
+	return &publicationService{repo: r, blogRepo: blogRepo, userRepo: userRepo, reactions: reactions, baseURL: baseURL}
+}
+
+// publicationRole returns userID's role in a publication, or "" when they
+// are not a member.
+func publicationRole(repo PublicationRepository, publicationID, userID int64) (string, error) {
+	role, err := repo.GetMemberRole(publicationID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("error checking membership of publication %d: %w", publicationID, err)
+	}
+	return role, nil
+}
+
+// canPublish reports whether a publication role may publish posts.
+func canPublish(role string) bool {
+	return role == PublicationRoleOwner || role == PublicationRoleEditor
+}
+
+func validPublicationFields(name, description string) (string, string, error) {
+	name = strings.TrimSpace(name)
+	description = strings.TrimSpace(description)
+	if name == "" {
+		return "", "", fmt.Errorf("%w: publication name cannot be empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > maxPublicationNameRunes {
+		return "", "", fmt.Errorf("%w: publication name cannot exceed %d characters", ErrInvalidInput, maxPublicationNameRunes)
+	}
+	if utf8.RuneCountInString(description) > maxPublicationDescriptionRunes {
+		return "", "", fmt.Errorf("%w: publication description cannot exceed %d characters", ErrInvalidInput, maxPublicationDescriptionRunes)
+	}
+	return name, description, nil
+}
+
+func (s *publicationService) get(slug string) (*models.Publication, error) {
+	pub, err := s.repo.GetPublicationBySlug(strings.ToLower(slug))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPublicationNotFound
+		}
+		return nil, fmt.Errorf("error retrieving publication %q: %w", slug, err)
+	}
+	return pub, nil
+}
+
+// getAs loads a publication and the actor's role in it, failing unless
+// the actor is a member.
+func (s *publicationService) getAs(actorID int64, slug string) (*models.Publication, string, error) {
+	pub, err := s.get(slug)
+	if err != nil {
+		return nil, "", err
+	}
+	role, err := publicationRole(s.repo, pub.ID, actorID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", ErrNotPublicationMember
+	}
+	return pub, role, nil
+}
+
+func (s *publicationService) Create(userID int64, name, slug, description string) (*models.Publication, error) {
+	name, description, err := validPublicationFields(name, description)
+	if err != nil {
+		return nil, err
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !publicationSlugPattern.MatchString(slug) {
+		return nil, fmt.Errorf("%w: slug must be 3-50 lowercase letters, digits or hyphens", ErrInvalidInput)
+	}
+
+	exists, err := s.repo.SlugExists(slug)
+	if err != nil {
+		return nil, fmt.Errorf("error checking slug: %w", err)
+	}
+	if exists {
+		return nil, ErrSlugTaken
+	}
+
+	pub := &models.Publication{Name: name, Slug: slug, Description: description}
+	if err := s.repo.CreatePublication(pub, userID); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func (s *publicationService) Get(slug string) (*PublicationDetail, error) {
+	pub, err := s.get(slug)
+	if err != nil {
+		return nil, err
+	}
+	members, err := s.repo.GetMembers(pub.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving members: %w", err)
+	}
+	return &PublicationDetail{Publication: pub, Members: members}, nil
+}
+
+func (s *publicationService) ListMine(userID int64) ([]models.Publication, error) {
+	pubs, err := s.repo.GetPublicationsByMember(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving publications for user %d: %w", userID, err)
+	}
+	return pubs, nil
+}
+
+func (s *publicationService) Update(userID int64, slug string, update PublicationUpdate) (*models.Publication, error) {
+	pub, role, err := s.getAs(userID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !canPublish(role) {
+		return nil, ErrPublicationRole
+	}
+
+	name, description := pub.Name, pub.Description
+	if update.Name != nil {
+		name = *update.Name
+	}
+	if update.Description != nil {
+		description = *update.Description
+	}
+	if pub.Name, pub.Description, err = validPublicationFields(name, description); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdatePublication(pub); err != nil {
+		return nil, err
+	}
+	return pub, nil
+}
+
+func (s *publicationService) Delete(userID int64, slug string) error {
+	pub, role, err := s.getAs(userID, slug)
+	if err != nil {
+		return err
+	}
+	if role != PublicationRoleOwner {
+		return ErrPublicationRole
+	}
+	return s.repo.DeletePublication(pub.ID)
+}
+
+func (s *publicationService) SetMember(actorID int64, slug, username, role string) (*PublicationDetail, error) {
+	role = strings.ToLower(strings.TrimSpace(role))
+	if role == "" {
+		role = PublicationRoleWriter
+	}
+	if role != PublicationRoleOwner && role != PublicationRoleEditor && role != PublicationRoleWriter {
+		return nil, fmt.Errorf("%w: role must be owner, editor or writer", ErrInvalidInput)
+	}
+
+	pub, actorRole, err := s.getAs(actorID, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetUserByUsername(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+	if err != nil || user.Status != UserStatusActive {
+		return nil, ErrUserNotFound
+	}
+
+	current, err := publicationRole(s.repo, pub.ID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	switch actorRole {
+	case PublicationRoleOwner:
+		// Demoting the last owner would leave nobody in charge
+		if current == PublicationRoleOwner && role != PublicationRoleOwner {
+			owners, err := s.repo.CountOwners(pub.ID)
+			if err != nil {
+				return nil, fmt.Errorf("error counting owners: %w", err)
+			}
+			if owners <= 1 {
+				return nil, ErrLastOwner
+			}
+		}
+	case PublicationRoleEditor:
+		if role != PublicationRoleWriter || (current != "" && current != PublicationRoleWriter) {
+			return nil, ErrPublicationRole
+		}
+	default:
+		return nil, ErrPublicationRole
+	}
+
+	if err := s.repo.SetMember(pub.ID, user.ID, role); err != nil {
+		return nil, err
+	}
+	return s.Get(pub.Slug)
+}
+
+func (s *publicationService) RemoveMember(actorID int64, slug string, userID int64) error {
+	pub, actorRole, err := s.getAs(actorID, slug)
+	if err != nil {
+		return err
+	}
+
+	role, err := publicationRole(s.repo, pub.ID, userID)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return ErrUserNotFound
+	}
+
+	allowed := actorID == userID ||
+		actorRole == PublicationRoleOwner ||
+		(actorRole == PublicationRoleEditor && role == PublicationRoleWriter)
+	if !allowed {
+		return ErrPublicationRole
+	}
+
+	if role == PublicationRoleOwner {
+		owners, err := s.repo.CountOwners(pub.ID)
+		if err != nil {
+			return fmt.Errorf("error counting owners: %w", err)
+		}
+		if owners <= 1 {
+			return ErrLastOwner
+		}
+	}
+
+	if err := s.repo.RemoveMember(pub.ID, userID); err != nil {
+		if strings.Contains(err.Error(), "no member found") {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *publicationService) posts(pub *models.Publication, status string, limit, offset int64) ([]models.Blog, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	blogs, err := s.blogRepo.GetPublicationBlogs(pub.ID, status, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error listing posts of publication %d: %w", pub.ID, err)
+	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+func (s *publicationService) Posts(slug string, limit, offset int64) ([]models.Blog, error) {
+	pub, err := s.get(slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.posts(pub, BlogStatusPublished, limit, offset)
+}
+
+func (s *publicationService) Submissions(actorID int64, slug string, limit, offset int64) ([]models.Blog, error) {
+	pub, role, err := s.getAs(actorID, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !canPublish(role) {
+		return nil, ErrPublicationRole
+	}
+	return s.posts(pub, BlogStatusSubmitted, limit, offset)
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description"`
+}
+
+func (s *publicationService) Feed(slug string) ([]byte, error) {
+	pub, err := s.get(slug)
+	if err != nil {
+		return nil, err
+	}
+	blogs, err := s.blogRepo.GetPublicationBlogs(pub.ID, BlogStatusPublished, feedItemCount, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error listing posts of publication %d: %w", pub.ID, err)
+	}
+
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       pub.Name,
+			Link:        fmt.Sprintf("%s/publications/%s", s.baseURL, pub.Slug),
+			Description: pub.Description,
+			Items:       make([]rssItem, 0, len(blogs)),
+		},
+	}
+	for _, blog := range blogs {
+		link := fmt.Sprintf("%s/blogs/%d", s.baseURL, blog.ID)
+		item := rssItem{
+			Title:       blog.Title,
+			Link:        link,
+			GUID:        link,
+			Description: blog.Excerpt,
+		}
+		if blog.PublishedAt != nil {
+			item.PubDate = blog.PublishedAt.UTC().Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("error rendering feed: %w", err)
+	}
+	return append([]byte(xml.Header), out...), nil
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// publicationTest is a publication with one member of each role, and a
+// user who isn't a member.
+type publicationTest struct {
+	s                               PublicationService
+	blogs                           *blogService
+	deps                            blogTestDeps
+	owner, editor, writer, outsider *models.User
+	pub                             *models.Publication
+}
+
+func newPublicationTest(t *testing.T) *publicationTest {
+	t.Helper()
+	blogs, deps := newTestBlogService()
+	users := &fakeUserRepo{}
+	pt := &publicationTest{
+		s:        NewPublicationService(deps.publications, deps.blogs, users, deps.reactions, "https://blog.example"),
+		blogs:    blogs,
+		deps:     deps,
+		owner:    users.add(models.User{Username: "owner", Status: UserStatusActive}),
+		editor:   users.add(models.User{Username: "editor", Status: UserStatusActive}),
+		writer:   users.add(models.User{Username: "writer", Status: UserStatusActive}),
+		outsider: users.add(models.User{Username: "outsider", Status: UserStatusActive}),
+	}
+
+	var err error
+	if pt.pub, err = pt.s.Create(pt.owner.ID, "The Team", "the-team", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pt.s.SetMember(pt.owner.ID, "the-team", "editor", PublicationRoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	// Members are added as writers unless told otherwise
+	if _, err := pt.s.SetMember(pt.owner.ID, "the-team", "@writer", ""); err != nil {
+		t.Fatal(err)
+	}
+	return pt
+}
+
+// user returns the fixture user called name.
+func (pt *publicationTest) user(name string) *models.User {
+	return map[string]*models.User{
+		"owner": pt.owner, "editor": pt.editor, "writer": pt.writer, "outsider": pt.outsider,
+	}[name]
+}
+
+func (pt *publicationTest) role(t *testing.T, userID int64) string {
+	t.Helper()
+	role, err := publicationRole(pt.deps.publications, pt.pub.ID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return role
+}
+
+func TestPublicationSetMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  string
+		target string
+		role   string
+		want   error
+	}{
+		{"owner adds an editor", "owner", "outsider", PublicationRoleEditor, nil},
+		{"owner adds an owner", "owner", "outsider", PublicationRoleOwner, nil},
+		{"owner demotes an editor", "owner", "editor", PublicationRoleWriter, nil},
+		{"editor adds a writer", "editor", "outsider", PublicationRoleWriter, nil},
+		{"editor adds an editor", "editor", "outsider", PublicationRoleEditor, ErrPublicationRole},
+		{"editor promotes a writer", "editor", "writer", PublicationRoleEditor, ErrPublicationRole},
+		{"editor demotes the owner", "editor", "owner", PublicationRoleWriter, ErrPublicationRole},
+		{"writer adds a writer", "writer", "outsider", PublicationRoleWriter, ErrPublicationRole},
+		{"outsider adds themselves", "outsider", "outsider", PublicationRoleWriter, ErrNotPublicationMember},
+		{"unknown role", "owner", "outsider", "admin", ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := newPublicationTest(t)
+			target := pt.user(tt.target)
+			before := pt.role(t, target.ID)
+
+			_, err := pt.s.SetMember(pt.user(tt.actor).ID, "the-team", tt.target, tt.role)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("SetMember err = %v, want %v", err, tt.want)
+			}
+			want := tt.role
+			if tt.want != nil {
+				want = before
+			}
+			if got := pt.role(t, target.ID); got != want {
+				t.Errorf("%s is now %q, want %q", tt.target, got, want)
+			}
+		})
+	}
+}
+
+func TestPublicationRemoveMember(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  string
+		target string
+		want   error
+	}{
+		{"writer leaves", "writer", "writer", nil},
+		{"editor leaves", "editor", "editor", nil},
+		{"editor removes a writer", "editor", "writer", nil},
+		{"owner removes an editor", "owner", "editor", nil},
+		{"editor removes the owner", "editor", "owner", ErrPublicationRole},
+		{"writer removes an editor", "writer", "editor", ErrPublicationRole},
+		{"owner removes a non-member", "owner", "outsider", ErrUserNotFound},
+		{"outsider removes a writer", "outsider", "writer", ErrNotPublicationMember},
+		{"last owner leaves", "owner", "owner", ErrLastOwner},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := newPublicationTest(t)
+			target := pt.user(tt.target)
+			before := pt.role(t, target.ID)
+
+			err := pt.s.RemoveMember(pt.user(tt.actor).ID, "the-team", target.ID)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("RemoveMember err = %v, want %v", err, tt.want)
+			}
+			want := ""
+			if tt.want != nil {
+				want = before
+			}
+			if got := pt.role(t, target.ID); got != want {
+				t.Errorf("%s is now %q, want %q", tt.target, got, want)
+			}
+		})
+	}
+}
+
+func TestPublicationLastOwner(t *testing.T) {
+	pt := newPublicationTest(t)
+
+	if _, err := pt.s.SetMember(pt.owner.ID, "the-team", "owner", PublicationRoleEditor); !errors.Is(err, ErrLastOwner) {
+		t.Fatalf("sole owner stepping down: err = %v, want ErrLastOwner", err)
+	}
+
+	// With a second owner either may step down, but not both
+	if _, err := pt.s.SetMember(pt.owner.ID, "the-team", "editor", PublicationRoleOwner); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pt.s.SetMember(pt.owner.ID, "the-team", "owner", PublicationRoleEditor); err != nil {
+		t.Fatalf("stepping down beside another owner: %v", err)
+	}
+	if got := pt.role(t, pt.owner.ID); got != PublicationRoleEditor {
+		t.Errorf("former owner is %q, want editor", got)
+	}
+	if err := pt.s.RemoveMember(pt.editor.ID, "the-team", pt.editor.ID); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("new sole owner leaving: err = %v, want ErrLastOwner", err)
+	}
+	if _, err := pt.s.SetMember(pt.editor.ID, "the-team", "editor", PublicationRoleWriter); !errors.Is(err, ErrLastOwner) {
+		t.Errorf("new sole owner stepping down: err = %v, want ErrLastOwner", err)
+	}
+}
+
+func TestPublicationManagementRights(t *testing.T) {
+	tests := []struct {
+		actor                                   string
+		wantUpdate, wantSubmissions, wantDelete error
+	}{
+		{"owner", nil, nil, nil},
+		{"editor", nil, nil, ErrPublicationRole},
+		{"writer", ErrPublicationRole, ErrPublicationRole, ErrPublicationRole},
+		{"outsider", ErrNotPublicationMember, ErrNotPublicationMember, ErrNotPublicationMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.actor, func(t *testing.T) {
+			pt := newPublicationTest(t)
+			actorID := pt.user(tt.actor).ID
+
+			name := "Renamed"
+			if _, err := pt.s.Update(actorID, "the-team", PublicationUpdate{Name: &name}); !errors.Is(err, tt.wantUpdate) {
+				t.Errorf("Update err = %v, want %v", err, tt.wantUpdate)
+			}
+			if _, err := pt.s.Submissions(actorID, "the-team", 10, 0); !errors.Is(err, tt.wantSubmissions) {
+				t.Errorf("Submissions err = %v, want %v", err, tt.wantSubmissions)
+			}
+			if err := pt.s.Delete(actorID, "the-team"); !errors.Is(err, tt.wantDelete) {
+				t.Errorf("Delete err = %v, want %v", err, tt.wantDelete)
+			}
+			_, err := pt.s.Get("the-team")
+			if deleted := errors.Is(err, ErrPublicationNotFound); deleted != (tt.wantDelete == nil) {
+				t.Errorf("after Delete: Get err = %v", err)
+			}
+		})
+	}
+}
+
+func TestPublicationPostsNeedAnEditor(t *testing.T) {
+	pt := newPublicationTest(t)
+	post := func(userID int64) *models.Blog {
+		return &models.Blog{UserId: userID, Title: "Team news", Content: "Some news", PublicationID: &pt.pub.ID}
+	}
+
+	if err := pt.blogs.Create(post(pt.outsider.ID), ""); !errors.Is(err, ErrNotPublicationMember) {
+		t.Errorf("outsider posting: err = %v, want ErrNotPublicationMember", err)
+	}
+
+	// A writer's post waits for an editor instead of going live
+	submitted := post(pt.writer.ID)
+	if err := pt.blogs.Create(submitted, ""); err != nil {
+		t.Fatal(err)
+	}
+	if submitted.Status != BlogStatusSubmitted || submitted.PublishedAt != nil {
+		t.Fatalf("writer's post is %q, want submitted and unpublished", submitted.Status)
+	}
+	if events := pt.deps.reviews.events; len(events) != 1 || events[0].Action != reviewActionSubmitted {
+		t.Errorf("review events = %+v, want one submission", events)
+	}
+	queue, err := pt.s.Submissions(pt.editor.ID, "the-team", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queue) != 1 || queue[0].ID != submitted.ID {
+		t.Errorf("submissions = %+v, want the writer's post", queue)
+	}
+
+	if _, err := pt.blogs.Publish(submitted.ID, pt.writer.ID); !errors.Is(err, ErrPublicationRole) {
+		t.Errorf("writer publishing: err = %v, want ErrPublicationRole", err)
+	}
+	published, err := pt.blogs.Publish(submitted.ID, pt.editor.ID)
+	if err != nil {
+		t.Fatalf("editor publishing: %v", err)
+	}
+	if published.Status != BlogStatusPublished {
+		t.Errorf("status = %q, want published", published.Status)
+	}
+
+	// Editors publish their own posts directly
+	direct := post(pt.editor.ID)
+	if err := pt.blogs.Create(direct, ""); err != nil {
+		t.Fatal(err)
+	}
+	if direct.Status != BlogStatusPublished {
+		t.Errorf("editor's post is %q, want published", direct.Status)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// ReactionLike is the reaction behind "posts I liked".
+const ReactionLike = "like"
+
+// ReactionTypes is the fixed set of reactions and the emoji clients show.
+var ReactionTypes = map[string]string{
+	ReactionLike: "👍",
+	"love":       "❤️",
+	"laugh":      "😂",
+	"clap":       "👏",
+	"fire":       "🔥",
+	"insightful": "💡",
+}
+
+var ErrBlogNotFound = errors.New("blog not found")
+
+// ReactionRepository defines the interface for reaction data operations
+type ReactionRepository interface {
+	AddReaction(blogID, userID int64, reaction string) (bool, error)
+	RemoveReaction(blogID, userID int64, reaction string) (bool, error)
+	GetReactionCounts(blogIDs []int64) ([]models.ReactionCount, error)
+	GetUserReactions(blogID, userID int64) ([]string, error)
+}
+
+// ReactionSummary is the reaction state of one blog.
+type ReactionSummary struct {
+	Reactions map[string]int `json:"reactions"`
+	// Mine lists the current user's reactions, when known
+	Mine []string `json:"mine,omitempty"`
+}
+
+// ReactionService lets readers react to blogs. Every method needs the
+// caller to be able to read the blog (see BlogService.View), with password
+// the access password of a password-protected post; other blogs are
+// reported as ErrBlogNotFound.
+type ReactionService interface {
+	// Toggle adds the reaction, or removes it if the user already left it.
+	Toggle(blogID, userID int64, reaction string, password string) (*ReactionSummary, error)
+	Remove(blogID, userID int64, reaction string, password string) (*ReactionSummary, error)
+	// Summary returns reaction counts for a blog and, when viewerID is
+	// non-zero, that user's own reactions.
+	Summary(blogID, viewerID int64, password string) (*ReactionSummary, error)
+}
+
+type reactionService struct {
+	repo  ReactionRepository
+	blogs BlogService
+}
+
+func NewReactionService(r ReactionRepository, blogs BlogService) ReactionService {
+	return &reactionService{repo: r, blogs: blogs}
+}
+
+// readable returns the blog if viewerID may read it.
+func (s *reactionService) readable(blogID, viewerID int64, password string) (*models.Blog, error) {
+	blog, err := s.blogs.View(blogID, viewerID, password)
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, ErrFollowersOnly) {
+		return nil, ErrBlogNotFound
+	}
+	return blog, err
+}
+
+func validReaction(reaction string) (string, error) {
+	reaction = strings.ToLower(strings.TrimSpace(reaction))
+	if _, ok := ReactionTypes[reaction]; !ok {
+		return "", fmt.Errorf("%w: unknown reaction %q", ErrInvalidInput, reaction)
+	}
+	return reaction, nil
+}
+
+func (s *reactionService) Toggle(blogID, userID int64, reaction string, password string) (*ReactionSummary, error) {
+	reaction, err := validReaction(reaction)
+	if err != nil {
+		return nil, err
+	}
+	blog, err := s.readable(blogID, userID, password)
+	if err != nil {
+		return nil, err
+	}
+	// Authors and reviewers can read drafts, but only published posts
+	// take reactions
+	if blog.Status != BlogStatusPublished {
+		return nil, ErrBlogNotFound
+	}
+
+	removed, err := s.repo.RemoveReaction(blogID, userID, reaction)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		if _, err := s.repo.AddReaction(blogID, userID, reaction); err != nil {
+			return nil, err
+		}
+	}
+	return s.summary(blogID, userID)
+}
+
+func (s *reactionService) Remove(blogID, userID int64, reaction string, password string) (*ReactionSummary, error) {
+	reaction, err := validReaction(reaction)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.readable(blogID, userID, password); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.RemoveReaction(blogID, userID, reaction); err != nil {
+		return nil, err
+	}
+	return s.summary(blogID, userID)
+}
+
+func (s *reactionService) Summary(blogID, viewerID int64, password string) (*ReactionSummary, error) {
+	if _, err := s.readable(blogID, viewerID, password); err != nil {
+		return nil, err
+	}
+	return s.summary(blogID, viewerID)
+}
+
+func (s *reactionService) summary(blogID, userID int64) (*ReactionSummary, error) {
+	counts, err := reactionCounts(s.repo, []int64{blogID})
+	if err != nil {
+		return nil, err
+	}
+	summary := &ReactionSummary{Reactions: counts[blogID]}
+
+	if userID != 0 {
+		summary.Mine, err = s.repo.GetUserReactions(blogID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving reactions: %w", err)
+		}
+	}
+	return summary, nil
+}
+
+// reactionCounts returns counts per blog; every requested blog gets a
+// (possibly empty) map.
+func reactionCounts(repo ReactionRepository, blogIDs []int64) (map[int64]map[string]int, error) {
+	rows, err := repo.GetReactionCounts(blogIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error counting reactions: %w", err)
+	}
+
+	counts := make(map[int64]map[string]int, len(blogIDs))
+	for _, id := range blogIDs {
+		counts[id] = map[string]int{}
+	}
+	for _, row := range rows {
+		counts[row.BlogID][row.Reaction] = row.Count
+	}
+	return counts, nil
+}
+
+// attachReactions fills in Reactions on each blog with a single query.
+func attachReactions(repo ReactionRepository, blogs []models.Blog) error {
+	ids := make([]int64, len(blogs))
+	for i := range blogs {
+		ids[i] = blogs[i].ID
+	}
+	counts, err := reactionCounts(repo, ids)
+	if err != nil {
+		return err
+	}
+	for i := range blogs {
+		blogs[i].Reactions = counts[blogs[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Users and posts of the readability fixture: author wrote one post of
+// each kind, follower follows them and stranger does not.
+const (
+	fixtureAuthor   = 9
+	fixtureFollower = 20
+	fixtureStranger = 21
+
+	publicPost    = 1
+	unlistedPost  = 2
+	followersPost = 3
+	privatePost   = 4
+	draftPost     = 5
+	trashedPost   = 6
+)
+
+func newReadabilityFixture() (*blogService, blogTestDeps) {
+	trashedAt := time.Now()
+	s, deps := newTestBlogService(
+		models.Blog{ID: publicPost, UserId: fixtureAuthor, Title: "public"},
+		models.Blog{ID: unlistedPost, UserId: fixtureAuthor, Title: "unlisted", Visibility: BlogVisibilityUnlisted},
+		models.Blog{ID: followersPost, UserId: fixtureAuthor, Title: "followers", Visibility: BlogVisibilityFollowers},
+		models.Blog{ID: privatePost, UserId: fixtureAuthor, Title: "private", Visibility: BlogVisibilityPrivate},
+		models.Blog{ID: draftPost, UserId: fixtureAuthor, Title: "draft", Status: BlogStatusDraft},
+		models.Blog{ID: trashedPost, UserId: fixtureAuthor, Title: "trashed", DeletedAt: &trashedAt},
+	)
+	deps.follows.Follow(fixtureFollower, fixtureAuthor)
+	return s, deps
+}
+
+func TestReactionsNeedReadablePublishedPost(t *testing.T) {
+	tests := []struct {
+		name   string
+		blogID int64
+		userID int64
+		want   error
+	}{
+		{"public", publicPost, fixtureStranger, nil},
+		{"unlisted", unlistedPost, fixtureStranger, nil},
+		{"followers by a follower", followersPost, fixtureFollower, nil},
+		{"followers by a stranger", followersPost, fixtureStranger, ErrBlogNotFound},
+		{"private", privatePost, fixtureStranger, ErrBlogNotFound},
+		{"private by its author", privatePost, fixtureAuthor, nil},
+		{"draft", draftPost, fixtureStranger, ErrBlogNotFound},
+		// The author can read the draft but it takes no reactions yet
+		{"draft by its author", draftPost, fixtureAuthor, ErrBlogNotFound},
+		{"trashed", trashedPost, fixtureAuthor, ErrBlogNotFound},
+		{"missing", 99, fixtureStranger, ErrBlogNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blogs, deps := newReadabilityFixture()
+			s := NewReactionService(deps.reactions, blogs)
+
+			summary, err := s.Toggle(tt.blogID, tt.userID, "Fire", "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Toggle err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(deps.reactions.reactions[tt.blogID]) != 0 {
+					t.Error("reaction stored on a post the user can't react to")
+				}
+				// Counts of posts the user can't read stay hidden too
+				if _, err := s.Summary(tt.blogID, tt.userID, ""); tt.userID != fixtureAuthor && !errors.Is(err, ErrBlogNotFound) {
+					t.Errorf("Summary err = %v, want ErrBlogNotFound", err)
+				}
+				return
+			}
+			if summary.Reactions["fire"] != 1 || len(summary.Mine) != 1 || summary.Mine[0] != "fire" {
+				t.Errorf("summary = %+v, want one fire reaction of mine", summary)
+			}
+		})
+	}
+}
+
+func TestReactionToggle(t *testing.T) {
+	blogs, deps := newReadabilityFixture()
+	s := NewReactionService(deps.reactions, blogs)
+
+	if _, err := s.Toggle(publicPost, fixtureStranger, "shrug", ""); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("unknown reaction: err = %v, want ErrInvalidInput", err)
+	}
+
+	if _, err := s.Toggle(publicPost, fixtureStranger, ReactionLike, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Toggle(publicPost, fixtureFollower, ReactionLike, ""); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := s.Toggle(publicPost, fixtureStranger, ReactionLike, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Reactions[ReactionLike] != 1 || len(summary.Mine) != 0 {
+		t.Errorf("after toggling off: %+v, want one like left, none mine", summary)
+	}
+
+	// Anonymous readers see counts but no reactions of their own
+	summary, err = s.Summary(publicPost, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Reactions[ReactionLike] != 1 || summary.Mine != nil {
+		t.Errorf("anonymous summary = %+v", summary)
+	}
+}
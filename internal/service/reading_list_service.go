@@ -0,0 +1,256 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Limits on reading lists, to keep a single list cheap to render.
+const (
+	maxReadingListsPerUser  = 50
+	maxReadingListItems     = 500
+	maxReadingListNameRunes = 100
+)
+
+var ErrReadingListNotFound = errors.New("reading list not found")
+
+// ReadingListRepository defines the interface for reading list data operations
+type ReadingListRepository interface {
+	CreateList(list *models.ReadingList) error
+	GetListByID(id int64) (*models.ReadingList, error)
+	GetListsByUser(userID int64, publicOnly bool) ([]models.ReadingList, error)
+	CountLists(userID int64) (int, error)
+	UpdateList(list *models.ReadingList) error
+	DeleteList(listID, userID int64) error
+	AddItem(listID, blogID int64) (bool, error)
+	RemoveItem(listID, blogID int64) error
+	GetItemIDs(listID int64) ([]int64, error)
+	ReorderItems(listID int64, blogIDs []int64) error
+}
+
+// ReadingListDetail is a reading list together with its blogs in order.
+type ReadingListDetail struct {
+	*models.ReadingList
+	Items []models.Blog `json:"items"`
+}
+
+// ReadingListUpdate holds the fields to change; nil fields are left as is.
+type ReadingListUpdate struct {
+	Name     *string
+	IsPublic *bool
+}
+
+// ReadingListService manages named, ordered reading lists. Private lists
+// are only visible to their owner and are reported as not found to anyone
+// else.
+type ReadingListService interface {
+	Create(userID int64, name string, isPublic bool) (*models.ReadingList, error)
+	List(userID int64) ([]models.ReadingList, error)
+	ListPublic(ownerID int64) ([]models.ReadingList, error)
+	Get(listID, viewerID int64) (*ReadingListDetail, error)
+	Update(userID, listID int64, update ReadingListUpdate) (*models.ReadingList, error)
+	Delete(userID, listID int64) error
+	AddItem(userID, listID, blogID int64) error
+	RemoveItem(userID, listID, blogID int64) error
+	// Reorder sets the order of a list; blogIDs must name every item once.
+	Reorder(userID, listID int64, blogIDs []int64) error
+}
+
+type readingListService struct {
+	repo      ReadingListRepository
+	blogRepo  BlogRepository
+	follows   FollowRepository
+	reactions ReactionRepository
+}
+
+func NewReadingListService(r ReadingListRepository, blogRepo BlogRepository, follows FollowRepository, reactions ReactionRepository) ReadingListService {
+	return &readingListService{repo: r, blogRepo: blogRepo, follows: follows, reactions: reactions}
+}
+
+func validReadingListName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("%w: list name cannot be empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(name) > maxReadingListNameRunes {
+		return "", fmt.Errorf("%w: list name cannot exceed %d characters", ErrInvalidInput, maxReadingListNameRunes)
+	}
+	return name, nil
+}
+
+// owned loads a list and checks it belongs to userID.
+func (s *readingListService) owned(userID, listID int64) (*models.ReadingList, error) {
+	list, err := s.repo.GetListByID(listID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReadingListNotFound
+		}
+		return nil, fmt.Errorf("error retrieving reading list %d: %w", listID, err)
+	}
+	if list.UserID != userID {
+		return nil, ErrReadingListNotFound
+	}
+	return list, nil
+}
+
+func (s *readingListService) Create(userID int64, name string, isPublic bool) (*models.ReadingList, error) {
+	name, err := validReadingListName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountLists(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error counting reading lists: %w", err)
+	}
+	if count >= maxReadingListsPerUser {
+		return nil, fmt.Errorf("%w: you cannot have more than %d reading lists", ErrInvalidInput, maxReadingListsPerUser)
+	}
+
+	list := &models.ReadingList{UserID: userID, Name: name, IsPublic: isPublic}
+	if err := s.repo.CreateList(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *readingListService) List(userID int64) ([]models.ReadingList, error) {
+	lists, err := s.repo.GetListsByUser(userID, false)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving reading lists for user %d: %w", userID, err)
+	}
+	return lists, nil
+}
+
+func (s *readingListService) ListPublic(ownerID int64) ([]models.ReadingList, error) {
+	lists, err := s.repo.GetListsByUser(ownerID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving reading lists for user %d: %w", ownerID, err)
+	}
+	return lists, nil
+}
+
+// Get returns a list with its blogs. viewerID is 0 for anonymous readers.
+func (s *readingListService) Get(listID, viewerID int64) (*ReadingListDetail, error) {
+	list, err := s.repo.GetListByID(listID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrReadingListNotFound
+		}
+		return nil, fmt.Errorf("error retrieving reading list %d: %w", listID, err)
+	}
+	if !list.IsPublic && list.UserID != viewerID {
+		return nil, ErrReadingListNotFound
+	}
+
+	blogs, err := s.blogRepo.GetReadingListBlogs(listID, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving blogs on reading list %d: %w", listID, err)
+	}
+	if err := attachReactions(s.reactions, blogs); err != nil {
+		return nil, err
+	}
+	return &ReadingListDetail{ReadingList: list, Items: blogs}, nil
+}
+
+func (s *readingListService) Update(userID, listID int64, update ReadingListUpdate) (*models.ReadingList, error) {
+	list, err := s.owned(userID, listID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		if list.Name, err = validReadingListName(*update.Name); err != nil {
+			return nil, err
+		}
+	}
+	if update.IsPublic != nil {
+		list.IsPublic = *update.IsPublic
+	}
+
+	if err := s.repo.UpdateList(list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (s *readingListService) Delete(userID, listID int64) error {
+	if err := s.repo.DeleteList(listID, userID); err != nil {
+		if strings.Contains(err.Error(), "no reading list found") {
+			return ErrReadingListNotFound
+		}
+		return fmt.Errorf("deletion failed: %w", err)
+	}
+	return nil
+}
+
+// AddItem appends a blog to a list; adding it again is not an error.
+func (s *readingListService) AddItem(userID, listID, blogID int64) error {
+	list, err := s.owned(userID, listID)
+	if err != nil {
+		return err
+	}
+	if list.ItemCount >= maxReadingListItems {
+		return fmt.Errorf("%w: a reading list cannot hold more than %d blogs", ErrInvalidInput, maxReadingListItems)
+	}
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	ok, err := canSave(s.follows, blog, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrBlogNotFound
+	}
+
+	if _, err := s.repo.AddItem(listID, blogID); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *readingListService) RemoveItem(userID, listID, blogID int64) error {
+	if _, err := s.owned(userID, listID); err != nil {
+		return err
+	}
+	if err := s.repo.RemoveItem(listID, blogID); err != nil {
+		if strings.Contains(err.Error(), "no reading list item found") {
+			return ErrBlogNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *readingListService) Reorder(userID, listID int64, blogIDs []int64) error {
+	if _, err := s.owned(userID, listID); err != nil {
+		return err
+	}
+
+	current, err := s.repo.GetItemIDs(listID)
+	if err != nil {
+		return fmt.Errorf("error retrieving reading list items: %w", err)
+	}
+	if len(blogIDs) != len(current) {
+		return fmt.Errorf("%w: blog_ids must list every blog on the reading list exactly once", ErrInvalidInput)
+	}
+	remaining := make(map[int64]bool, len(current))
+	for _, id := range current {
+		remaining[id] = true
+	}
+	for _, id := range blogIDs {
+		if !remaining[id] {
+			return fmt.Errorf("%w: blog_ids must list every blog on the reading list exactly once", ErrInvalidInput)
+		}
+		delete(remaining, id)
+	}
+
+	return s.repo.ReorderItems(listID, blogIDs)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// fakeReadingListRepo keeps lists by ID and their items in order.
+type fakeReadingListRepo struct {
+	ReadingListRepository
+	lists map[int64]*models.ReadingList
+	items map[int64][]int64
+}
+
+func newFakeReadingListRepo() *fakeReadingListRepo {
+	return &fakeReadingListRepo{lists: map[int64]*models.ReadingList{}, items: map[int64][]int64{}}
+}
+
+func (r *fakeReadingListRepo) CreateList(list *models.ReadingList) error {
+	list.ID = int64(len(r.lists) + 1)
+	stored := *list
+	r.lists[list.ID] = &stored
+	return nil
+}
+
+func (r *fakeReadingListRepo) GetListByID(id int64) (*models.ReadingList, error) {
+	list, ok := r.lists[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	found := *list
+	found.ItemCount = len(r.items[id])
+	return &found, nil
+}
+
+func (r *fakeReadingListRepo) CountLists(userID int64) (int, error) {
+	n := 0
+	for _, list := range r.lists {
+		if list.UserID == userID {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeReadingListRepo) AddItem(listID, blogID int64) (bool, error) {
+	for _, id := range r.items[listID] {
+		if id == blogID {
+			return false, nil
+		}
+	}
+	r.items[listID] = append(r.items[listID], blogID)
+	return true, nil
+}
+
+func (r *fakeReadingListRepo) RemoveItem(listID, blogID int64) error {
+	items := r.items[listID]
+	for i, id := range items {
+		if id == blogID {
+			r.items[listID] = append(items[:i:i], items[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no reading list item found for blog %d", blogID)
+}
+
+func (r *fakeReadingListRepo) GetItemIDs(listID int64) ([]int64, error) {
+	return append([]int64{}, r.items[listID]...), nil
+}
+
+func (r *fakeReadingListRepo) ReorderItems(listID int64, blogIDs []int64) error {
+	r.items[listID] = append([]int64{}, blogIDs...)
+	return nil
+}
+
+// listBlogRepo serves a reading list's items from the fake reading list
+// repository, in order.
+type listBlogRepo struct {
+	*fakeBlogRepo
+	lists *fakeReadingListRepo
+}
+
+func (r *listBlogRepo) GetReadingListBlogs(listID, viewerID int64) ([]models.Blog, error) {
+	var blogs []models.Blog
+	for _, id := range r.lists.items[listID] {
+		blogs = append(blogs, *r.blogs[id])
+	}
+	return blogs, nil
+}
+
+func newTestReadingListService() (ReadingListService, *fakeReadingListRepo) {
+	_, deps := newReadabilityFixture()
+	lists := newFakeReadingListRepo()
+	blogs := &listBlogRepo{fakeBlogRepo: deps.blogs, lists: lists}
+	return NewReadingListService(lists, blogs, deps.follows, deps.reactions), lists
+}
+
+func TestReadingListAddItem(t *testing.T) {
+	for _, tt := range saveTests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, lists := newTestReadingListService()
+			list, err := s.Create(tt.userID, "Later", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.AddItem(tt.userID, list.ID, tt.blogID); !errors.Is(err, tt.want) {
+				t.Fatalf("AddItem err = %v, want %v", err, tt.want)
+			}
+			if stored := len(lists.items[list.ID]) == 1; stored != (tt.want == nil) {
+				t.Errorf("item stored = %v, want %v", stored, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestReadingListOwnership(t *testing.T) {
+	s, _ := newTestReadingListService()
+	private, err := s.Create(fixtureFollower, "Mine", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	public, err := s.Create(fixtureFollower, "Shared", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddItem(fixtureFollower, public.ID, publicPost); err != nil {
+		t.Fatal(err)
+	}
+
+	// Private lists are not found by anyone else, and nobody else can
+	// change either list
+	if _, err := s.Get(private.ID, fixtureStranger); !errors.Is(err, ErrReadingListNotFound) {
+		t.Errorf("Get someone's private list: err = %v, want ErrReadingListNotFound", err)
+	}
+	if _, err := s.Get(private.ID, fixtureFollower); err != nil {
+		t.Errorf("Get own private list: %v", err)
+	}
+	detail, err := s.Get(public.ID, 0)
+	if err != nil {
+		t.Fatalf("Get public list anonymously: %v", err)
+	}
+	if len(detail.Items) != 1 || detail.Items[0].ID != publicPost {
+		t.Errorf("items = %+v, want the public post", detail.Items)
+	}
+	if err := s.AddItem(fixtureStranger, public.ID, unlistedPost); !errors.Is(err, ErrReadingListNotFound) {
+		t.Errorf("AddItem to someone's list: err = %v, want ErrReadingListNotFound", err)
+	}
+	if err := s.RemoveItem(fixtureStranger, public.ID, publicPost); !errors.Is(err, ErrReadingListNotFound) {
+		t.Errorf("RemoveItem from someone's list: err = %v, want ErrReadingListNotFound", err)
+	}
+}
+
+func TestReadingListReorder(t *testing.T) {
+	s, lists := newTestReadingListService()
+	list, err := s.Create(fixtureFollower, "Later", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int64{publicPost, unlistedPost, followersPost} {
+		if err := s.AddItem(fixtureFollower, list.ID, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ids := range [][]int64{
+		{publicPost, unlistedPost},
+		{publicPost, unlistedPost, unlistedPost},
+		{publicPost, unlistedPost, privatePost},
+	} {
+		if err := s.Reorder(fixtureFollower, list.ID, ids); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("Reorder(%v) err = %v, want ErrInvalidInput", ids, err)
+		}
+	}
+
+	want := []int64{followersPost, publicPost, unlistedPost}
+	if err := s.Reorder(fixtureFollower, list.ID, want); err != nil {
+		t.Fatalf("Reorder: %v", err)
+	}
+	if got := lists.items[list.ID]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,408 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Review decisions as sent by reviewers, and the history actions they and
+// the rest of the workflow are recorded as.
+const (
+	ReviewDecisionApprove        = "approve"
+	ReviewDecisionRequestChanges = "request_changes"
+
+	reviewActionSubmitted        = "submitted"
+	reviewActionAssigned         = "reviewer_assigned"
+	reviewActionUnassigned       = "reviewer_removed"
+	reviewActionApproved         = "approved"
+	reviewActionChangesRequested = "changes_requested"
+)
+
+const (
+	maxReviewCommentRunes = 5000
+	maxReviewNoteRunes    = 2000
+)
+
+var (
+	ErrNotReviewer           = errors.New("you are not a reviewer of this post")
+	ErrReviewerNotFound      = errors.New("reviewer not found")
+	ErrReviewCommentNotFound = errors.New("review comment not found")
+	ErrReviewNotAllowed      = errors.New("you cannot do that on this review")
+)
+
+// ReviewRepository defines the interface for editorial review data operations
+type ReviewRepository interface {
+	AddReviewer(blogID, userID, assignedBy int64) (bool, error)
+	RemoveReviewer(blogID, userID int64) error
+	IsReviewer(blogID, userID int64) (bool, error)
+	GetReviewers(blogID int64) ([]models.BlogReviewer, error)
+	AddComment(comment *models.ReviewComment) error
+	GetComment(blogID, commentID int64) (*models.ReviewComment, error)
+	GetComments(blogID int64) ([]models.ReviewComment, error)
+	GetCommentsByUser(userID int64) ([]models.ReviewComment, error)
+	SetCommentResolved(blogID, commentID int64, resolved bool) error
+	AddEvent(blogID, userID int64, action, note string) error
+	GetEvents(blogID int64) ([]models.ReviewEvent, error)
+}
+
+// ReviewSummary is everything the author and reviewers see of a review.
+type ReviewSummary struct {
+	BlogID    int64                  `json:"blog_id"`
+	Status    string                 `json:"status"`
+	Reviewers []models.BlogReviewer  `json:"reviewers"`
+	Comments  []models.ReviewComment `json:"comments"`
+	History   []models.ReviewEvent   `json:"history"`
+}
+
+// ReviewCommentInput is a new review comment. Start and End, when both
+// set, anchor it to that rune range of the blog content.
+type ReviewCommentInput struct {
+	Body  string
+	Start *int
+	End   *int
+}
+
+// ReviewService runs the editorial review of submitted posts. Authors
+// submit a draft to assigned reviewers (and, for publication posts, the
+// publication's editors), who comment on it and approve it or request
+// changes, which returns it to draft.
+type ReviewService interface {
+	// Submit moves a draft to submitted, assigning the given reviewers.
+	// Personal posts need at least one reviewer.
+	Submit(userID, blogID int64, reviewers []string) (*ReviewSummary, error)
+	AssignReviewer(actorID, blogID int64, username string) (*ReviewSummary, error)
+	// RemoveReviewer unassigns a reviewer; reviewers may remove themselves.
+	RemoveReviewer(actorID, blogID, reviewerID int64) error
+	Summary(viewerID, blogID int64) (*ReviewSummary, error)
+	Comment(userID, blogID int64, input ReviewCommentInput) (*models.ReviewComment, error)
+	// ResolveComment resolves or reopens a comment; open to the post's
+	// authors and the comment's writer.
+	ResolveComment(userID, blogID, commentID int64, resolved bool) (*models.ReviewComment, error)
+	Decide(userID, blogID int64, decision, note string) (*ReviewSummary, error)
+	// AwaitingReview lists submitted posts the user is assigned to review.
+	AwaitingReview(userID int64) ([]models.Blog, error)
+}
+
+type reviewService struct {
+	repo         ReviewRepository
+	blogRepo     BlogRepository
+	userRepo     UserRepository
+	coAuthors    CoAuthorRepository
+	publications PublicationRepository
+}
+
+func NewReviewService(r ReviewRepository, blogRepo BlogRepository, userRepo UserRepository, coAuthors CoAuthorRepository, publications PublicationRepository) ReviewService {
+	return &reviewService{repo: r, blogRepo: blogRepo, userRepo: userRepo, coAuthors: coAuthors, publications: publications}
+}
+
+// isReviewer reports whether userID may review a blog: an assigned
+// reviewer, or an editor of the blog's publication.
+func isReviewer(repo ReviewRepository, publications PublicationRepository, blog *models.Blog, userID int64) (bool, error) {
+	assigned, err := repo.IsReviewer(blog.ID, userID)
+	if err != nil || assigned {
+		return assigned, err
+	}
+	if blog.PublicationID == nil {
+		return false, nil
+	}
+	role, err := publicationRole(publications, *blog.PublicationID, userID)
+	return canPublish(role), err
+}
+
+// access loads a blog and works out userID's part in its review. Users
+// with no part get ErrBlogNotFound for unpublished posts, so drafts stay
+// hidden, and ErrNotReviewer otherwise.
+func (s *reviewService) access(blogID, userID int64) (blog *models.Blog, authorRole string, reviewer bool, err error) {
+	blog, err = s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, "", false, ErrBlogNotFound
+	}
+	if authorRole, err = blogRole(s.coAuthors, blog, userID); err != nil {
+		return nil, "", false, err
+	}
+	if reviewer, err = isReviewer(s.repo, s.publications, blog, userID); err != nil {
+		return nil, "", false, err
+	}
+	if authorRole == "" && !reviewer {
+		if blog.Status != BlogStatusPublished {
+			return nil, "", false, ErrBlogNotFound
+		}
+		return nil, "", false, ErrNotReviewer
+	}
+	return blog, authorRole, reviewer, nil
+}
+
+// canManageReviewers reports whether userID may assign reviewers: the
+// post's owner, or an editor of its publication.
+func (s *reviewService) canManageReviewers(blog *models.Blog, userID int64) (bool, error) {
+	if blog.UserId == userID {
+		return true, nil
+	}
+	if blog.PublicationID == nil {
+		return false, nil
+	}
+	role, err := publicationRole(s.publications, *blog.PublicationID, userID)
+	return canPublish(role), err
+}
+
+// assign resolves username and makes them a reviewer of blog.
+func (s *reviewService) assign(blog *models.Blog, actorID int64, username string) error {
+	user, err := s.userRepo.GetUserByUsername(strings.TrimPrefix(strings.TrimSpace(username), "@"))
+	if err != nil || user.Status != UserStatusActive {
+		return ErrUserNotFound
+	}
+	role, err := blogRole(s.coAuthors, blog, user.ID)
+	if err != nil {
+		return err
+	}
+	if role != "" {
+		return fmt.Errorf("%w: %s is an author of this post and cannot review it", ErrInvalidInput, user.Username)
+	}
+
+	added, err := s.repo.AddReviewer(blog.ID, user.ID, actorID)
+	if err != nil || !added {
+		return err
+	}
+	return s.repo.AddEvent(blog.ID, actorID, reviewActionAssigned, user.Username)
+}
+
+func (s *reviewService) Submit(userID, blogID int64, reviewers []string) (*ReviewSummary, error) {
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return nil, ErrBlogNotFound
+	}
+	if blog.UserId != userID {
+		return nil, ErrNotBlogOwner
+	}
+	if blog.Status != BlogStatusDraft {
+		return nil, fmt.Errorf("%w: only drafts can be submitted", ErrInvalidInput)
+	}
+	if blog.PublicationID == nil && len(reviewers) == 0 {
+		return nil, fmt.Errorf("%w: personal posts need at least one reviewer", ErrInvalidInput)
+	}
+
+	for _, username := range reviewers {
+		if err := s.assign(blog, userID, username); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.blogRepo.SetBlogStatus(blogID, BlogStatusSubmitted); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddEvent(blogID, userID, reviewActionSubmitted, ""); err != nil {
+		return nil, err
+	}
+	return s.Summary(userID, blogID)
+}
+
+func (s *reviewService) AssignReviewer(actorID, blogID int64, username string) (*ReviewSummary, error) {
+	blog, _, _, err := s.access(blogID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := s.canManageReviewers(blog, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrReviewNotAllowed
+	}
+	if blog.Status == BlogStatusPublished {
+		return nil, fmt.Errorf("%w: this post is already published", ErrInvalidInput)
+	}
+
+	if err := s.assign(blog, actorID, username); err != nil {
+		return nil, err
+	}
+	return s.Summary(actorID, blogID)
+}
+
+func (s *reviewService) RemoveReviewer(actorID, blogID, reviewerID int64) error {
+	blog, _, _, err := s.access(blogID, actorID)
+	if err != nil {
+		return err
+	}
+	if actorID != reviewerID {
+		ok, err := s.canManageReviewers(blog, actorID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrReviewNotAllowed
+		}
+	}
+
+	if err := s.repo.RemoveReviewer(blogID, reviewerID); err != nil {
+		if strings.Contains(err.Error(), "no reviewer found") {
+			return ErrReviewerNotFound
+		}
+		return fmt.Errorf("removing reviewer failed: %w", err)
+	}
+
+	note := ""
+	if user, err := s.userRepo.GetByID(reviewerID); err == nil {
+		note = user.Username
+	}
+	return s.repo.AddEvent(blogID, actorID, reviewActionUnassigned, note)
+}
+
+func (s *reviewService) Summary(viewerID, blogID int64) (*ReviewSummary, error) {
+	blog, _, _, err := s.access(blogID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewers, err := s.repo.GetReviewers(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving reviewers of blog %d: %w", blogID, err)
+	}
+	comments, err := s.repo.GetComments(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving review comments of blog %d: %w", blogID, err)
+	}
+	history, err := s.repo.GetEvents(blogID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving review history of blog %d: %w", blogID, err)
+	}
+
+	content := []rune(blog.Content)
+	for i := range comments {
+		markOutdated(&comments[i], content)
+	}
+
+	return &ReviewSummary{
+		BlogID:    blogID,
+		Status:    blog.Status,
+		Reviewers: reviewers,
+		Comments:  comments,
+		History:   history,
+	}, nil
+}
+
+// markOutdated flags an anchored comment whose range no longer holds the
+// text it was written against.
+func markOutdated(comment *models.ReviewComment, content []rune) {
+	if comment.AnchorStart == nil || comment.AnchorEnd == nil {
+		return
+	}
+	start, end := *comment.AnchorStart, *comment.AnchorEnd
+	comment.Outdated = end > len(content) || string(content[start:end]) != comment.QuotedText
+}
+
+func (s *reviewService) Comment(userID, blogID int64, input ReviewCommentInput) (*models.ReviewComment, error) {
+	blog, _, _, err := s.access(blogID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, fmt.Errorf("%w: comment cannot be empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(body) > maxReviewCommentRunes {
+		return nil, fmt.Errorf("%w: comment must be at most %d characters", ErrInvalidInput, maxReviewCommentRunes)
+	}
+
+	comment := &models.ReviewComment{BlogID: blogID, UserID: &userID, Body: body}
+	if input.Start != nil || input.End != nil {
+		content := []rune(blog.Content)
+		if input.Start == nil || input.End == nil || *input.Start < 0 || *input.Start >= *input.End || *input.End > len(content) {
+			return nil, fmt.Errorf("%w: start and end must select a range of the post content (0 to %d)", ErrInvalidInput, len(content))
+		}
+		comment.AnchorStart, comment.AnchorEnd = input.Start, input.End
+		comment.QuotedText = string(content[*input.Start:*input.End])
+	}
+
+	if err := s.repo.AddComment(comment); err != nil {
+		return nil, err
+	}
+	return s.repo.GetComment(blogID, comment.ID)
+}
+
+func (s *reviewService) ResolveComment(userID, blogID, commentID int64, resolved bool) (*models.ReviewComment, error) {
+	blog, authorRole, _, err := s.access(blogID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := s.repo.GetComment(blogID, commentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReviewCommentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving review comment %d: %w", commentID, err)
+	}
+	if authorRole == "" && (comment.UserID == nil || *comment.UserID != userID) {
+		return nil, ErrReviewNotAllowed
+	}
+
+	if err := s.repo.SetCommentResolved(blogID, commentID, resolved); err != nil {
+		if strings.Contains(err.Error(), "no review comment found") {
+			return nil, ErrReviewCommentNotFound
+		}
+		return nil, fmt.Errorf("updating review comment failed: %w", err)
+	}
+
+	comment, err = s.repo.GetComment(blogID, commentID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving review comment %d: %w", commentID, err)
+	}
+	markOutdated(comment, []rune(blog.Content))
+	return comment, nil
+}
+
+func (s *reviewService) Decide(userID, blogID int64, decision, note string) (*ReviewSummary, error) {
+	blog, authorRole, reviewer, err := s.access(blogID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if authorRole != "" || !reviewer {
+		return nil, ErrNotReviewer
+	}
+	if blog.Status != BlogStatusSubmitted {
+		return nil, fmt.Errorf("%w: only submitted posts can be reviewed", ErrInvalidInput)
+	}
+
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > maxReviewNoteRunes {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidInput, maxReviewNoteRunes)
+	}
+
+	var action string
+	switch strings.ToLower(strings.TrimSpace(decision)) {
+	case ReviewDecisionApprove:
+		action = reviewActionApproved
+	case ReviewDecisionRequestChanges:
+		if note == "" {
+			return nil, fmt.Errorf("%w: say what needs to change in the note", ErrInvalidInput)
+		}
+		action = reviewActionChangesRequested
+	default:
+		return nil, fmt.Errorf("%w: decision must be %q or %q", ErrInvalidInput, ReviewDecisionApprove, ReviewDecisionRequestChanges)
+	}
+
+	if err := s.repo.AddEvent(blogID, userID, action, note); err != nil {
+		return nil, err
+	}
+	// Requested changes hand the post back to its authors to revise and
+	// resubmit.
+	if action == reviewActionChangesRequested {
+		if err := s.blogRepo.SetBlogStatus(blogID, BlogStatusDraft); err != nil {
+			return nil, err
+		}
+	}
+	return s.Summary(userID, blogID)
+}
+
+func (s *reviewService) AwaitingReview(userID int64) ([]models.Blog, error) {
+	blogs, err := s.blogRepo.GetBlogsAwaitingReview(userID)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving posts awaiting review by user %d: %w", userID, err)
+	}
+	return blogs, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const reviewContent = "Héllo, wörld! This needs a review."
+
+// reviewTest is a draft post submitted by author to reviewer; stranger
+// has no part in it.
+type reviewTest struct {
+	s                          ReviewService
+	deps                       blogTestDeps
+	author, reviewer, stranger *models.User
+}
+
+func newReviewTest(t *testing.T) *reviewTest {
+	t.Helper()
+	users := &fakeUserRepo{}
+	author := users.add(models.User{Username: "author", Status: UserStatusActive})
+	_, deps := newTestBlogService(models.Blog{ID: 1, UserId: author.ID, Title: "Draft", Content: reviewContent, Status: BlogStatusDraft})
+	rt := &reviewTest{
+		s:        NewReviewService(deps.reviews, deps.blogs, users, deps.coAuthors, deps.publications),
+		deps:     deps,
+		author:   author,
+		reviewer: users.add(models.User{Username: "reviewer", Status: UserStatusActive}),
+		stranger: users.add(models.User{Username: "stranger", Status: UserStatusActive}),
+	}
+	if _, err := rt.s.Submit(author.ID, 1, []string{"@reviewer"}); err != nil {
+		t.Fatal(err)
+	}
+	return rt
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestReviewAccess(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		user                                 func(*reviewTest) int64
+		wantSummary, wantComment, wantDecide error
+	}{
+		{"author", func(rt *reviewTest) int64 { return rt.author.ID }, nil, nil, ErrNotReviewer},
+		{"reviewer", func(rt *reviewTest) int64 { return rt.reviewer.ID }, nil, nil, nil},
+		// The submitted post is unpublished, so it stays hidden
+		{"stranger", func(rt *reviewTest) int64 { return rt.stranger.ID }, ErrBlogNotFound, ErrBlogNotFound, ErrBlogNotFound},
+		{"anonymous", func(*reviewTest) int64 { return 0 }, ErrBlogNotFound, ErrBlogNotFound, ErrBlogNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newReviewTest(t)
+			userID := tt.user(rt)
+
+			if _, err := rt.s.Summary(userID, 1); !errors.Is(err, tt.wantSummary) {
+				t.Errorf("Summary err = %v, want %v", err, tt.wantSummary)
+			}
+			if _, err := rt.s.Comment(userID, 1, ReviewCommentInput{Body: "Looks good"}); !errors.Is(err, tt.wantComment) {
+				t.Errorf("Comment err = %v, want %v", err, tt.wantComment)
+			}
+			if _, err := rt.s.Decide(userID, 1, ReviewDecisionApprove, ""); !errors.Is(err, tt.wantDecide) {
+				t.Errorf("Decide err = %v, want %v", err, tt.wantDecide)
+			}
+		})
+	}
+}
+
+func TestReviewAccessOfPublishedPost(t *testing.T) {
+	rt := newReviewTest(t)
+	rt.deps.blogs.blogs[1].Status = BlogStatusPublished
+
+	// Readers of a published post may see it exists but not its review
+	if _, err := rt.s.Summary(rt.stranger.ID, 1); !errors.Is(err, ErrNotReviewer) {
+		t.Errorf("Summary err = %v, want ErrNotReviewer", err)
+	}
+	if _, err := rt.s.Comment(rt.stranger.ID, 1, ReviewCommentInput{Body: "Nice"}); !errors.Is(err, ErrNotReviewer) {
+		t.Errorf("Comment err = %v, want ErrNotReviewer", err)
+	}
+}
+
+func TestReviewReviewerManagement(t *testing.T) {
+	rt := newReviewTest(t)
+
+	if _, err := rt.s.AssignReviewer(rt.reviewer.ID, 1, "stranger"); !errors.Is(err, ErrReviewNotAllowed) {
+		t.Errorf("reviewer assigning: err = %v, want ErrReviewNotAllowed", err)
+	}
+	if _, err := rt.s.AssignReviewer(rt.author.ID, 1, "author"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("author reviewing their own post: err = %v, want ErrInvalidInput", err)
+	}
+	if err := rt.s.RemoveReviewer(rt.stranger.ID, 1, rt.reviewer.ID); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("stranger removing a reviewer: err = %v, want ErrBlogNotFound", err)
+	}
+
+	// Reviewers may step down, and lose access with it
+	if err := rt.s.RemoveReviewer(rt.reviewer.ID, 1, rt.reviewer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.s.Summary(rt.reviewer.ID, 1); !errors.Is(err, ErrBlogNotFound) {
+		t.Errorf("Summary by a removed reviewer: err = %v, want ErrBlogNotFound", err)
+	}
+	if err := rt.s.RemoveReviewer(rt.author.ID, 1, rt.reviewer.ID); !errors.Is(err, ErrReviewerNotFound) {
+		t.Errorf("removing twice: err = %v, want ErrReviewerNotFound", err)
+	}
+}
+
+func TestReviewResolveComment(t *testing.T) {
+	rt := newReviewTest(t)
+	if _, err := rt.s.AssignReviewer(rt.author.ID, 1, "stranger"); err != nil {
+		t.Fatal(err)
+	}
+	comment, err := rt.s.Comment(rt.reviewer.ID, 1, ReviewCommentInput{Body: "Typo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rt.s.ResolveComment(rt.stranger.ID, 1, comment.ID, true); !errors.Is(err, ErrReviewNotAllowed) {
+		t.Errorf("another reviewer resolving: err = %v, want ErrReviewNotAllowed", err)
+	}
+	resolved, err := rt.s.ResolveComment(rt.author.ID, 1, comment.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.ResolvedAt == nil {
+		t.Error("comment not resolved by the author")
+	}
+	reopened, err := rt.s.ResolveComment(rt.reviewer.ID, 1, comment.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.ResolvedAt != nil {
+		t.Error("comment not reopened by its writer")
+	}
+	if _, err := rt.s.ResolveComment(rt.author.ID, 1, 99, true); !errors.Is(err, ErrReviewCommentNotFound) {
+		t.Errorf("unknown comment: err = %v, want ErrReviewCommentNotFound", err)
+	}
+}
+
+func TestReviewCommentAnchors(t *testing.T) {
+	runes := len([]rune(reviewContent))
+	tests := []struct {
+		name       string
+		start, end *int
+		want       error
+		quoted     string
+	}{
+		{"unanchored", nil, nil, nil, ""},
+		// Anchors count runes, not bytes
+		{"first word", intPtr(0), intPtr(5), nil, "Héllo"},
+		{"up to the end", intPtr(runes - 7), intPtr(runes), nil, "review."},
+		{"start only", intPtr(0), nil, ErrInvalidInput, ""},
+		{"end only", nil, intPtr(5), ErrInvalidInput, ""},
+		{"negative start", intPtr(-1), intPtr(5), ErrInvalidInput, ""},
+		{"empty range", intPtr(5), intPtr(5), ErrInvalidInput, ""},
+		{"reversed", intPtr(5), intPtr(2), ErrInvalidInput, ""},
+		{"past the end", intPtr(0), intPtr(runes + 1), ErrInvalidInput, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newReviewTest(t)
+			comment, err := rt.s.Comment(rt.reviewer.ID, 1, ReviewCommentInput{Body: "Here", Start: tt.start, End: tt.end})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Comment err = %v, want %v", err, tt.want)
+			}
+			if tt.want != nil {
+				if len(rt.deps.reviews.comments) != 0 {
+					t.Error("comment with a bad anchor was stored")
+				}
+				return
+			}
+			if comment.QuotedText != tt.quoted {
+				t.Errorf("quoted text = %q, want %q", comment.QuotedText, tt.quoted)
+			}
+		})
+	}
+}
+
+func TestReviewCommentOutdated(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		outdated bool
+	}{
+		{"unchanged", reviewContent, false},
+		{"edited elsewhere", reviewContent + " More text.", false},
+		{"quoted text edited", "Hello, wörld! This needs a review.", true},
+		// A post cut shorter than the anchor must not break the summary
+		{"cut short", "Hé", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rt := newReviewTest(t)
+			if _, err := rt.s.Comment(rt.reviewer.ID, 1, ReviewCommentInput{Body: "Here", Start: intPtr(0), End: intPtr(5)}); err != nil {
+				t.Fatal(err)
+			}
+			rt.deps.blogs.blogs[1].Content = tt.content
+
+			summary, err := rt.s.Summary(rt.author.ID, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(summary.Comments) != 1 || summary.Comments[0].Outdated != tt.outdated {
+				t.Errorf("comments = %+v, want one with outdated %v", summary.Comments, tt.outdated)
+			}
+		})
+	}
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	maxSeriesTitleRunes       = 200
+	maxSeriesDescriptionRunes = 1000
+)
+
+var ErrSeriesNotFound = errors.New("series not found")
+
+// SeriesRepository defines the interface for series data operations
+type SeriesRepository interface {
+	CreateSeries(series *models.Series) error
+	// GetSeriesByID and GetSeriesByUser count only the posts readers may
+	// find when publicOnly is set, and all the author's posts otherwise.
+	GetSeriesByID(id int64, publicOnly bool) (*models.Series, error)
+	GetSeriesByUser(userID int64, publicOnly bool) ([]models.Series, error)
+	GetSeriesIDForBlog(blogID int64) (int64, error)
+	UpdateSeries(series *models.Series) error
+	DeleteSeries(seriesID, userID int64) error
+	AddPost(seriesID, blogID int64) error
+	RemovePost(seriesID, blogID int64) error
+	GetEntries(seriesID int64, publishedOnly bool) ([]models.SeriesEntry, error)
+	ReorderPosts(seriesID int64, blogIDs []int64) error
+}
+
+// SeriesDetail is a series with its table of contents.
+type SeriesDetail struct {
+	*models.Series
+	Contents []models.SeriesEntry `json:"contents"`
+}
+
+// SeriesUpdate holds the fields to change; nil fields are left as is.
+type SeriesUpdate struct {
+	Title       *string
+	Description *string
+}
+
+// SeriesService lets authors group their posts into ordered, multi-part
+// series.
+type SeriesService interface {
+	Create(userID int64, title, description string) (*models.Series, error)
+	Get(seriesID int64) (*SeriesDetail, error)
+	// ListByUser lists a user's series as readers see them; ListMine counts
+	// the author's unpublished posts too.
+	ListByUser(userID int64) ([]models.Series, error)
+	ListMine(userID int64) ([]models.Series, error)
+	Update(userID, seriesID int64, update SeriesUpdate) (*models.Series, error)
+	Delete(userID, seriesID int64) error
+	// AddPost appends one of the author's posts to the end of the series.
+	AddPost(userID, seriesID, blogID int64) error
+	RemovePost(userID, seriesID, blogID int64) error
+	// Reorder sets the order of a series; blogIDs must name every post once.
+	Reorder(userID, seriesID int64, blogIDs []int64) error
+}
+
+type seriesService struct {
+	repo     SeriesRepository
+	blogRepo BlogRepository
+}
+
+func NewSeriesService(r SeriesRepository, blogRepo BlogRepository) SeriesService {
+	return &seriesService{repo: r, blogRepo: blogRepo}
+}
+
+func validSeriesFields(title, description string) (string, string, error) {
+	title = strings.TrimSpace(title)
+	description = strings.TrimSpace(description)
+	if title == "" {
+		return "", "", fmt.Errorf("%w: series title cannot be empty", ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(title) > maxSeriesTitleRunes {
+		return "", "", fmt.Errorf("%w: series title cannot exceed %d characters", ErrInvalidInput, maxSeriesTitleRunes)
+	}
+	if utf8.RuneCountInString(description) > maxSeriesDescriptionRunes {
+		return "", "", fmt.Errorf("%w: series description cannot exceed %d characters", ErrInvalidInput, maxSeriesDescriptionRunes)
+	}
+	return title, description, nil
+}
+
+func (s *seriesService) get(seriesID int64, publicOnly bool) (*models.Series, error) {
+	series, err := s.repo.GetSeriesByID(seriesID, publicOnly)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSeriesNotFound
+		}
+		return nil, fmt.Errorf("error retrieving series %d: %w", seriesID, err)
+	}
+	return series, nil
+}
+
+// owned loads a series and checks it belongs to userID.
+func (s *seriesService) owned(userID, seriesID int64) (*models.Series, error) {
+	series, err := s.get(seriesID, false)
+	if err != nil {
+		return nil, err
+	}
+	if series.UserID != userID {
+		return nil, ErrSeriesNotFound
+	}
+	return series, nil
+}
+
+func (s *seriesService) Create(userID int64, title, description string) (*models.Series, error) {
+	title, description, err := validSeriesFields(title, description)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.Series{UserID: userID, Title: title, Description: description}
+	if err := s.repo.CreateSeries(series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (s *seriesService) Get(seriesID int64) (*SeriesDetail, error) {
+	series, err := s.get(seriesID, true)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetEntries(seriesID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving series contents: %w", err)
+	}
+	return &SeriesDetail{Series: series, Contents: entries}, nil
+}
+
+func (s *seriesService) ListByUser(userID int64) ([]models.Series, error) {
+	return s.listByUser(userID, true)
+}
+
+func (s *seriesService) ListMine(userID int64) ([]models.Series, error) {
+	return s.listByUser(userID, false)
+}
+
+func (s *seriesService) listByUser(userID int64, publicOnly bool) ([]models.Series, error) {
+	series, err := s.repo.GetSeriesByUser(userID, publicOnly)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving series for user %d: %w", userID, err)
+	}
+	return series, nil
+}
+
+func (s *seriesService) Update(userID, seriesID int64, update SeriesUpdate) (*models.Series, error) {
+	series, err := s.owned(userID, seriesID)
+	if err != nil {
+		return nil, err
+	}
+
+	title, description := series.Title, series.Description
+	if update.Title != nil {
+		title = *update.Title
+	}
+	if update.Description != nil {
+		description = *update.Description
+	}
+	if series.Title, series.Description, err = validSeriesFields(title, description); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateSeries(series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (s *seriesService) Delete(userID, seriesID int64) error {
+	if err := s.repo.DeleteSeries(seriesID, userID); err != nil {
+		if strings.Contains(err.Error(), "no series found") {
+			return ErrSeriesNotFound
+		}
+		return fmt.Errorf("deletion failed: %w", err)
+	}
+	return nil
+}
+
+func (s *seriesService) AddPost(userID, seriesID, blogID int64) error {
+	if _, err := s.owned(userID, seriesID); err != nil {
+		return err
+	}
+
+	blog, err := s.blogRepo.GetBlogByID(blogID)
+	if err != nil {
+		return ErrBlogNotFound
+	}
+	if blog.UserId != userID {
+		return fmt.Errorf("%w: you can only add your own posts to a series", ErrInvalidInput)
+	}
+
+	current, err := s.repo.GetSeriesIDForBlog(blogID)
+	switch {
+	case err == nil && current == seriesID:
+		return nil
+	case err == nil:
+		return fmt.Errorf("%w: this post is already part of another series", ErrInvalidInput)
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("error checking series for blog %d: %w", blogID, err)
+	}
+
+	return s.repo.AddPost(seriesID, blogID)
+}
+
+func (s *seriesService) RemovePost(userID, seriesID, blogID int64) error {
+	if _, err := s.owned(userID, seriesID); err != nil {
+		return err
+	}
+	if err := s.repo.RemovePost(seriesID, blogID); err != nil {
+		if strings.Contains(err.Error(), "no series post found") {
+			return ErrBlogNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *seriesService) Reorder(userID, seriesID int64, blogIDs []int64) error {
+	if _, err := s.owned(userID, seriesID); err != nil {
+		return err
+	}
+
+	entries, err := s.repo.GetEntries(seriesID, false)
+	if err != nil {
+		return fmt.Errorf("error retrieving series contents: %w", err)
+	}
+	if len(blogIDs) != len(entries) {
+		return fmt.Errorf("%w: blog_ids must list every post in the series exactly once", ErrInvalidInput)
+	}
+	remaining := make(map[int64]bool, len(entries))
+	for _, entry := range entries {
+		remaining[entry.BlogID] = true
+	}
+	for _, id := range blogIDs {
+		if !remaining[id] {
+			return fmt.Errorf("%w: blog_ids must list every post in the series exactly once", ErrInvalidInput)
+		}
+		delete(remaining, id)
+	}
+
+	return s.repo.ReorderPosts(seriesID, blogIDs)
+}
+
+// seriesInfo places a blog within its series, or returns nil when the blog
+// is not part of one.
+func seriesInfo(repo SeriesRepository, blogID int64) (*models.SeriesInfo, error) {
+	seriesID, err := repo.GetSeriesIDForBlog(blogID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error checking series for blog %d: %w", blogID, err)
+	}
+
+	series, err := repo.GetSeriesByID(seriesID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving series %d: %w", seriesID, err)
+	}
+	entries, err := repo.GetEntries(seriesID, true)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving series contents: %w", err)
+	}
+
+	info := &models.SeriesInfo{
+		ID:       series.ID,
+		Title:    series.Title,
+		Total:    len(entries),
+		Contents: entries,
+	}
+	for i, entry := range entries {
+		if entry.BlogID != blogID {
+			continue
+		}
+		info.Position = entry.Position
+		if i > 0 {
+			info.Previous = &entries[i-1]
+		}
+		if i < len(entries)-1 {
+			info.Next = &entries[i+1]
+		}
+	}
+	return info, nil
+}
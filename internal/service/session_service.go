@@ -0,0 +1,158 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// sessionTouchInterval limits how often last_seen_at is written.
+const sessionTouchInterval = time.Minute
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session has been revoked")
+)
+
+// SessionRepository defines the interface for session data operations
+type SessionRepository interface {
+	CreateSession(session *models.Session) error
+	GetActiveSession(sessionID, userID int64) (*models.Session, error)
+	GetActiveSessionsByUserID(userID int64, since time.Time) ([]models.Session, error)
+	TouchSession(sessionID int64) error
+	RevokeSession(sessionID, userID int64) error
+	RevokeUserSessions(userID, exceptID int64) error
+}
+
+// SessionService tracks where users are signed in.
+type SessionService interface {
+	Start(userID int64, userAgent, ip string) (*models.Session, error)
+	List(userID, currentSessionID int64) ([]models.Session, error)
+	Revoke(userID, sessionID int64) error
+	ValidateSession(claims *auth.Claims) error
+}
+
+type sessionService struct {
+	repo SessionRepository
+}
+
+func NewSessionService(r SessionRepository) SessionService {
+	return &sessionService{repo: r}
+}
+
+// Start records a new login.
+func (s *sessionService) Start(userID int64, userAgent, ip string) (*models.Session, error) {
+	session := &models.Session{
+		UserID: userID,
+		Device: deviceLabel(userAgent),
+		IP:     ip,
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// List returns the user's sessions whose tokens can still be valid.
+func (s *sessionService) List(userID, currentSessionID int64) ([]models.Session, error) {
+	sessions, err := s.repo.GetActiveSessionsByUserID(userID, time.Now().Add(-auth.TokenTTL))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving sessions for user %d: %w", userID, err)
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// Revoke signs a session out.
+func (s *sessionService) Revoke(userID, sessionID int64) error {
+	if err := s.repo.RevokeSession(sessionID, userID); err != nil {
+		if strings.Contains(err.Error(), "no session found") {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("revocation failed: %w", err)
+	}
+	return nil
+}
+
+// ValidateSession rejects JWTs whose session has been revoked, and records
+// activity on the session.
+func (s *sessionService) ValidateSession(claims *auth.Claims) error {
+	session, err := s.repo.GetActiveSession(claims.SessionID, claims.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrSessionRevoked
+		}
+		return fmt.Errorf("error checking session: %w", err)
+	}
+
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err := s.repo.TouchSession(session.ID); err != nil {
+			log.Printf("⚠️  Failed to update session %d: %v", session.ID, err)
+		}
+	}
+	return nil
+}
+
+// deviceLabel turns a User-Agent header into a short label such as
+// "Firefox on Windows".
+func deviceLabel(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	ua := strings.ToLower(userAgent)
+
+	var client string
+	switch {
+	case strings.Contains(ua, "edg/"):
+		client = "Edge"
+	case strings.Contains(ua, "firefox/"):
+		client = "Firefox"
+	case strings.Contains(ua, "chrome/"):
+		client = "Chrome"
+	case strings.Contains(ua, "safari/"):
+		client = "Safari"
+	case strings.HasPrefix(ua, "curl/"):
+		return "curl"
+	case strings.HasPrefix(ua, "postmanruntime/"):
+		return "Postman"
+	case strings.HasPrefix(ua, "node"):
+		return "Node.js"
+	}
+
+	var os string
+	switch {
+	case strings.Contains(ua, "windows"):
+		os = "Windows"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"):
+		os = "iOS"
+	case strings.Contains(ua, "mac os x"):
+		os = "macOS"
+	case strings.Contains(ua, "android"):
+		os = "Android"
+	case strings.Contains(ua, "linux"):
+		os = "Linux"
+	}
+
+	switch {
+	case client != "" && os != "":
+		return client + " on " + os
+	case client != "":
+		return client
+	case os != "":
+		return os
+	}
+
+	if len(userAgent) > 100 {
+		return userAgent[:100]
+	}
+	return userAgent
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+)
+
+func TestDeviceLabel(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"", "Unknown device"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:128.0) Gecko/20100101 Firefox/128.0", "Firefox on Windows"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36", "Chrome on macOS"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Mobile/15E148 Safari/604.1", "Safari on iOS"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0", "Firefox on Linux"},
+		{"curl/8.7.1", "curl"},
+		{"PostmanRuntime/7.39.0", "Postman"},
+		{"node-fetch/1.0", "Node.js"},
+		{"Mozilla/5.0 (X11; Linux x86_64)", "Linux"},
+		{"my-script/1.0", "my-script/1.0"},
+	}
+	for _, tt := range tests {
+		if got := deviceLabel(tt.userAgent); got != tt.want {
+			t.Errorf("deviceLabel(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
+
+func TestDeviceLabelTruncatesUnknownAgents(t *testing.T) {
+	got := deviceLabel(strings.Repeat("x", 300))
+	if len(got) != 100 {
+		t.Errorf("label is %d bytes, want 100", len(got))
+	}
+}
+
+func TestSessionRevocation(t *testing.T) {
+	const alice, bob = 1, 2
+	repo := &fakeSessionRepo{}
+	s := NewSessionService(repo)
+
+	laptop, err := s.Start(alice, "Mozilla/5.0 (X11; Linux x86_64; rv:128.0) Gecko/20100101 Firefox/128.0", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	phone, err := s.Start(alice, "curl/8.7.1", "192.0.2.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if laptop.Device != "Firefox on Linux" || laptop.IP != "192.0.2.1" {
+		t.Errorf("session = %+v", laptop)
+	}
+
+	sessions, err := s.List(alice, phone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("listed %d sessions, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID == phone.ID) {
+			t.Errorf("session %d current = %v", session.ID, session.Current)
+		}
+	}
+
+	laptopClaims := &auth.Claims{UserID: alice, SessionID: laptop.ID}
+	if err := s.ValidateSession(laptopClaims); err != nil {
+		t.Fatalf("ValidateSession: %v", err)
+	}
+	// A token naming someone else's session is no good either
+	if err := s.ValidateSession(&auth.Claims{UserID: bob, SessionID: laptop.ID}); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("another user's session: err = %v, want ErrSessionRevoked", err)
+	}
+
+	if err := s.Revoke(bob, laptop.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("revoking another user's session: err = %v, want ErrSessionNotFound", err)
+	}
+	if err := s.Revoke(alice, laptop.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ValidateSession(laptopClaims); !errors.Is(err, ErrSessionRevoked) {
+		t.Errorf("revoked session: err = %v, want ErrSessionRevoked", err)
+	}
+	if err := s.Revoke(alice, laptop.ID); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("revoking twice: err = %v, want ErrSessionNotFound", err)
+	}
+
+	sessions, err = s.List(alice, phone.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != phone.ID {
+		t.Errorf("sessions after revoking = %+v, want only the phone", sessions)
+	}
+}
+
+func TestSessionLastSeen(t *testing.T) {
+	repo := &fakeSessionRepo{}
+	s := NewSessionService(repo)
+	session, err := s.Start(1, "", "192.0.2.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := &auth.Claims{UserID: 1, SessionID: session.ID}
+
+	// Recent activity isn't written again on every request
+	stale := time.Now().Add(-sessionTouchInterval / 2)
+	repo.sessions[0].LastSeenAt = stale
+	if err := s.ValidateSession(claims); err != nil {
+		t.Fatal(err)
+	}
+	if !repo.sessions[0].LastSeenAt.Equal(stale) {
+		t.Error("last seen updated within the touch interval")
+	}
+
+	repo.sessions[0].LastSeenAt = time.Now().Add(-2 * sessionTouchInterval)
+	if err := s.ValidateSession(claims); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(repo.sessions[0].LastSeenAt) >= sessionTouchInterval {
+		t.Error("last seen not updated after the touch interval")
+	}
+}
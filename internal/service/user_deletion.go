@@ -0,0 +1,153 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+)
+
+const (
+	// DeletionModeDeletePosts erases the user's posts with the account.
+	DeletionModeDeletePosts = "delete_posts"
+	// DeletionModeAnonymize keeps the posts, attributed to "deleted".
+	DeletionModeAnonymize = "anonymize"
+
+	// accountDeletionGrace is how long a deletion request can be cancelled.
+	accountDeletionGrace = 14 * 24 * time.Hour
+)
+
+var (
+	ErrDeletionNotRequested = errors.New("account deletion has not been requested")
+	ErrDeletionPending      = errors.New("account deletion is already scheduled")
+)
+
+// DeletionStatus describes a scheduled account deletion.
+type DeletionStatus struct {
+	RequestedAt  time.Time `json:"requested_at"`
+	ScheduledFor time.Time `json:"scheduled_for"`
+	Mode         string    `json:"mode"`
+}
+
+// RequestDeletion schedules the account for deletion after the grace period.
+// Accounts with a password must confirm it. Every session but sessionID is
+// signed out and all personal access tokens are deleted, so only the
+// requester can still use the account, e.g. to cancel.
+func (s *userService) RequestDeletion(userID, sessionID int64, password, mode string) (*DeletionStatus, error) {
+	if mode == "" {
+		mode = DeletionModeDeletePosts
+	}
+	if mode != DeletionModeDeletePosts && mode != DeletionModeAnonymize {
+		return nil, fmt.Errorf("%w: mode must be %q or %q", ErrInvalidInput, DeletionModeDeletePosts, DeletionModeAnonymize)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+	if user.DeletionRequestedAt != nil {
+		return nil, ErrDeletionPending
+	}
+	if user.Password != "" && !auth.VerifyPassword(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.userRepo.ScheduleDeletion(userID, mode); err != nil {
+		return nil, err
+	}
+	if err := s.sessionRepo.RevokeUserSessions(userID, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to sign out other sessions: %w", err)
+	}
+
+	status := &DeletionStatus{
+		RequestedAt:  time.Now(),
+		ScheduledFor: time.Now().Add(accountDeletionGrace),
+		Mode:         mode,
+	}
+	log.Printf("⚠️  User %d scheduled account deletion for %s", userID, status.ScheduledFor.Format(time.RFC3339))
+
+	if user.Email != nil {
+		msg := mail.Message{
+			To:      *user.Email,
+			Subject: "Your account is scheduled for deletion",
+			Body: fmt.Sprintf(
+				"Hi %s,\n\nYour account will be permanently deleted on %s.\n\n"+
+					"Changed your mind? Sign in before then and cancel the deletion from your account settings.\n",
+				user.Username, status.ScheduledFor.Format("January 2, 2006"),
+			),
+		}
+		if err := s.mailer.Send(msg); err != nil {
+			log.Printf("⚠️  Failed to send deletion notice to user %d: %v", userID, err)
+		}
+	}
+
+	return status, nil
+}
+
+func (s *userService) GetDeletionStatus(userID int64) (*DeletionStatus, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionRequestedAt == nil {
+		return nil, ErrDeletionNotRequested
+	}
+
+	status := &DeletionStatus{
+		RequestedAt:  *user.DeletionRequestedAt,
+		ScheduledFor: user.DeletionRequestedAt.Add(accountDeletionGrace),
+	}
+	if user.DeletionMode != nil {
+		status.Mode = *user.DeletionMode
+	}
+	return status, nil
+}
+
+func (s *userService) CancelDeletion(userID int64) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.DeletionRequestedAt == nil {
+		return ErrDeletionNotRequested
+	}
+	return s.userRepo.CancelDeletion(userID)
+}
+
+// PurgeDeletedAccounts erases accounts whose grace period has ended.
+func (s *userService) PurgeDeletedAccounts() error {
+	ids, err := s.userRepo.GetUsersDueForDeletion(time.Now().Add(-accountDeletionGrace))
+	if err != nil {
+		return fmt.Errorf("error retrieving accounts due for deletion: %w", err)
+	}
+
+	for _, id := range ids {
+		user, err := s.userRepo.GetByID(id)
+		if err != nil {
+			log.Printf("⚠️  Failed to load user %d for deletion: %v", id, err)
+			continue
+		}
+		keepPosts := user.DeletionMode != nil && *user.DeletionMode == DeletionModeAnonymize
+
+		files, err := s.userRepo.DeleteUser(id, keepPosts)
+		if err != nil {
+			log.Printf("❌ Failed to delete user %d: %v", id, err)
+			continue
+		}
+		for _, f := range files {
+			if err := os.Remove(f); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("⚠️  Failed to remove export file %s: %v", f, err)
+			}
+		}
+		log.Printf("✅ Deleted account %d", id)
+	}
+	return nil
+}
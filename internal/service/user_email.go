@@ -0,0 +1,183 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	TokenPurposeEmailVerification = "email_verification"
+
+	emailVerificationTTL = 48 * time.Hour
+)
+
+// normalizeEmail returns the canonical form used for storage and uniqueness.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// checkEmailAvailable validates and normalises an address and makes sure no
+// other account uses it.
+func (s *userService) checkEmailAvailable(email string) (string, error) {
+	email = normalizeEmail(email)
+	addr, err := netmail.ParseAddress(email)
+	if err != nil || addr.Address != email || len(email) > 254 {
+		return "", fmt.Errorf("%w: invalid email address", ErrInvalidInput)
+	}
+
+	_, err = s.userRepo.GetUserByEmail(email)
+	if err == nil {
+		return "", ErrEmailTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("error checking email: %w", err)
+	}
+	return email, nil
+}
+
+// sendVerification mails a verification token for email, replacing any
+// token sent earlier.
+func (s *userService) sendVerification(user *models.User, email string) error {
+	if err := s.tokenRepo.DeleteUnusedUserTokens(user.ID, TokenPurposeEmailVerification); err != nil {
+		return err
+	}
+
+	raw, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return err
+	}
+
+	token := &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   TokenPurposeEmailVerification,
+		TokenHash: auth.HashToken(raw),
+		Email:     &email,
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}
+	if err := s.tokenRepo.CreateUserToken(token); err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+
+	msg := mail.Message{
+		To:      email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm this email address by opening the link below within the next 48 hours:\n\n"+
+				"%s/email/verify?token=%s\n\n"+
+				"If you did not create an account, you can ignore this email.\n",
+			user.Username, s.opts.BaseURL, raw,
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return fmt.Errorf("failed to send verification email: %w", err)
+	}
+	return nil
+}
+
+// GetMyProfile returns the profile of the signed-in user, including private fields.
+func (s *userService) GetMyProfile(id int64) (*UserProfile, error) {
+	profile, err := s.GetUserProfile(id)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	verified := user.EmailVerifiedAt != nil
+	profile.Email = user.Email
+	profile.EmailVerified = &verified
+	return profile, nil
+}
+
+// VerifyEmail redeems a verification token and marks the address it was sent
+// to as the user's verified email.
+func (s *userService) VerifyEmail(token string) error {
+	verification, err := s.tokenRepo.ConsumeUserToken(auth.HashToken(token), TokenPurposeEmailVerification)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("error redeeming verification token: %w", err)
+	}
+	if verification.Email == nil {
+		return ErrInvalidToken
+	}
+
+	// The address may have been claimed by another account in the meantime
+	existing, err := s.userRepo.GetUserByEmail(*verification.Email)
+	if err == nil && existing.ID != verification.UserID {
+		return ErrEmailTaken
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("error checking email: %w", err)
+	}
+
+	return s.userRepo.SetVerifiedEmail(verification.UserID, *verification.Email)
+}
+
+// ResendVerification sends a fresh verification email for the user's
+// current, unverified address.
+func (s *userService) ResendVerification(userID int64) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == nil {
+		return fmt.Errorf("%w: no email address on file", ErrInvalidInput)
+	}
+	if user.EmailVerifiedAt != nil {
+		return fmt.Errorf("%w: email address is already verified", ErrInvalidInput)
+	}
+	return s.sendVerification(user, *user.Email)
+}
+
+// ChangeEmail starts a change of address. The current address stays in place
+// until the new one has been verified.
+func (s *userService) ChangeEmail(userID int64, password, newEmail string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	if !auth.VerifyPassword(user.Password, password) {
+		return ErrInvalidCredentials
+	}
+
+	email, err := s.checkEmailAvailable(newEmail)
+	if err != nil {
+		return err
+	}
+
+	return s.sendVerification(user, email)
+}
+
+// CheckCanPost returns ErrEmailNotVerified when posting requires a verified
+// email address and the user has none.
+func (s *userService) CheckCanPost(userID int64) error {
+	if !s.opts.RequireVerifiedEmail {
+		return nil
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
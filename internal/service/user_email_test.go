@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestChangeEmail(t *testing.T) {
+	s, users, _, _, mailer, alice := newMailTestService(t)
+	bobEmail := "bob@example.com"
+	users.add(models.User{Username: "bob", Email: &bobEmail, Status: UserStatusActive})
+
+	tests := []struct {
+		name, password, email string
+		want                  error
+	}{
+		{"wrong password", "not the password", "alice@new.example", ErrInvalidCredentials},
+		{"not an address", testPassword, "alice at new.example", ErrInvalidInput},
+		{"display name", testPassword, "Alice <alice@new.example>", ErrInvalidInput},
+		// Addresses are compared normalised
+		{"taken", testPassword, " BOB@Example.com ", ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		if err := s.ChangeEmail(alice.ID, tt.password, tt.email); !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if n := len(mailer.Messages()); n != 0 {
+		t.Fatalf("refused changes sent %d messages", n)
+	}
+
+	if err := s.ChangeEmail(alice.ID, testPassword, " Alice@New.Example "); err != nil {
+		t.Fatal(err)
+	}
+	token := mailedToken(t, mailer, "alice@new.example")
+
+	// The current address stays until the new one is verified
+	stored, _ := users.GetByID(alice.ID)
+	if *stored.Email != "alice@example.com" {
+		t.Errorf("email changed to %q before verification", *stored.Email)
+	}
+
+	if err := s.VerifyEmail(token); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ = users.GetByID(alice.ID)
+	if *stored.Email != "alice@new.example" || stored.EmailVerifiedAt == nil {
+		t.Errorf("after verifying: email %q, verified at %v", *stored.Email, stored.EmailVerifiedAt)
+	}
+	if err := s.VerifyEmail(token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("reusing the token: err = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestVerifyEmailToken(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare runs after the change to alice@new.example was mailed
+		// and returns the token to redeem
+		prepare func(s UserService, users *fakeUserRepo, tokens *fakeUserTokenRepo, token string) string
+		want    error
+	}{
+		{"valid", func(_ UserService, _ *fakeUserRepo, _ *fakeUserTokenRepo, token string) string {
+			return token
+		}, nil},
+		{"unknown", func(UserService, *fakeUserRepo, *fakeUserTokenRepo, string) string {
+			return "not-a-token"
+		}, ErrInvalidToken},
+		{"expired", func(_ UserService, _ *fakeUserRepo, tokens *fakeUserTokenRepo, token string) string {
+			tokens.expireAll()
+			return token
+		}, ErrInvalidToken},
+		// Asking again replaces the earlier token
+		{"superseded", func(s UserService, users *fakeUserRepo, _ *fakeUserTokenRepo, token string) string {
+			if err := s.ChangeEmail(users.created[0].ID, testPassword, "alice@other.example"); err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}, ErrInvalidToken},
+		{"claimed since", func(_ UserService, users *fakeUserRepo, _ *fakeUserTokenRepo, token string) string {
+			bob := users.add(models.User{Username: "bob", Status: UserStatusActive})
+			if err := users.SetVerifiedEmail(bob.ID, "alice@new.example"); err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}, ErrEmailTaken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users, tokens, _, mailer, alice := newMailTestService(t)
+			if err := s.ChangeEmail(alice.ID, testPassword, "alice@new.example"); err != nil {
+				t.Fatal(err)
+			}
+			token := tt.prepare(s, users, tokens, mailedToken(t, mailer, "alice@new.example"))
+
+			if err := s.VerifyEmail(token); !errors.Is(err, tt.want) {
+				t.Fatalf("VerifyEmail err = %v, want %v", err, tt.want)
+			}
+			stored, _ := users.GetByID(alice.ID)
+			wantEmail := "alice@new.example"
+			if tt.want != nil {
+				wantEmail = "alice@example.com"
+			}
+			if *stored.Email != wantEmail {
+				t.Errorf("email = %q, want %q", *stored.Email, wantEmail)
+			}
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	s, users, _, _, mailer, alice := newMailTestService(t)
+
+	if err := s.ResendVerification(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.VerifyEmail(mailedToken(t, mailer, "alice@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ResendVerification(alice.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("already verified: err = %v, want ErrInvalidInput", err)
+	}
+
+	bob := users.add(models.User{Username: "bob", Status: UserStatusActive})
+	if err := s.ResendVerification(bob.ID); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("no address: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestCheckCanPost(t *testing.T) {
+	users, tokens := &fakeUserRepo{}, &fakeUserTokenRepo{}
+	email := "alice@example.com"
+	alice := users.add(models.User{Username: "alice", Email: &email, Status: UserStatusActive})
+
+	open := NewUserService(users, tokens, &fakeSessionRepo{}, nil, nil, UserOptions{})
+	if err := open.CheckCanPost(alice.ID); err != nil {
+		t.Errorf("without the requirement: %v", err)
+	}
+
+	gated := NewUserService(users, tokens, &fakeSessionRepo{}, nil, nil, UserOptions{RequireVerifiedEmail: true})
+	if err := gated.CheckCanPost(alice.ID); !errors.Is(err, ErrEmailNotVerified) {
+		t.Errorf("unverified: err = %v, want ErrEmailNotVerified", err)
+	}
+	if err := users.SetVerifiedEmail(alice.ID, email); err != nil {
+		t.Fatal(err)
+	}
+	if err := gated.CheckCanPost(alice.ID); err != nil {
+		t.Errorf("verified: %v", err)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Profile field limits, matching the column sizes in db/schema.sql.
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 500
+	maxAvatarURLLength   = 500
+	maxWebsiteLength     = 200
+	maxLocationLength    = 100
+	maxSocialLinks       = 8
+	maxSocialLinkLength  = 200
+)
+
+var socialNetworkPattern = regexp.MustCompile(`^[a-z0-9_]{1,20}$`)
+
+// ProfileUpdate is a partial profile update; nil fields are left unchanged.
+type ProfileUpdate struct {
+	DisplayName *string             `json:"display_name"`
+	Bio         *string             `json:"bio"`
+	AvatarURL   *string             `json:"avatar_url"`
+	Website     *string             `json:"website"`
+	Location    *string             `json:"location"`
+	SocialLinks *models.SocialLinks `json:"social_links"`
+}
+
+// UpdateProfile validates and applies a partial profile update.
+func (s *userService) UpdateProfile(userID int64, update ProfileUpdate) (*UserProfile, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		if user.DisplayName, err = cleanText("display_name", *update.DisplayName, maxDisplayNameLength, false); err != nil {
+			return nil, err
+		}
+	}
+	if update.Bio != nil {
+		if user.Bio, err = cleanText("bio", *update.Bio, maxBioLength, true); err != nil {
+			return nil, err
+		}
+	}
+	if update.Location != nil {
+		if user.Location, err = cleanText("location", *update.Location, maxLocationLength, false); err != nil {
+			return nil, err
+		}
+	}
+	// There is no media store to upload avatars to yet, so they are linked
+	// by URL like the website.
+	if update.AvatarURL != nil {
+		if user.AvatarURL, err = cleanURL("avatar_url", *update.AvatarURL, maxAvatarURLLength); err != nil {
+			return nil, err
+		}
+	}
+	if update.Website != nil {
+		if user.Website, err = cleanURL("website", *update.Website, maxWebsiteLength); err != nil {
+			return nil, err
+		}
+	}
+	if update.SocialLinks != nil {
+		if len(*update.SocialLinks) > maxSocialLinks {
+			return nil, fmt.Errorf("%w: at most %d social links are allowed", ErrInvalidInput, maxSocialLinks)
+		}
+		links := models.SocialLinks{}
+		for network, link := range *update.SocialLinks {
+			network = strings.ToLower(strings.TrimSpace(network))
+			if !socialNetworkPattern.MatchString(network) {
+				return nil, fmt.Errorf("%w: invalid social network name %q", ErrInvalidInput, network)
+			}
+			cleaned, err := cleanURL("social_links."+network, link, maxSocialLinkLength)
+			if err != nil {
+				return nil, err
+			}
+			if cleaned != "" {
+				links[network] = cleaned
+			}
+		}
+		user.SocialLinks = links
+	}
+
+	if err := s.userRepo.UpdateProfile(user); err != nil {
+		return nil, err
+	}
+
+	return s.GetMyProfile(userID)
+}
+
+// cleanText trims a free-text field and enforces its length limit. Control
+// characters are rejected, except newlines when multiline is set.
+func cleanText(field, value string, maxLen int, multiline bool) (string, error) {
+	value = strings.TrimSpace(value)
+	if utf8.RuneCountInString(value) > maxLen {
+		return "", fmt.Errorf("%w: %s cannot exceed %d characters", ErrInvalidInput, field, maxLen)
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) && !(multiline && (r == '\n' || r == '\r')) {
+			return "", fmt.Errorf("%w: %s contains invalid characters", ErrInvalidInput, field)
+		}
+	}
+	return value, nil
+}
+
+// cleanURL validates an optional absolute http(s) URL.
+func cleanURL(field, value string, maxLen int) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if len(value) > maxLen {
+		return "", fmt.Errorf("%w: %s cannot exceed %d characters", ErrInvalidInput, field, maxLen)
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%w: %s must be an http(s) URL", ErrInvalidInput, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func strPtr(s string) *string { return &s }
+
+func newProfileTest() (UserService, *fakeUserRepo, *models.User) {
+	users := &fakeUserRepo{}
+	s := NewUserService(users, &fakeUserTokenRepo{}, &fakeSessionRepo{}, nil, nil, UserOptions{})
+	alice := users.add(models.User{Username: "alice", Status: UserStatusActive})
+	return s, users, alice
+}
+
+func TestUpdateProfileValidation(t *testing.T) {
+	manyLinks := models.SocialLinks{}
+	for i := 0; i <= maxSocialLinks; i++ {
+		manyLinks[fmt.Sprintf("net%d", i)] = "https://example.com"
+	}
+	tests := []struct {
+		name   string
+		update ProfileUpdate
+	}{
+		{"display name too long", ProfileUpdate{DisplayName: strPtr(strings.Repeat("é", maxDisplayNameLength+1))}},
+		{"display name with a newline", ProfileUpdate{DisplayName: strPtr("Alice\nLiddell")}},
+		{"bio too long", ProfileUpdate{Bio: strPtr(strings.Repeat("a", maxBioLength+1))}},
+		{"bio with a control character", ProfileUpdate{Bio: strPtr("Hello\x00world")}},
+		{"location too long", ProfileUpdate{Location: strPtr(strings.Repeat("a", maxLocationLength+1))}},
+		{"avatar not http", ProfileUpdate{AvatarURL: strPtr("javascript:alert(1)")}},
+		{"avatar relative", ProfileUpdate{AvatarURL: strPtr("/images/me.png")}},
+		{"avatar too long", ProfileUpdate{AvatarURL: strPtr("https://example.com/" + strings.Repeat("a", maxAvatarURLLength))}},
+		{"website without host", ProfileUpdate{Website: strPtr("https://")}},
+		{"website ftp", ProfileUpdate{Website: strPtr("ftp://example.com")}},
+		{"too many links", ProfileUpdate{SocialLinks: &manyLinks}},
+		{"bad network name", ProfileUpdate{SocialLinks: &models.SocialLinks{"my network": "https://example.com"}}},
+		{"bad link", ProfileUpdate{SocialLinks: &models.SocialLinks{"github": "github.com/alice"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, users, alice := newProfileTest()
+			if _, err := s.UpdateProfile(alice.ID, ProfileUpdate{DisplayName: strPtr("Alice")}); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := s.UpdateProfile(alice.ID, tt.update); !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("err = %v, want ErrInvalidInput", err)
+			}
+			stored, _ := users.GetByID(alice.ID)
+			if stored.DisplayName != "Alice" || stored.Bio != "" || stored.AvatarURL != "" || stored.Website != "" || len(stored.SocialLinks) != 0 {
+				t.Errorf("refused update changed the profile: %+v", stored)
+			}
+		})
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	s, _, alice := newProfileTest()
+
+	profile, err := s.UpdateProfile(alice.ID, ProfileUpdate{
+		DisplayName: strPtr("  Alice Liddell  "),
+		Bio:         strPtr("Curious.\nFollows rabbits."),
+		AvatarURL:   strPtr("https://img.example/alice.png"),
+		Website:     strPtr(" http://alice.example "),
+		Location:    strPtr("Oxford"),
+		SocialLinks: &models.SocialLinks{" GitHub ": "https://github.com/alice", "mastodon": "  "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.DisplayName != "Alice Liddell" || profile.Bio != "Curious.\nFollows rabbits." ||
+		profile.AvatarURL != "https://img.example/alice.png" || profile.Website != "http://alice.example" || profile.Location != "Oxford" {
+		t.Errorf("profile = %+v", profile)
+	}
+	// Network names are normalised and empty links dropped
+	if len(profile.SocialLinks) != 1 || profile.SocialLinks["github"] != "https://github.com/alice" {
+		t.Errorf("social links = %v", profile.SocialLinks)
+	}
+
+	// Fields left out stay as they are; empty ones are cleared
+	profile, err = s.UpdateProfile(alice.ID, ProfileUpdate{Website: strPtr("")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Website != "" || profile.DisplayName != "Alice Liddell" || profile.Location != "Oxford" {
+		t.Errorf("after clearing the website: %+v", profile)
+	}
+
+	if _, err := s.UpdateProfile(99, ProfileUpdate{Bio: strPtr("hi")}); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown user: err = %v, want ErrUserNotFound", err)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+// Registration modes.
+const (
+	RegistrationOpen     = "open"
+	RegistrationInvite   = "invite"
+	RegistrationApproval = "approval"
+	RegistrationClosed   = "closed"
+)
+
+// Account statuses.
+const (
+	UserStatusActive  = "active"
+	UserStatusPending = "pending"
+)
+
+var (
+	ErrRegistrationClosed = errors.New("registration is closed")
+	ErrInviteRequired     = errors.New("an invite code is required to register")
+	ErrInvalidInvite      = errors.New("invalid or expired invite code")
+	ErrAccountPending     = errors.New("account is awaiting approval")
+)
+
+// PendingUser is an entry in the admin approval queue.
+type PendingUser struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Email     *string   `json:"email,omitempty"`
+	InvitedBy *int64    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// useInvite takes one use of the invite identified by code. It returns nil
+// when no code is given and invites are optional.
+func (s *userService) useInvite(code string) (*models.Invite, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		if s.opts.RegistrationMode == RegistrationInvite {
+			return nil, ErrInviteRequired
+		}
+		return nil, nil
+	}
+
+	invite, err := s.inviteRepo.UseInvite(auth.HashToken(code))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidInvite
+		}
+		return nil, fmt.Errorf("error checking invite: %w", err)
+	}
+	return invite, nil
+}
+
+func (s *userService) IsAdmin(userID int64) (bool, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.IsAdmin, nil
+}
+
+func (s *userService) ListPendingUsers() ([]PendingUser, error) {
+	users, err := s.userRepo.GetPendingUsers()
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving pending users: %w", err)
+	}
+
+	pending := make([]PendingUser, 0, len(users))
+	for _, u := range users {
+		pending = append(pending, PendingUser{
+			ID:        u.ID,
+			Username:  u.Username,
+			Email:     u.Email,
+			InvitedBy: u.InvitedBy,
+			CreatedAt: u.CreatedAt,
+		})
+	}
+	return pending, nil
+}
+
+// ApproveUser activates a pending account and lets the user know.
+func (s *userService) ApproveUser(userID int64) error {
+	if err := s.userRepo.ApproveUser(userID); err != nil {
+		if strings.Contains(err.Error(), "no pending user found") {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	log.Printf("✅ User %d approved", userID)
+
+	user, err := s.GetUserByID(userID)
+	if err != nil || user.Email == nil {
+		return nil
+	}
+	msg := mail.Message{
+		To:      *user.Email,
+		Subject: "Your account has been approved",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account has been approved. You can now sign in at %s.\n",
+			user.Username, s.opts.BaseURL,
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		log.Printf("⚠️  Failed to send approval email to user %d: %v", userID, err)
+	}
+	return nil
+}
+
+// RejectUser removes a pending account, freeing its username and email.
+func (s *userService) RejectUser(userID int64) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Status != UserStatusPending {
+		return ErrUserNotFound
+	}
+
+	if _, err := s.userRepo.DeleteUser(userID, false); err != nil {
+		return fmt.Errorf("failed to reject user: %w", err)
+	}
+	log.Printf("⚠️  User %d rejected", userID)
+	return nil
+}
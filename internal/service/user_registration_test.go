@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+const (
+	testInviteCode = "inv_testcode"
+	testPassword   = "a long enough test password"
+)
+
+func newTestInvite(maxUses int) *models.Invite {
+	return &models.Invite{ID: 7, CreatedBy: 3, CodeHash: auth.HashToken(testInviteCode), MaxUses: maxUses}
+}
+
+func newRegistrationService(mode string, users *fakeUserRepo, invites *fakeInviteRepo) UserService {
+	return NewUserService(users, nil, nil, invites, nil, UserOptions{RegistrationMode: mode})
+}
+
+func TestRegisterWithInvite(t *testing.T) {
+	invite := newTestInvite(2)
+	users := &fakeUserRepo{}
+	s := newRegistrationService(RegistrationInvite, users, newFakeInviteRepo(invite))
+
+	user, err := s.RegisterUser("alice", testPassword, "", testInviteCode)
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if invite.UseCount != 1 {
+		t.Errorf("use_count = %d, want 1", invite.UseCount)
+	}
+	if user.InviteID == nil || *user.InviteID != invite.ID {
+		t.Errorf("InviteID = %v, want %d", user.InviteID, invite.ID)
+	}
+	if user.InvitedBy == nil || *user.InvitedBy != invite.CreatedBy {
+		t.Errorf("InvitedBy = %v, want %d", user.InvitedBy, invite.CreatedBy)
+	}
+	if user.Status != UserStatusActive {
+		t.Errorf("Status = %q, want %q", user.Status, UserStatusActive)
+	}
+
+	if _, err := s.RegisterUser("bob", testPassword, "", " "+testInviteCode+" "); err != nil {
+		t.Fatalf("second RegisterUser: %v", err)
+	}
+	if _, err := s.RegisterUser("carol", testPassword, "", testInviteCode); !errors.Is(err, ErrInvalidInvite) {
+		t.Errorf("third use: err = %v, want ErrInvalidInvite", err)
+	}
+	if invite.UseCount != 2 {
+		t.Errorf("use_count = %d after the invite ran out, want 2", invite.UseCount)
+	}
+	if len(users.created) != 2 {
+		t.Errorf("created %d users, want 2", len(users.created))
+	}
+}
+
+func TestRegisterReleasesInviteWhenCreateFails(t *testing.T) {
+	invite := newTestInvite(1)
+	invites := newFakeInviteRepo(invite)
+	users := &fakeUserRepo{createErr: errors.New(`pq: duplicate key value violates unique constraint "idx_users_username_lower"`)}
+	s := newRegistrationService(RegistrationInvite, users, invites)
+
+	if _, err := s.RegisterUser("alice", testPassword, "", testInviteCode); !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("err = %v, want ErrUsernameTaken", err)
+	}
+	if invite.UseCount != 0 {
+		t.Errorf("use_count = %d after a failed registration, want 0", invite.UseCount)
+	}
+
+	// The use that was given back can be taken again
+	users.createErr = nil
+	if _, err := s.RegisterUser("alice2", testPassword, "", testInviteCode); err != nil {
+		t.Fatalf("RegisterUser after release: %v", err)
+	}
+	if invite.UseCount != 1 {
+		t.Errorf("use_count = %d, want 1", invite.UseCount)
+	}
+}
+
+func TestRegisterRejectsUnusableInvites(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name   string
+		invite *models.Invite
+		code   string
+	}{
+		{"unknown code", newTestInvite(1), "inv_other"},
+		{"revoked", &models.Invite{ID: 7, CodeHash: auth.HashToken(testInviteCode), MaxUses: 1, RevokedAt: &past}, testInviteCode},
+		{"expired", &models.Invite{ID: 7, CodeHash: auth.HashToken(testInviteCode), MaxUses: 1, ExpiresAt: &past}, testInviteCode},
+		{"used up", &models.Invite{ID: 7, CodeHash: auth.HashToken(testInviteCode), MaxUses: 1, UseCount: 1}, testInviteCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCount := tt.invite.UseCount
+			users := &fakeUserRepo{}
+			s := newRegistrationService(RegistrationOpen, users, newFakeInviteRepo(tt.invite))
+
+			if _, err := s.RegisterUser("alice", testPassword, "", tt.code); !errors.Is(err, ErrInvalidInvite) {
+				t.Errorf("err = %v, want ErrInvalidInvite", err)
+			}
+			if tt.invite.UseCount != useCount {
+				t.Errorf("use_count changed from %d to %d", useCount, tt.invite.UseCount)
+			}
+			if len(users.created) != 0 {
+				t.Error("a user was created")
+			}
+		})
+	}
+}
+
+func TestRegisterWithoutInvite(t *testing.T) {
+	tests := []struct {
+		mode       string
+		wantErr    error
+		wantStatus string
+	}{
+		{RegistrationOpen, nil, UserStatusActive},
+		{RegistrationApproval, nil, UserStatusPending},
+		{RegistrationInvite, ErrInviteRequired, ""},
+		{RegistrationClosed, ErrRegistrationClosed, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			invite := newTestInvite(1)
+			s := newRegistrationService(tt.mode, &fakeUserRepo{}, newFakeInviteRepo(invite))
+
+			user, err := s.RegisterUser("alice", testPassword, "", "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && user.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", user.Status, tt.wantStatus)
+			}
+			if invite.UseCount != 0 {
+				t.Errorf("use_count = %d, want 0", invite.UseCount)
+			}
+		})
+	}
+}
+
+func TestRegisterWithInviteSkipsApproval(t *testing.T) {
+	invite := newTestInvite(1)
+	s := newRegistrationService(RegistrationApproval, &fakeUserRepo{}, newFakeInviteRepo(invite))
+
+	user, err := s.RegisterUser("alice", testPassword, "", testInviteCode)
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if user.Status != UserStatusActive {
+		t.Errorf("Status = %q, want %q", user.Status, UserStatusActive)
+	}
+	if invite.UseCount != 1 {
+		t.Errorf("use_count = %d, want 1", invite.UseCount)
+	}
+}
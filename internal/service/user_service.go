@@ -4,15 +4,28 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
+	"time"
 
 	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
 	"github.com/Brownie44l1/blog/internal/models"
 )
 
+const (
+	TokenPurposePasswordReset = "password_reset"
+
+	passwordResetTTL = time.Hour
+)
+
 var (
 	ErrUserNotFound       = errors.New("user not found")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUsernameTaken      = errors.New("username already taken")
+	ErrInvalidInput       = errors.New("invalid input")
+	ErrEmailTaken         = errors.New("email already in use")
+	ErrEmailNotVerified   = errors.New("email address not verified")
 )
 
 // UserRepository defines the interface for user data operations
@@ -21,43 +34,147 @@ type UserRepository interface {
 	GetByID(id int64) (*models.User, error)
 	GetUserByUsername(username string) (*models.User, error)
 	GetBlogCountByUserID(userID int64) (int, error)
+	GetUserByEmail(email string) (*models.User, error)
+	UpdatePassword(userID int64, hashedPassword string) error
+	SetVerifiedEmail(userID int64, email string) error
+	UpdateProfile(user *models.User) error
+	GetUserIDByPreviousUsername(username string) (int64, error)
+	ChangeUsername(userID int64, oldUsername, newUsername string, redirectUntil time.Time) error
+	ScheduleDeletion(userID int64, mode string) error
+	CancelDeletion(userID int64) error
+	GetUsersDueForDeletion(cutoff time.Time) ([]int64, error)
+	DeleteUser(userID int64, keepPosts bool) ([]string, error)
+	GetPendingUsers() ([]models.User, error)
+	ApproveUser(userID int64) error
+	GetAuthorsByIDs(ids []int64) ([]models.Author, error)
+}
+
+// UserTokenRepository defines the interface for single-use user token data operations
+type UserTokenRepository interface {
+	CreateUserToken(token *models.UserToken) error
+	GetUserToken(tokenHash, purpose string) (*models.UserToken, error)
+	ConsumeUserToken(tokenHash, purpose string) (*models.UserToken, error)
+	DeleteUnusedUserTokens(userID int64, purpose string) error
 }
 
 type UserService interface {
-	RegisterUser(username, password string) (*models.User, error)
+	RegisterUser(username, password, email, inviteCode string) (*models.User, error)
 	Authenticate(username, password string) (*models.User, error)
 	GetUserByID(id int64) (*models.User, error)
 	GetUserProfile(id int64) (*UserProfile, error)
+	ChangePassword(userID, sessionID int64, currentPassword, newPassword string) error
+	RequestPasswordReset(login string) error
+	ResetPassword(token, newPassword string) error
+	GetMyProfile(id int64) (*UserProfile, error)
+	VerifyEmail(token string) error
+	ResendVerification(userID int64) error
+	ChangeEmail(userID int64, password, newEmail string) error
+	CheckCanPost(userID int64) error
+	UpdateProfile(userID int64, update ProfileUpdate) (*UserProfile, error)
+	GetUserProfileByUsername(username string) (*UserProfile, error)
+	ChangeUsername(userID int64, newUsername string) (*UserProfile, error)
+	RequestDeletion(userID, sessionID int64, password, mode string) (*DeletionStatus, error)
+	GetDeletionStatus(userID int64) (*DeletionStatus, error)
+	CancelDeletion(userID int64) error
+	PurgeDeletedAccounts() error
+	IsAdmin(userID int64) (bool, error)
+	ListPendingUsers() ([]PendingUser, error)
+	ApproveUser(userID int64) error
+	RejectUser(userID int64) error
+}
+
+// UserOptions holds the configurable behaviour of UserService.
+type UserOptions struct {
+	// BaseURL is used to build links in outgoing mail
+	BaseURL string
+	// RequireEmail makes an email address mandatory at registration
+	RequireEmail bool
+	// RequireVerifiedEmail blocks posting until the email address is verified
+	RequireVerifiedEmail bool
+	// RegistrationMode is one of the Registration* constants
+	RegistrationMode string
+	// PasswordPolicy applies to every new password
+	PasswordPolicy auth.PasswordPolicy
 }
 
 type userService struct {
-	userRepo UserRepository
+	userRepo    UserRepository
+	tokenRepo   UserTokenRepository
+	sessionRepo SessionRepository
+	inviteRepo  InviteRepository
+	mailer      mail.Mailer
+	opts        UserOptions
 }
 
 type UserProfile struct {
-	ID        int64  `json:"id"`
-	Username  string `json:"username"`
-	BlogCount int    `json:"blog_count"`
+	ID            int64              `json:"id"`
+	Username      string             `json:"username"`
+	DisplayName   string             `json:"display_name"`
+	Bio           string             `json:"bio"`
+	AvatarURL     string             `json:"avatar_url"`
+	Website       string             `json:"website"`
+	Location      string             `json:"location"`
+	SocialLinks   models.SocialLinks `json:"social_links"`
+	BlogCount     int                `json:"blog_count"`
+	Email         *string            `json:"email,omitempty"`
+	EmailVerified *bool              `json:"email_verified,omitempty"`
 }
 
-func NewUserService(userRepo UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo UserRepository, tokenRepo UserTokenRepository, sessionRepo SessionRepository, inviteRepo InviteRepository, mailer mail.Mailer, opts UserOptions) UserService {
+	return &userService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessionRepo: sessionRepo,
+		inviteRepo:  inviteRepo,
+		mailer:      mailer,
+		opts:        opts,
+	}
 }
 
-func (s *userService) RegisterUser(username, password string) (*models.User, error) {
+// RegisterUser creates an account according to the registration mode. In
+// approval mode the account is created pending unless a valid invite code
+// is given.
+func (s *userService) RegisterUser(username, password, email, inviteCode string) (*models.User, error) {
+	if s.opts.RegistrationMode == RegistrationClosed {
+		return nil, ErrRegistrationClosed
+	}
+
 	// Validate input
 	if username == "" || password == "" {
 		return nil, fmt.Errorf("username and password cannot be empty")
 	}
 
-	// Check if username exists
-	_, err := s.userRepo.GetUserByUsername(username)
-	if err == nil {
-		return nil, ErrUsernameTaken
+	var emailAddr *string
+	if email != "" {
+		normalized, err := s.checkEmailAvailable(email)
+		if err != nil {
+			return nil, err
+		}
+		emailAddr = &normalized
+	} else if s.opts.RequireEmail {
+		return nil, fmt.Errorf("%w: email address is required", ErrInvalidInput)
 	}
-	// Only proceed if error is "not found"
-	if !errors.Is(err, sql.ErrNoRows) && err.Error() != fmt.Sprintf("user not found for username '%s'", username) {
-		return nil, fmt.Errorf("error checking username: %w", err)
+
+	if err := validateUsername(username); err != nil {
+		return nil, err
+	}
+
+	var personal []string
+	if emailAddr != nil {
+		local, _, _ := strings.Cut(*emailAddr, "@")
+		personal = append(personal, local)
+	}
+	if err := s.checkPassword("password", password, username, personal...); err != nil {
+		return nil, err
+	}
+
+	// Check if username exists or is still held by a recent rename
+	available, err := usernameAvailable(s.userRepo, username, 0)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, ErrUsernameTaken
 	}
 
 	// Hash password
@@ -69,11 +186,42 @@ func (s *userService) RegisterUser(username, password string) (*models.User, err
 	user := &models.User{
 		Username: username,
 		Password: hashedPassword,
+		Email:    emailAddr,
+		Status:   UserStatusActive,
+	}
+
+	invite, err := s.useInvite(inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if invite != nil {
+		user.InvitedBy = &invite.CreatedBy
+		user.InviteID = &invite.ID
+	} else if s.opts.RegistrationMode == RegistrationApproval {
+		user.Status = UserStatusPending
 	}
 
 	if err := s.userRepo.CreateUser(user); err != nil {
+		if invite != nil {
+			if err := s.inviteRepo.ReleaseInvite(invite.ID); err != nil {
+				log.Printf("⚠️  Failed to release invite %d: %v", invite.ID, err)
+			}
+		}
+		// Lost a race for the name with a concurrent sign-up
+		if strings.Contains(err.Error(), "idx_users_username_lower") {
+			return nil, ErrUsernameTaken
+		}
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	if user.Status == UserStatusPending {
+		log.Printf("📝 User %d registered and is awaiting approval", user.ID)
+	}
+
+	if emailAddr != nil {
+		if err := s.sendVerification(user, *emailAddr); err != nil {
+			log.Printf("⚠️  Failed to send verification email to user %d: %v", user.ID, err)
+		}
+	}
 
 	// Clear password before returning
 	user.Password = ""
@@ -91,6 +239,19 @@ func (s *userService) Authenticate(username, password string) (*models.User, err
 		return nil, ErrInvalidCredentials
 	}
 
+	if user.Status == UserStatusPending {
+		return nil, ErrAccountPending
+	}
+
+	// Transparently upgrade legacy bcrypt or weaker argon2id hashes
+	if auth.NeedsRehash(user.Password) {
+		if hashed, err := auth.HashPassword(password); err != nil {
+			log.Printf("⚠️  Failed to rehash password for user %d: %v", user.ID, err)
+		} else if err := s.userRepo.UpdatePassword(user.ID, hashed); err != nil {
+			log.Printf("⚠️  Failed to store rehashed password for user %d: %v", user.ID, err)
+		}
+	}
+
 	// Clear password before returning
 	user.Password = ""
 	return user, nil
@@ -122,8 +283,175 @@ func (s *userService) GetUserProfile(id int64) (*UserProfile, error) {
 	}
 
 	return &UserProfile{
-		ID:        user.ID,
-		Username:  user.Username,
-		BlogCount: blogCount,
+		ID:          user.ID,
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+		AvatarURL:   user.AvatarURL,
+		Website:     user.Website,
+		Location:    user.Location,
+		SocialLinks: user.SocialLinks,
+		BlogCount:   blogCount,
 	}, nil
-}
\ No newline at end of file
+}
+
+// ChangePassword replaces the password of a signed-in user after checking the
+// current one, and signs out every session except sessionID. Accounts created
+// through an identity provider have no password yet and set one without.
+func (s *userService) ChangePassword(userID, sessionID int64, currentPassword, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("%w: new password cannot be empty", ErrInvalidInput)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	if user.Password != "" && !auth.VerifyPassword(user.Password, currentPassword) {
+		return ErrInvalidCredentials
+	}
+
+	if err := s.checkPassword("new_password", newPassword, user.Username, emailLocalPart(user)...); err != nil {
+		return err
+	}
+
+	hashedPassword, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(userID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(userID, sessionID); err != nil {
+		return fmt.Errorf("failed to sign out other sessions: %w", err)
+	}
+	return nil
+}
+
+// RequestPasswordReset mails a single-use reset token to the account
+// identified by login (a username or email address). Unknown accounts and
+// accounts without an email address are ignored so callers cannot probe
+// which accounts exist.
+func (s *userService) RequestPasswordReset(login string) error {
+	login = strings.TrimSpace(login)
+	if login == "" {
+		return fmt.Errorf("%w: username or email is required", ErrInvalidInput)
+	}
+
+	var user *models.User
+	var err error
+	if strings.Contains(login, "@") {
+		user, err = s.userRepo.GetUserByEmail(login)
+	} else {
+		user, err = s.userRepo.GetUserByUsername(login)
+	}
+	if err != nil || user.Email == nil {
+		log.Printf("Password reset requested for unknown or unreachable account %q", login)
+		return nil
+	}
+
+	if err := s.tokenRepo.DeleteUnusedUserTokens(user.ID, TokenPurposePasswordReset); err != nil {
+		return err
+	}
+
+	raw, err := auth.GenerateOpaqueToken("")
+	if err != nil {
+		return err
+	}
+
+	token := &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   TokenPurposePasswordReset,
+		TokenHash: auth.HashToken(raw),
+		ExpiresAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := s.tokenRepo.CreateUserToken(token); err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	msg := mail.Message{
+		To:      *user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to reset the password for your account. "+
+				"To choose a new password, open the link below within the next hour:\n\n"+
+				"%s/password/reset?token=%s\n\n"+
+				"If you did not ask for this, you can ignore this email.\n",
+			user.Username, s.opts.BaseURL, raw,
+		),
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return fmt.Errorf("failed to send reset email: %w", err)
+	}
+	return nil
+}
+
+// ResetPassword redeems a reset token and sets a new password, signing the
+// user out everywhere.
+func (s *userService) ResetPassword(token, newPassword string) error {
+	if newPassword == "" {
+		return fmt.Errorf("%w: new password cannot be empty", ErrInvalidInput)
+	}
+
+	// Check the new password before redeeming the token, so a rejected
+	// password does not use it up
+	pending, err := s.tokenRepo.GetUserToken(auth.HashToken(token), TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("error looking up reset token: %w", err)
+	}
+	user, err := s.GetUserByID(pending.UserID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkPassword("new_password", newPassword, user.Username, emailLocalPart(user)...); err != nil {
+		return err
+	}
+
+	resetToken, err := s.tokenRepo.ConsumeUserToken(auth.HashToken(token), TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("error redeeming reset token: %w", err)
+	}
+
+	hashedPassword, err := auth.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(resetToken.UserID, hashedPassword); err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if err := s.sessionRepo.RevokeUserSessions(resetToken.UserID, 0); err != nil {
+		return fmt.Errorf("failed to sign out sessions: %w", err)
+	}
+	return nil
+}
+
+// checkPassword applies the password policy, reporting problems against field.
+func (s *userService) checkPassword(field, password, username string, personal ...string) error {
+	if reason := s.opts.PasswordPolicy.Check(password, append(personal, username)...); reason != "" {
+		return fieldError(field, reason)
+	}
+	return nil
+}
+
+// emailLocalPart returns the part of the user's email before the "@", if any.
+func emailLocalPart(user *models.User) []string {
+	if user.Email == nil {
+		return nil
+	}
+	local, _, _ := strings.Cut(*user.Email, "@")
+	return []string{local}
+}
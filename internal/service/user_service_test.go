@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/Brownie44l1/blog/internal/auth"
+	"github.com/Brownie44l1/blog/internal/mail"
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestChangePasswordWithoutPassword(t *testing.T) {
+	users, sessions := &fakeUserRepo{}, &fakeSessionRepo{}
+	s := NewUserService(users, nil, sessions, nil, nil, UserOptions{})
+	// Accounts created through an identity provider have no password
+	user := users.add(models.User{Username: "alice", Status: UserStatusActive})
+
+	if err := s.ChangePassword(user.ID, 3, "", testPassword); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	stored, _ := users.GetByID(user.ID)
+	if !auth.VerifyPassword(stored.Password, testPassword) {
+		t.Error("the new password was not stored")
+	}
+
+	// Once set, the password is required to change it
+	if err := s.ChangePassword(user.ID, 3, "", "another long test password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("err = %v, want ErrInvalidCredentials", err)
+	}
+}
+
+// newMailTestService returns a user service that mails through a
+// MemoryMailer, with alice signed up at alice@example.com.
+func newMailTestService(t *testing.T) (UserService, *fakeUserRepo, *fakeUserTokenRepo, *fakeSessionRepo, *mail.MemoryMailer, *models.User) {
+	t.Helper()
+	users, tokens, sessions := &fakeUserRepo{}, &fakeUserTokenRepo{}, &fakeSessionRepo{}
+	mailer := mail.NewMemoryMailer()
+	s := NewUserService(users, tokens, sessions, nil, mailer, UserOptions{
+		BaseURL:        "https://blog.example",
+		PasswordPolicy: auth.PasswordPolicy{MinLength: 12},
+	})
+
+	hash, err := auth.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	email := "alice@example.com"
+	alice := users.add(models.User{Username: "alice", Password: hash, Email: &email, Status: UserStatusActive})
+	return s, users, tokens, sessions, mailer, alice
+}
+
+var mailedTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// mailedToken returns the token in the link of the only message sent to to.
+func mailedToken(t *testing.T, mailer *mail.MemoryMailer, to string) string {
+	t.Helper()
+	msgs := mailer.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(msgs))
+	}
+	if msgs[0].To != to {
+		t.Fatalf("message sent to %q, want %q", msgs[0].To, to)
+	}
+	m := mailedTokenPattern.FindStringSubmatch(msgs[0].Body)
+	if m == nil {
+		t.Fatalf("no token link in message:\n%s", msgs[0].Body)
+	}
+	return m[1]
+}
+
+func TestChangePassword(t *testing.T) {
+	s, users, _, sessions, _, alice := newMailTestService(t)
+	const newPassword = "a brand new test password"
+
+	if err := s.ChangePassword(alice.ID, 3, "not the password", newPassword); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong current password: err = %v, want ErrInvalidCredentials", err)
+	}
+	if _, revoked := sessions.revokedAllBut[alice.ID]; revoked {
+		t.Fatal("a failed change signed sessions out")
+	}
+
+	if err := s.ChangePassword(alice.ID, 3, testPassword, newPassword); err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	stored, _ := users.GetByID(alice.ID)
+	if !auth.VerifyPassword(stored.Password, newPassword) {
+		t.Error("the new password was not stored")
+	}
+	if kept, revoked := sessions.revokedAllBut[alice.ID]; !revoked || kept != 3 {
+		t.Errorf("revoked sessions = %v, want all but session 3", sessions.revokedAllBut)
+	}
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	s, users, tokens, _, mailer, alice := newMailTestService(t)
+	users.add(models.User{Username: "bob", Status: UserStatusActive})
+
+	// Unknown accounts and accounts without email look the same as real ones
+	for _, login := range []string{"nobody", "nobody@example.com", "bob"} {
+		if err := s.RequestPasswordReset(login); err != nil {
+			t.Errorf("RequestPasswordReset(%q) = %v, want nil", login, err)
+		}
+	}
+	if msgs := mailer.Messages(); len(msgs) != 0 {
+		t.Fatalf("sent %d messages for accounts that can't be reached", len(msgs))
+	}
+
+	for _, login := range []string{"alice", "Alice@Example.com"} {
+		t.Run(login, func(t *testing.T) {
+			mailer.Reset()
+			if err := s.RequestPasswordReset(login); err != nil {
+				t.Fatalf("RequestPasswordReset: %v", err)
+			}
+			raw := mailedToken(t, mailer, *alice.Email)
+			if _, err := tokens.GetUserToken(auth.HashToken(raw), TokenPurposePasswordReset); err != nil {
+				t.Errorf("mailed token is not redeemable: %v", err)
+			}
+		})
+	}
+
+	// Asking again replaces the earlier token
+	if len(tokens.tokens) != 1 {
+		t.Errorf("%d outstanding tokens, want 1", len(tokens.tokens))
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	const newPassword = "a brand new test password"
+
+	requestReset := func(t *testing.T) (UserService, *fakeUserRepo, *fakeUserTokenRepo, *fakeSessionRepo, *models.User, string) {
+		s, users, tokens, sessions, mailer, alice := newMailTestService(t)
+		if err := s.RequestPasswordReset("alice"); err != nil {
+			t.Fatalf("RequestPasswordReset: %v", err)
+		}
+		return s, users, tokens, sessions, alice, mailedToken(t, mailer, *alice.Email)
+	}
+
+	t.Run("reset", func(t *testing.T) {
+		s, users, _, sessions, alice, raw := requestReset(t)
+		if err := s.ResetPassword(raw, newPassword); err != nil {
+			t.Fatalf("ResetPassword: %v", err)
+		}
+		stored, _ := users.GetByID(alice.ID)
+		if !auth.VerifyPassword(stored.Password, newPassword) {
+			t.Error("the new password was not stored")
+		}
+		if kept, revoked := sessions.revokedAllBut[alice.ID]; !revoked || kept != 0 {
+			t.Errorf("revoked sessions = %v, want every session", sessions.revokedAllBut)
+		}
+
+		// Tokens are single use
+		if err := s.ResetPassword(raw, "yet another test password"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("reused token: err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		s, _, tokens, _, _, raw := requestReset(t)
+		tokens.expireAll()
+		if err := s.ResetPassword(raw, newPassword); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		s, _, _, _, _, _ := requestReset(t)
+		if err := s.ResetPassword("not-a-token", newPassword); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("err = %v, want ErrInvalidToken", err)
+		}
+	})
+
+	t.Run("policy failure keeps the token", func(t *testing.T) {
+		s, users, _, _, alice, raw := requestReset(t)
+		for _, weak := range []string{"short", "alice's new password"} {
+			if err := s.ResetPassword(raw, weak); !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("ResetPassword(%q) = %v, want ErrInvalidInput", weak, err)
+			}
+		}
+		if err := s.ResetPassword(raw, newPassword); err != nil {
+			t.Fatalf("token was used up by a rejected password: %v", err)
+		}
+		stored, _ := users.GetByID(alice.ID)
+		if !auth.VerifyPassword(stored.Password, newPassword) {
+			t.Error("the new password was not stored")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// usernameChangeCooldown is how long a user must wait between renames.
+	usernameChangeCooldown = 30 * 24 * time.Hour
+	// usernameRedirectGrace is how long an old username keeps redirecting to
+	// the new one. It stays reserved for its previous owner during that time.
+	usernameRedirectGrace = 30 * 24 * time.Hour
+)
+
+var ErrUsernameChangeTooSoon = errors.New("username was changed too recently")
+
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,20}$`)
+
+// reservedUsernames cannot be registered because they collide with routes,
+// look official, or are used internally.
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "auth": true,
+	"blog": true, "blogs": true, "deleted": true, "help": true,
+	"login": true, "logout": true, "me": true, "moderator": true,
+	"null": true, "register": true, "root": true, "settings": true,
+	"staff": true, "support": true, "system": true, "undefined": true,
+	"user": true, "users": true, "www": true,
+}
+
+// isReservedUsername reports whether username is on the deny-list.
+func isReservedUsername(username string) bool {
+	return reservedUsernames[strings.ToLower(username)]
+}
+
+// validateUsername checks the format and deny-list for a new username.
+func validateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("%w: username must be 3-20 characters of letters, digits or underscores", ErrInvalidInput)
+	}
+	if isReservedUsername(username) {
+		return fmt.Errorf("%w: username %q is reserved", ErrInvalidInput, username)
+	}
+	return nil
+}
+
+// usernameAvailable reports whether username is neither in use nor held by a
+// recent rename. A user may always take back their own previous name.
+func usernameAvailable(userRepo UserRepository, username string, userID int64) (bool, error) {
+	existing, err := userRepo.GetUserByUsername(username)
+	if err == nil {
+		return existing.ID == userID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) && !strings.Contains(err.Error(), "user not found") {
+		return false, fmt.Errorf("error checking username: %w", err)
+	}
+
+	previousOwner, err := userRepo.GetUserIDByPreviousUsername(username)
+	if err == nil {
+		return previousOwner == userID, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("error checking username history: %w", err)
+	}
+	return true, nil
+}
+
+// GetUserProfileByUsername looks a user up by username. Old usernames still
+// in their grace period resolve to the renamed user; callers can compare the
+// returned username to detect that and redirect.
+func (s *userService) GetUserProfileByUsername(username string) (*UserProfile, error) {
+	user, err := s.userRepo.GetUserByUsername(username)
+	if err == nil {
+		return s.GetUserProfile(user.ID)
+	}
+	if !errors.Is(err, sql.ErrNoRows) && !strings.Contains(err.Error(), "user not found") {
+		return nil, fmt.Errorf("error retrieving user: %w", err)
+	}
+
+	userID, err := s.userRepo.GetUserIDByPreviousUsername(username)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("error checking username history: %w", err)
+	}
+	return s.GetUserProfile(userID)
+}
+
+// ChangeUsername renames a user. Renames are limited by a cooldown, except
+// for changing only the letter case of the current name.
+func (s *userService) ChangeUsername(userID int64, newUsername string) (*UserProfile, error) {
+	newUsername = strings.TrimSpace(strings.TrimPrefix(newUsername, "@"))
+	if err := validateUsername(newUsername); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == newUsername {
+		return nil, fmt.Errorf("%w: that is already your username", ErrInvalidInput)
+	}
+
+	caseOnly := strings.EqualFold(user.Username, newUsername)
+	if !caseOnly {
+		if user.UsernameChangedAt != nil && time.Since(*user.UsernameChangedAt) < usernameChangeCooldown {
+			return nil, fmt.Errorf("%w: next change allowed after %s", ErrUsernameChangeTooSoon,
+				user.UsernameChangedAt.Add(usernameChangeCooldown).Format(time.RFC3339))
+		}
+
+		available, err := usernameAvailable(s.userRepo, newUsername, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			return nil, ErrUsernameTaken
+		}
+	}
+
+	if err := s.userRepo.ChangeUsername(userID, user.Username, newUsername, time.Now().Add(usernameRedirectGrace)); err != nil {
+		if strings.Contains(err.Error(), "duplicate key") {
+			return nil, ErrUsernameTaken
+		}
+		return nil, err
+	}
+
+	return s.GetMyProfile(userID)
+}
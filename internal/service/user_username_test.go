@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Brownie44l1/blog/internal/models"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		username string
+		valid    bool
+	}{
+		{"alice", true},
+		{"Alice_99", true},
+		{"al", false},
+		{"a_name_that_is_too_long", false},
+		{"al ice", false},
+		{"alice!", false},
+		{"ålice", false},
+		// The deny-list ignores case
+		{"admin", false},
+		{"Admin", false},
+		{"ME", false},
+	}
+	for _, tt := range tests {
+		err := validateUsername(tt.username)
+		if tt.valid && err != nil {
+			t.Errorf("validateUsername(%q) = %v, want nil", tt.username, err)
+		}
+		if !tt.valid && !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("validateUsername(%q) = %v, want ErrInvalidInput", tt.username, err)
+		}
+	}
+}
+
+// newUsernameTest returns a user service with alice and bob signed up.
+func newUsernameTest() (UserService, *fakeUserRepo, *models.User, *models.User) {
+	users := &fakeUserRepo{}
+	s := NewUserService(users, &fakeUserTokenRepo{}, &fakeSessionRepo{}, nil, nil, UserOptions{})
+	alice := users.add(models.User{Username: "alice", Status: UserStatusActive})
+	bob := users.add(models.User{Username: "bob", Status: UserStatusActive})
+	return s, users, alice, bob
+}
+
+// endCooldown backdates a user's last rename past the cooldown.
+func endCooldown(users *fakeUserRepo, userID int64) {
+	for _, u := range users.created {
+		if u.ID == userID && u.UsernameChangedAt != nil {
+			past := u.UsernameChangedAt.Add(-usernameChangeCooldown - time.Minute)
+			u.UsernameChangedAt = &past
+		}
+	}
+}
+
+func TestChangeUsernameCooldown(t *testing.T) {
+	s, users, alice, _ := newUsernameTest()
+
+	profile, err := s.ChangeUsername(alice.ID, "@alicia")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Username != "alicia" {
+		t.Errorf("username = %q, want alicia", profile.Username)
+	}
+
+	if _, err := s.ChangeUsername(alice.ID, "alison"); !errors.Is(err, ErrUsernameChangeTooSoon) {
+		t.Errorf("second rename: err = %v, want ErrUsernameChangeTooSoon", err)
+	}
+	// Fixing the letter case doesn't wait for the cooldown
+	if _, err := s.ChangeUsername(alice.ID, "Alicia"); err != nil {
+		t.Errorf("case-only rename: %v", err)
+	}
+	if _, err := s.ChangeUsername(alice.ID, "Alicia"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("renaming to the same name: err = %v, want ErrInvalidInput", err)
+	}
+
+	endCooldown(users, alice.ID)
+	if _, err := s.ChangeUsername(alice.ID, "alison"); err != nil {
+		t.Errorf("rename after the cooldown: %v", err)
+	}
+}
+
+func TestChangeUsernameAvailability(t *testing.T) {
+	s, _, alice, _ := newUsernameTest()
+
+	for _, name := range []string{"bob", "BOB"} {
+		if _, err := s.ChangeUsername(alice.ID, name); !errors.Is(err, ErrUsernameTaken) {
+			t.Errorf("renaming to %q: err = %v, want ErrUsernameTaken", name, err)
+		}
+	}
+	if _, err := s.ChangeUsername(alice.ID, "admin"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("renaming to a reserved name: err = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestOldUsernameRedirects(t *testing.T) {
+	s, users, alice, bob := newUsernameTest()
+	if _, err := s.ChangeUsername(alice.ID, "alicia"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The old name resolves to the renamed user, so callers can redirect
+	profile, err := s.GetUserProfileByUsername("Alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.ID != alice.ID || profile.Username != "alicia" {
+		t.Errorf("old name resolved to %+v, want alicia", profile)
+	}
+
+	// and stays reserved for its previous owner during the grace period
+	if _, err := s.ChangeUsername(bob.ID, "alice"); !errors.Is(err, ErrUsernameTaken) {
+		t.Errorf("taking a redirecting name: err = %v, want ErrUsernameTaken", err)
+	}
+	endCooldown(users, alice.ID)
+	if _, err := s.ChangeUsername(alice.ID, "alice"); err != nil {
+		t.Fatalf("taking back the old name: %v", err)
+	}
+	if profile, err := s.GetUserProfileByUsername("alicia"); err != nil || profile.Username != "alice" {
+		t.Errorf("alicia now resolves to %+v, %v; want alice", profile, err)
+	}
+
+	// Once the redirect expires the name is free again
+	for i := range users.history {
+		users.history[i].expiresAt = time.Now().Add(-time.Second)
+	}
+	if _, err := s.GetUserProfileByUsername("alicia"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("expired redirect: err = %v, want ErrUserNotFound", err)
+	}
+	profile, err = s.ChangeUsername(bob.ID, "alicia")
+	if err != nil {
+		t.Fatalf("taking an expired name: %v", err)
+	}
+	if profile.Username != "alicia" {
+		t.Errorf("username = %q, want alicia", profile.Username)
+	}
+	if _, err := s.GetUserProfileByUsername("nobody"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("unknown name: err = %v, want ErrUserNotFound", err)
+	}
+}
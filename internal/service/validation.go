@@ -0,0 +1,34 @@
+package service
+
+import (
+	"sort"
+	"strings"
+)
+
+// ValidationError reports invalid input per request field. It matches
+// ErrInvalidInput with errors.Is, so existing handling still applies.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func fieldError(field, message string) *ValidationError {
+	return &ValidationError{Fields: map[string]string{field: message}}
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field := range e.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		parts = append(parts, field+" "+e.Fields[field])
+	}
+	return ErrInvalidInput.Error() + ": " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidInput
+}